@@ -0,0 +1,347 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                     zr-web/[auth.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	Login state is kept in the session, so Context must be created
+//	with a *Sessions. A typical login handler and protected page:
+//
+//	func loginServe(w http.ResponseWriter, req *http.Request) {
+//		ctx := web.NewContext(w, req, &sessions)
+//		user, ok := checkPassword(req.FormValue("user"),
+//			req.FormValue("password"))
+//		if !ok {
+//			ctx.Reply(loginPage("Wrong user name or password"), "html")
+//			return
+//		}
+//		ctx.Login(user.ID)
+//		ctx.SetRoles(user.Roles...)
+//		ctx.SafeRedirect(req.FormValue("next"), "/")
+//	}
+//
+//	func adminServe(w http.ResponseWriter, req *http.Request) {
+//		ctx := web.NewContext(w, req, &sessions)
+//		if !ctx.RequireLogin("/login") || !ctx.RequireRole("admin") {
+//			return
+//		}
+//		...
+//	}
+
+//  BasicAuth struct
+//
+// # Methods (ctx *Context)
+//   HasRole(role string) bool
+//   Login(userID string) error
+//   Logout() error
+//   RequireLogin(loginURL string) bool
+//   RequireRole(roles ...string) bool
+//   Roles() []string
+//   SetRoles(roles ...string)
+//   UserID() string
+//
+// # Methods (ob *BasicAuth)
+//   ) Handler(next http.Handler) http.Handler
+//   ) HandlerFunc(next http.HandlerFunc) http.HandlerFunc
+//
+// # Support (File Scope)
+//   (ob *BasicAuth) check(user, password string) bool
+//   (ob *BasicAuth) isVerified(key [sha256.Size]byte) bool
+//   (ob *BasicAuth) setVerified(key [sha256.Size]byte)
+//   basicAuthCacheKey(user, password, expect string) [sha256.Size]byte
+//   basicAuthDummyHash() string
+//   constantTimeEqual(a, b string) bool
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/balacode/zr"
+)
+
+// Names of the session settings that hold the login state.
+const (
+	sessionUserSetting  = "user"
+	sessionRolesSetting = "roles"
+)
+
+// BasicAuth is a middleware that requires HTTP Basic authentication.
+// It is meant for simple cases such as admin pages or internal
+// tools; use session logins for pages used by the public.
+// Always serve pages protected with BasicAuth over HTTPS,
+// since the password is sent with every request.
+type BasicAuth struct {
+	// Realm is shown by browsers in the login prompt.
+	Realm string
+
+	// Users maps user names to passwords, or to password
+	// hashes made by HashPassword(), which are recommended.
+	// Since browsers send the password with every request,
+	// successful checks of hashed passwords are cached.
+	Users map[string]string
+
+	// Check, if set, is called to verify the user name and password
+	// instead of looking them up in Users.
+	Check func(user, password string) bool
+
+	verified map[[sha256.Size]byte]bool
+	mutex    sync.Mutex
+} //                                                                   BasicAuth
+
+// basicAuthCacheSize limits the number of successful
+// checks cached by each BasicAuth
+const basicAuthCacheSize = 1000
+
+// basicAuthCacheSecret keys the hashes of cached
+// credentials, so they can't be brute-forced offline
+var basicAuthCacheSecret = func() []byte {
+	ret := make([]byte, 32)
+	if _, err := rand.Read(ret); err != nil {
+		zr.Error("Can't generate BasicAuth cache secret:", err)
+	}
+	return ret
+}()
+
+// basicAuthDummy holds the hash checked for unknown users
+var basicAuthDummy struct {
+	hash  string
+	mutex sync.Mutex
+}
+
+// -----------------------------------------------------------------------------
+// # Methods (ctx *Context)
+
+// HasRole returns true if the logged-in user has the given role.
+func (ctx *Context) HasRole(role string) bool {
+	if ctx.UserID() == "" {
+		return false
+	}
+	for _, s := range ctx.Roles() {
+		if s == role {
+			return true
+		}
+	}
+	return false
+} //                                                                     HasRole
+
+// Login marks the session as logged in by the user identified
+// by 'userID'. It regenerates the session, i.e. moves its settings
+// to a session with a new ID, to prevent session fixation.
+// Any roles set before are cleared: use SetRoles() after Login().
+func (ctx *Context) Login(userID string) error {
+	if userID == "" {
+		return zr.Error(zr.EInvalidArg, "^userID", "is blank")
+	}
+	if ctx.sessions == nil || ctx.Session == nil {
+		return zr.Error("Context has no sessions")
+	}
+	ctx.Session = ctx.sessions.regenerate(ctx.w, ctx.req, ctx.Session, true)
+	ctx.Session.SetSetting(sessionUserSetting, userID)
+	ctx.Session.SetSetting(sessionRolesSetting, "")
+	return nil
+} //                                                                       Login
+
+// Logout ends the current session and starts a new, empty
+// session, so that nothing from the old session is kept.
+func (ctx *Context) Logout() error {
+	if ctx.sessions == nil || ctx.Session == nil {
+		return zr.Error("Context has no sessions")
+	}
+	ctx.Session = ctx.sessions.regenerate(ctx.w, ctx.req, ctx.Session, false)
+	return nil
+} //                                                                      Logout
+
+// RequireLogin returns true if a user is logged in. If not, it
+// redirects the client to 'loginURL' with a 'next' query parameter
+// holding the current URL and returns false, in which case the
+// handler should return without replying. After logging in, the
+// login handler can send the client back with SafeRedirect(next).
+func (ctx *Context) RequireLogin(loginURL string) bool {
+	if ctx.UserID() != "" {
+		return true
+	}
+	sep := "?"
+	if strings.Contains(loginURL, "?") {
+		sep = "&"
+	}
+	next := ctx.req.URL.RequestURI()
+	http.Redirect(ctx.w, ctx.req,
+		loginURL+sep+"next="+url.QueryEscape(next), http.StatusSeeOther)
+	return false
+} //                                                                RequireLogin
+
+// RequireRole returns true if the logged-in user has any of the given
+// roles. If not, it replies with HTTP status 403 (Forbidden) and
+// returns false, in which case the handler should return without
+// replying. Call RequireLogin() first to redirect anonymous
+// users to the login page rather than forbidding them.
+func (ctx *Context) RequireRole(roles ...string) bool {
+	for _, role := range roles {
+		if ctx.HasRole(role) {
+			return true
+		}
+	}
+	http.Error(ctx.w, http.StatusText(http.StatusForbidden),
+		http.StatusForbidden)
+	return false
+} //                                                                 RequireRole
+
+// Roles returns the roles of the logged-in user, as set by SetRoles().
+func (ctx *Context) Roles() []string {
+	if ctx.Session == nil {
+		return nil
+	}
+	return strings.Fields(ctx.Session.GetSetting(sessionRolesSetting))
+} //                                                                       Roles
+
+// SetRoles sets the roles of the logged-in user, e.g. "admin", "editor".
+// Roles can't contain spaces. They are kept in the session.
+func (ctx *Context) SetRoles(roles ...string) {
+	if ctx.Session == nil {
+		return
+	}
+	ctx.Session.SetSetting(sessionRolesSetting, strings.Join(roles, " "))
+} //                                                                    SetRoles
+
+// UserID returns the ID of the logged-in user, as
+// passed to Login(), or a blank string if nobody
+// is logged in.
+func (ctx *Context) UserID() string {
+	if ctx.Session == nil {
+		return ""
+	}
+	return ctx.Session.GetSetting(sessionUserSetting)
+} //                                                                      UserID
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *BasicAuth)
+
+// Handler wraps 'next' so that it is only called for requests with valid
+// Basic authentication credentials. Other requests get HTTP status
+// 401 (Unauthorized) with a 'WWW-Authenticate' challenge.
+func (ob *BasicAuth) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, password, ok := req.BasicAuth()
+		if !ok || !ob.check(user, password) {
+			realm := ob.Realm
+			if realm == "" {
+				realm = "Restricted"
+			}
+			w.Header().Set("WWW-Authenticate",
+				"Basic realm="+strconv.Quote(realm)+`, charset="UTF-8"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized),
+				http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+} //                                                                     Handler
+
+// HandlerFunc is like Handler(), but wraps a handler function such
+// as one passed to http.HandleFunc(), for protecting single routes.
+func (ob *BasicAuth) HandlerFunc(next http.HandlerFunc) http.HandlerFunc {
+	return ob.Handler(next).ServeHTTP
+} //                                                                 HandlerFunc
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// check verifies a user name and password. The comparison takes
+// the same time whether or not the user exists, so that valid
+// user names can't be found by timing the replies: unknown users
+// are checked against a dummy hash, or a dummy password if none
+// of the Users has a hash. Successful checks of hashed passwords
+// are cached, so that each request doesn't repeat the hashing.
+func (ob *BasicAuth) check(user, password string) bool {
+	if ob.Check != nil {
+		return ob.Check(user, password)
+	}
+	expect, exists := ob.Users[user]
+	if !exists {
+		expect = "\x00dummy"
+		for _, s := range ob.Users {
+			if isPasswordHash(s) {
+				expect = basicAuthDummyHash()
+				break
+			}
+		}
+	}
+	if !isPasswordHash(expect) {
+		return constantTimeEqual(password, expect) && exists
+	}
+	key := basicAuthCacheKey(user, password, expect)
+	if exists && ob.isVerified(key) {
+		return true
+	}
+	if !VerifyPassword(password, expect) || !exists {
+		return false
+	}
+	ob.setVerified(key)
+	return true
+} //                                                                       check
+
+// isVerified returns true if the credentials
+// identified by 'key' were verified before.
+func (ob *BasicAuth) isVerified(key [sha256.Size]byte) bool {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+	return ob.verified[key]
+} //                                                                  isVerified
+
+// setVerified caches the verified credentials identified by 'key'.
+// When the cache is full, it is emptied.
+func (ob *BasicAuth) setVerified(key [sha256.Size]byte) {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+	if ob.verified == nil || len(ob.verified) >= basicAuthCacheSize {
+		ob.verified = make(map[[sha256.Size]byte]bool)
+	}
+	ob.verified[key] = true
+} //                                                                 setVerified
+
+// basicAuthCacheKey returns the key of credentials in the cache of
+// verified credentials. It includes the stored hash 'expect', so
+// changing a user's password makes the cached entry unusable.
+func basicAuthCacheKey(user, password, expect string) [sha256.Size]byte {
+	mac := hmac.New(sha256.New, basicAuthCacheSecret)
+	for _, s := range []string{user, password, expect} {
+		mac.Write([]byte(strconv.Itoa(len(s)) + ":" + s))
+	}
+	var ret [sha256.Size]byte
+	copy(ret[:], mac.Sum(nil))
+	return ret
+} //                                                           basicAuthCacheKey
+
+// basicAuthDummyHash returns the password hash checked for unknown
+// users. It is made once, and again if PasswordIterations() changes,
+// so it takes as long to check as the hashes of real users.
+func basicAuthDummyHash() string {
+	dummy := &basicAuthDummy
+	dummy.mutex.Lock()
+	defer dummy.mutex.Unlock()
+	if dummy.hash == "" || NeedsRehash(dummy.hash) {
+		hash, err := HashPassword("")
+		if err == nil {
+			dummy.hash = hash
+		}
+	}
+	return dummy.hash
+} //                                                          basicAuthDummyHash
+
+// constantTimeEqual compares two strings in time that
+// depends neither on their contents nor their lengths.
+func constantTimeEqual(a, b string) bool {
+	hashA := sha256.Sum256([]byte(a))
+	hashB := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(hashA[:], hashB[:]) == 1
+} //                                                           constantTimeEqual
+
+// end
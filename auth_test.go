@@ -0,0 +1,228 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                zr-web/[auth_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Methods (ctx *Context)
+//   Test_auth_Context_Login_
+//   Test_auth_Context_Logout_
+//   Test_auth_Context_RequireLogin_
+//   Test_auth_Context_RequireRole_
+//
+// # Methods (ob *BasicAuth)
+//   Test_auth_BasicAuth_Handler_
+//   Test_auth_BasicAuth_check_
+
+//  to test all items in auth.go use:
+//      go test --run Test_auth_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// newTestSessionContext creates a Context for a request that
+// carries the session cookie 'sessionID', if not blank.
+func newTestSessionContext(sessions *Sessions, method, target, sessionID string,
+) (Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, nil)
+	if sessionID != "" {
+		req.AddCookie(&http.Cookie{Name: "app_session_id", Value: sessionID})
+	}
+	rec := httptest.NewRecorder()
+	return NewContext(rec, req, sessions), rec
+} //                                                       newTestSessionContext
+
+// -----------------------------------------------------------------------------
+// # Methods (ctx *Context)
+
+// go test --run Test_auth_Context_Login_
+func Test_auth_Context_Login_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) Login(userID string) error
+	//
+	var sessions Sessions
+	ctx, _ := newTestSessionContext(&sessions, "POST", "/login", "planted")
+	ctx.Session.SetSetting("cart", "3 items")
+	ctx.SetRoles("guest")
+	zr.TEqual(t, ctx.UserID(), "")
+	//
+	zr.TTrue(t, ctx.Login("") != nil)
+	zr.TEqual(t, ctx.Login("u1"), nil)
+	newID := ctx.Session.ID()
+	zr.TTrue(t, newID != "planted")
+	zr.TEqual(t, ctx.UserID(), "u1")
+	zr.TEqual(t, ctx.Session.GetSetting("cart"), "3 items")
+	zr.TEqual(t, len(ctx.Roles()), 0)
+	//
+	// the new ID carries the login, the planted ID doesn't
+	ctx2, _ := newTestSessionContext(&sessions, "GET", "/", newID)
+	zr.TEqual(t, ctx2.UserID(), "u1")
+	ctx3, _ := newTestSessionContext(&sessions, "GET", "/", "planted")
+	zr.TEqual(t, ctx3.UserID(), "")
+	//
+	// Context without sessions
+	ctx4 := NewContext(httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/", nil), nil)
+	zr.TTrue(t, ctx4.Login("u1") != nil)
+} //                                                    Test_auth_Context_Login_
+
+// go test --run Test_auth_Context_Logout_
+func Test_auth_Context_Logout_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) Logout() error
+	//
+	var sessions Sessions
+	ctx, _ := newTestSessionContext(&sessions, "POST", "/login", "")
+	ctx.Login("u1")
+	oldID := ctx.Session.ID()
+	//
+	ctx2, rec := newTestSessionContext(&sessions, "POST", "/logout", oldID)
+	zr.TEqual(t, ctx2.Logout(), nil)
+	zr.TEqual(t, ctx2.UserID(), "")
+	zr.TTrue(t, ctx2.Session.ID() != oldID)
+	cookies := rec.Result().Cookies()
+	zr.TEqual(t, len(cookies), 1)
+	zr.TEqual(t, cookies[0].Value, ctx2.Session.ID())
+	zr.TEqual(t, cookies[0].Path, "/")
+	zr.TTrue(t, cookies[0].HttpOnly)
+	//
+	ctx3, _ := newTestSessionContext(&sessions, "GET", "/", oldID)
+	zr.TEqual(t, ctx3.UserID(), "")
+} //                                                   Test_auth_Context_Logout_
+
+// go test --run Test_auth_Context_RequireLogin_
+func Test_auth_Context_RequireLogin_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) RequireLogin(loginURL string) bool
+	//
+	var sessions Sessions
+	{
+		ctx, rec := newTestSessionContext(&sessions, "GET", "/orders?p=2", "")
+		zr.TFalse(t, ctx.RequireLogin("/login"))
+		zr.TEqual(t, rec.Code, http.StatusSeeOther)
+		zr.TEqual(t, rec.Header().Get("Location"),
+			"/login?next=%2Forders%3Fp%3D2")
+	}
+	{
+		ctx, rec := newTestSessionContext(&sessions, "GET", "/orders", "")
+		zr.TFalse(t, ctx.RequireLogin("/login?lang=en"))
+		zr.TEqual(t, rec.Header().Get("Location"),
+			"/login?lang=en&next=%2Forders")
+	}
+	{
+		ctx, rec := newTestSessionContext(&sessions, "GET", "/orders", "")
+		ctx.Login("u1")
+		zr.TTrue(t, ctx.RequireLogin("/login"))
+		zr.TEqual(t, rec.Header().Get("Location"), "")
+	}
+} //                                             Test_auth_Context_RequireLogin_
+
+// go test --run Test_auth_Context_RequireRole_
+func Test_auth_Context_RequireRole_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) RequireRole(roles ...string) bool
+	//
+	var sessions Sessions
+	{
+		ctx, rec := newTestSessionContext(&sessions, "GET", "/admin", "")
+		ctx.SetRoles("admin") // not logged in: roles don't count
+		zr.TFalse(t, ctx.RequireRole("admin"))
+		zr.TEqual(t, rec.Code, http.StatusForbidden)
+	}
+	{
+		ctx, rec := newTestSessionContext(&sessions, "GET", "/admin", "")
+		ctx.Login("u1")
+		ctx.SetRoles("editor", "viewer")
+		zr.TTrue(t, ctx.HasRole("viewer"))
+		zr.TTrue(t, ctx.RequireRole("admin", "editor"))
+		zr.TFalse(t, ctx.RequireRole("admin"))
+		zr.TEqual(t, rec.Code, http.StatusForbidden)
+	}
+} //                                              Test_auth_Context_RequireRole_
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *BasicAuth)
+
+// go test --run Test_auth_BasicAuth_Handler_
+func Test_auth_BasicAuth_Handler_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *BasicAuth) Handler(next http.Handler) http.Handler
+	//
+	auth := &BasicAuth{
+		Realm: "Admin",
+		Users: map[string]string{"ann": "secret"},
+	}
+	handler := auth.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	test := func(user, password string, expect int) {
+		req := httptest.NewRequest("GET", "/", nil)
+		if user != "" {
+			req.SetBasicAuth(user, password)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		zr.TEqual(t, rec.Code, expect)
+		if expect == http.StatusUnauthorized {
+			zr.TEqual(t, rec.Header().Get("WWW-Authenticate"),
+				`Basic realm="Admin", charset="UTF-8"`)
+		}
+	}
+	test("ann", "secret", http.StatusOK)
+	test("ann", "wrong", http.StatusUnauthorized)
+	test("bob", "secret", http.StatusUnauthorized)
+	test("bob", "", http.StatusUnauthorized)
+	test("", "", http.StatusUnauthorized)
+	//
+	// custom check function
+	auth.Check = func(user, password string) bool { return user == "bob" }
+	test("bob", "x", http.StatusOK)
+	test("ann", "secret", http.StatusUnauthorized)
+} //                                                Test_auth_BasicAuth_Handler_
+
+// go test --run Test_auth_BasicAuth_check_
+func Test_auth_BasicAuth_check_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *BasicAuth) check(user, password string) bool
+	//
+	defer SetPasswordIterations(DefaultPasswordIterations)
+	SetPasswordIterations(1000)
+	hash, _ := HashPassword("secret")
+	auth := &BasicAuth{Users: map[string]string{
+		"ann": hash,
+		"bob": "plain",
+	}}
+	zr.TEqual(t, auth.check("ann", "secret"), true)
+	zr.TEqual(t, auth.check("ann", "wrong"), false)
+	zr.TEqual(t, auth.check("bob", "plain"), true)
+	zr.TEqual(t, auth.check("bob", "secret"), false)
+	//
+	// successful checks of hashes are cached, failed ones are not
+	zr.TEqual(t, len(auth.verified), 1)
+	zr.TEqual(t, auth.check("ann", "secret"), true)
+	zr.TEqual(t, len(auth.verified), 1)
+	//
+	// unknown users are checked against the dummy hash, never
+	// against another user's password, whether it is hashed or not
+	zr.TEqual(t, auth.check("eve", "secret"), false)
+	zr.TEqual(t, auth.check("eve", "plain"), false)
+	zr.TTrue(t, isPasswordHash(basicAuthDummy.hash))
+	zr.TEqual(t, auth.check("eve", ""), false)
+	//
+	// changing a password makes the cached check unusable
+	auth.Users["ann"], _ = HashPassword("new")
+	zr.TEqual(t, auth.check("ann", "secret"), false)
+	zr.TEqual(t, auth.check("ann", "new"), true)
+} //                                                  Test_auth_BasicAuth_check_
+
+// end
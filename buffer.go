@@ -18,9 +18,11 @@ package web
 //   ) Write(html ...*Buffer)
 //   ) WriteBytes(arrays ...[]byte)
 //   ) WriteString(strings ...string)
+//   ) WriteTo(w io.Writer) (int64, error)
 
 import (
 	"bytes"
+	"io"
 )
 
 // -----------------------------------------------------------------------------
@@ -96,4 +98,12 @@ func (ob *Buffer) WriteString(strings ...string) {
 	}
 } //                                                                 WriteString
 
+// WriteTo writes the contents of the buffer to 'w' and implements
+// the io.WriterTo interface. Unlike bytes.Buffer.WriteTo(),
+// it leaves the contents in the buffer.
+func (ob *Buffer) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(ob.html.Bytes())
+	return int64(n), err
+} //                                                                     WriteTo
+
 // end
@@ -18,9 +18,12 @@ package web
 //		web "github.com/balacode/zr-web"
 //	)
 //
+//	var sessions web.Sessions
+//
 //	func main() {
 //		http.HandleFunc("/", mainServe)
-//		log.Fatal(http.ListenAndServe("localhost:888", nil))
+//		server := &web.Server{Addr: "localhost:888", Sessions: &sessions}
+//		log.Fatal(server.ListenAndServe()) // stops on Ctrl+C or SIGTERM
 //	}
 //
 //	func mainServe(w http.ResponseWriter, req *http.Request) {
@@ -35,31 +38,52 @@ package web
 //        ) Context
 //
 // # Request Properties (ctx *Context)
+//   Accepts(types ...string) string
 //   BaseReferer() string
+//   ClientIP() string
+//   Host() string
 //   Method() string
 //   HREF() string
 //   PostData() []byte
 //   Referer() string
+//   Scheme() string
 //   URI() string
 //
 // # Methods (ctx *Context)
+//   IsSafeRedirect(url string) bool
+//   Negotiate(handlers map[string]func())
 //   Redirect(url string)
-//   Reply(data []byte, mediaType string)
+//   RedirectBack(fallback string)
+//   RedirectPermanent(url string)
+//   RedirectSeeOther(url string)
+//   Reply(content interface{}, mediaType string)
 //   ResetPostData()
+//   SafeRedirect(url, fallback string)
 //
 // # Debug Helper Method
 //   (ctx *Context) DebugString() string {
 
 // # Support (File Scope)
+//   acceptRange struct
+//   countingWriter struct
+//   (ob *countingWriter) Write(data []byte) (int, error)
+//   parseAccept(header string) []acceptRange
 //   readPostData(req *http.Request) []byte
+//   readerWriterTo struct
+//   (ob readerWriterTo) WriteTo(w io.Writer) (int64, error)
 
 import (
 	"bytes"
 	"fmt"
 	"hash/crc32"
+	"io"
 	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/balacode/zr"
 )
@@ -110,6 +134,7 @@ type Context struct {
 	id       int64               // serial number
 	w        http.ResponseWriter // an interface, so shouldn't be a pointer
 	req      *http.Request
+	sessions *Sessions
 	postData []byte
 } //                                                                     Context
 
@@ -124,9 +149,10 @@ func NewContext(w http.ResponseWriter, req *http.Request, sess *Sessions,
 ) Context {
 	nextContextID++
 	ret := Context{
-		id:  nextContextID,
-		req: req,
-		w:   w,
+		id:       nextContextID,
+		req:      req,
+		w:        w,
+		sessions: sess,
 	}
 	if sess != nil {
 		ret.Session = sess.GetByCookie(w, req)
@@ -169,6 +195,60 @@ func NewContext(w http.ResponseWriter, req *http.Request, sess *Sessions,
 // -----------------------------------------------------------------------------
 // # Request Properties (ctx *Context)
 
+// Accepts returns the best match for the request's 'Accept' header from
+// the given list of media types, or a blank string if none is acceptable.
+//
+// Each type can be a full MIME type like "text/html" or a short name
+// or file extension like "html" or "json", which is resolved using
+// MediaType(). The returned string is the type exactly as it was
+// passed in 'types', so it can be passed directly to Reply().
+//
+// The header's q-values and wildcards ("*/*", "text/*") are honored.
+// When several types are equally acceptable, the first one is returned.
+// If the request has no 'Accept' header, returns the first type.
+func (ctx *Context) Accepts(types ...string) string {
+	if len(types) == 0 {
+		return ""
+	}
+	ranges := parseAccept(ctx.req.Header.Get("Accept"))
+	if len(ranges) == 0 {
+		return types[0]
+	}
+	var (
+		ret   string
+		bestQ float64
+	)
+	for _, typ := range types {
+		mimeType := strings.ToLower(strings.TrimSpace(typ))
+		if !strings.Contains(mimeType, "/") {
+			mimeType = MediaType(mimeType)
+		}
+		var (
+			mainType, subType = mimeType, ""
+			q                 = -1.0
+			specificity       = -1
+		)
+		if i := strings.Index(mimeType, "/"); i != -1 {
+			mainType, subType = mimeType[:i], mimeType[i+1:]
+		}
+		// the most specific matching range determines the quality
+		for _, rng := range ranges {
+			if rng.specificity <= specificity {
+				continue
+			}
+			if (rng.mainType == "*" || rng.mainType == mainType) &&
+				(rng.subType == "*" || rng.subType == subType) {
+				q = rng.q
+				specificity = rng.specificity
+			}
+		}
+		if q > bestQ {
+			ret, bestQ = typ, q
+		}
+	}
+	return ret
+} //                                                                     Accepts
+
 // BaseReferer property returns the base referer path of
 // the current request. I.e. a path with '/', '\', '#'
 // and numbers stripped from the end.
@@ -177,6 +257,21 @@ func (ctx *Context) BaseReferer() string {
 	return ret
 } //                                                                 BaseReferer
 
+// ClientIP returns the IP address of the client that made the request.
+// If the request was passed on by a trusted proxy (see SetTrustedProxies)
+// the address is read from the 'Forwarded' or 'X-Forwarded-For' header.
+// Otherwise it is the address of the connection's remote end.
+func (ctx *Context) ClientIP() string {
+	return requestClientIP(ctx.req)
+} //                                                                    ClientIP
+
+// Host returns the host name (and port, if specified) requested by the
+// client. If the request was passed on by a trusted proxy, the host is
+// read from the 'Forwarded' or 'X-Forwarded-Host' header.
+func (ctx *Context) Host() string {
+	return requestHost(ctx.req)
+} //                                                                        Host
+
 // Method returns the request's HTTP method ('GET', 'POST', 'PUT', etc)
 func (ctx *Context) Method() string {
 	return strings.ToUpper(ctx.req.Method)
@@ -213,6 +308,13 @@ func (ctx *Context) Referer() string {
 	return ret
 } //                                                                     Referer
 
+// Scheme returns "https" if the client made the request over TLS,
+// or "http" otherwise. If the request was passed on by a trusted proxy,
+// the scheme is read from the 'Forwarded' or 'X-Forwarded-Proto' header.
+func (ctx *Context) Scheme() string {
+	return requestScheme(ctx.req)
+} //                                                                      Scheme
+
 // URI property returns the full URI path of the current request.
 // This includes the HREF() part and any query parameters.
 func (ctx *Context) URI() string {
@@ -221,16 +323,82 @@ func (ctx *Context) URI() string {
 		ret = strings.ReplaceAll(ret, "\\", "/")
 	}
 	return ret
-} //                                                                        URI
+} //                                                                         URI
 
 // -----------------------------------------------------------------------------
 // # Methods (ctx *Context)
 
+// IsSafeRedirect returns true if 'url' can be used as a redirect target
+// without creating an open redirect: i.e. it is a relative URL, or an
+// http/https URL to the requested host or one of the hosts allowed
+// with SetRedirectHosts(). Other schemes (e.g. 'javascript:') and
+// URLs containing backslashes or control characters are rejected.
+func (ctx *Context) IsSafeRedirect(url string) bool {
+	return isSafeRedirect(url, ctx.Host())
+} //                                                              IsSafeRedirect
+
+// Negotiate calls the handler whose media type best matches the
+// request's 'Accept' header. The keys of 'handlers' are media types
+// or short names accepted by Accepts(), e.g. "html", "json" or "csv".
+// Since maps are unordered, equally acceptable types are tried in
+// alphabetical order of their keys. When none of the types is
+// acceptable, replies with HTTP status 406 (Not Acceptable).
+func (ctx *Context) Negotiate(handlers map[string]func()) {
+	types := make([]string, 0, len(handlers))
+	for typ := range handlers {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	typ := ctx.Accepts(types...)
+	if typ == "" || handlers[typ] == nil {
+		http.Error(ctx.w, http.StatusText(http.StatusNotAcceptable),
+			http.StatusNotAcceptable)
+		return
+	}
+	handlers[typ]()
+} //                                                                   Negotiate
+
 // Redirect redirects the client to another url using
 // HTTP redirect code 302 (temporary redirect).
 func (ctx *Context) Redirect(url string) {
 	http.Redirect(ctx.w, ctx.req, url, http.StatusFound)
-} // 																	Redirect
+} //                                                                    Redirect
+
+// RedirectBack redirects the client to the page it came from, as given
+// by the 'Referer' header, using HTTP redirect code 303 (see other).
+// If the referer is not a safe target (see IsSafeRedirect), tries the
+// referer's base path (see BaseReferer). If there is no referer, or
+// neither is safe, redirects to 'fallback' instead.
+func (ctx *Context) RedirectBack(fallback string) {
+	url := ctx.req.Referer()
+	if !ctx.IsSafeRedirect(url) {
+		url = ctx.BaseReferer()
+	}
+	if !ctx.IsSafeRedirect(url) {
+		url = fallback
+	}
+	http.Redirect(ctx.w, ctx.req, url, http.StatusSeeOther)
+} //                                                                RedirectBack
+
+// RedirectPermanent redirects the client to another url permanently.
+// GET and HEAD requests get HTTP redirect code 301 (moved permanently),
+// other methods get 308 (permanent redirect) so that clients repeat
+// the same method and body at the new url.
+func (ctx *Context) RedirectPermanent(url string) {
+	code := http.StatusPermanentRedirect
+	if method := ctx.Method(); method == "GET" || method == "HEAD" {
+		code = http.StatusMovedPermanently
+	}
+	http.Redirect(ctx.w, ctx.req, url, code)
+} //                                                           RedirectPermanent
+
+// RedirectSeeOther redirects the client to another url using HTTP
+// redirect code 303 (see other). The client then loads the new url
+// with GET. Use it after handling a POST, so that reloading the
+// resulting page doesn't resubmit the form.
+func (ctx *Context) RedirectSeeOther(url string) {
+	http.Redirect(ctx.w, ctx.req, url, http.StatusSeeOther)
+} //                                                            RedirectSeeOther
 
 // Reply method sends the reply to a request.
 // Specify 'mediaType' to set 'Content-Type' in the HTTP header.
@@ -238,8 +406,11 @@ func (ctx *Context) Redirect(url string) {
 // in which case it gets converted to a proper MIME type,
 // e.g. 'application/pdf' or 'image/png'
 // Use the file extension value, e.g. "pdf"
-func (ctx *Context) Reply(data []byte, mediaType string) {
-	//IDEA: it would be good if Reply() accepted strings, io.Reader, etc.
+//
+// 'content' can be a []byte, string or *Buffer, or an io.WriterTo
+// (such as a *Node) or io.Reader, which are written straight to the
+// client, without building the whole reply in memory first.
+func (ctx *Context) Reply(content interface{}, mediaType string) {
 	mediaType = MediaType(mediaType)
 	if mediaType != "" {
 		ctx.w.Header().Set("Content-Type", mediaType)
@@ -247,6 +418,40 @@ func (ctx *Context) Reply(data []byte, mediaType string) {
 	if mediaType == "" {
 		zr.Error(zr.EInvalidArg, "^mediaType", ":^", mediaType)
 	}
+	var data []byte
+	var stream io.WriterTo
+	switch v := content.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case *Buffer:
+		if v != nil {
+			data = v.Bytes()
+		}
+	case io.WriterTo:
+		stream = v
+	case io.Reader:
+		stream = readerWriterTo{v}
+	case nil:
+	default:
+		zr.Error(zr.EInvalidArg, "^content", "of type", reflect.TypeOf(v))
+		return
+	}
+	if stream != nil && ContextDebugFunc == nil {
+		cw := &countingWriter{w: ctx.w}
+		_, err := stream.WriteTo(cw)
+		atomic.AddUint64(&replyBytesTotal, uint64(cw.n))
+		if err != nil {
+			zr.Error("Failed sending reply:", err)
+		}
+		return
+	}
+	if stream != nil {
+		var buf bytes.Buffer
+		stream.WriteTo(&buf)
+		data = buf.Bytes()
+	}
 	if ContextDebugFunc != nil {
 		const LE = " \n"                 // line end
 		defer contextDebugMutex.Unlock() // locked by NewContext()
@@ -282,7 +487,8 @@ func (ctx *Context) Reply(data []byte, mediaType string) {
 			LE,
 		)
 	}
-	ctx.w.Write(data)
+	n, _ := ctx.w.Write(data)
+	atomic.AddUint64(&replyBytesTotal, uint64(n))
 } //                                                                       Reply
 
 // ResetPostData _ _
@@ -290,6 +496,18 @@ func (ctx *Context) ResetPostData() {
 	ctx.postData = []byte{}
 } //                                                               ResetPostData
 
+// SafeRedirect redirects the client to 'url' using HTTP redirect
+// code 303 (see other), but only if it passes IsSafeRedirect().
+// Otherwise redirects to 'fallback', which should be a fixed,
+// known-good url. Use it for targets that come from the request,
+// e.g. the '?next=' parameter of a login page.
+func (ctx *Context) SafeRedirect(url, fallback string) {
+	if !ctx.IsSafeRedirect(url) {
+		url = fallback
+	}
+	http.Redirect(ctx.w, ctx.req, url, http.StatusSeeOther)
+} //                                                                SafeRedirect
+
 // -----------------------------------------------------------------------------
 // # Debug Helper Method
 
@@ -308,6 +526,70 @@ func (ctx *Context) DebugString() string {
 // -----------------------------------------------------------------------------
 // # Support (File Scope)
 
+// acceptRange holds one media range parsed from an 'Accept' header.
+type acceptRange struct {
+	mainType    string
+	subType     string
+	q           float64
+	specificity int // 0 for */*, 1 for type/*, 2 for type/subtype
+} //                                                                 acceptRange
+
+// countingWriter is a writer that counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+} //                                                              countingWriter
+
+// Write writes 'data' to the underlying writer.
+func (ob *countingWriter) Write(data []byte) (int, error) {
+	n, err := ob.w.Write(data)
+	ob.n += int64(n)
+	return n, err
+} //                                                                       Write
+
+// parseAccept parses the value of an 'Accept' header into a list of
+// media ranges. Ranges with an invalid quality value are skipped.
+func parseAccept(header string) []acceptRange {
+	var ret []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mimeType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mimeType == "" {
+			continue
+		}
+		rng := acceptRange{mainType: mimeType, subType: "*", q: 1}
+		if i := strings.Index(mimeType, "/"); i != -1 {
+			rng.mainType, rng.subType = mimeType[:i], mimeType[i+1:]
+		}
+		switch {
+		case rng.mainType == "*":
+			rng.specificity = 0
+		case rng.subType == "*":
+			rng.specificity = 1
+		default:
+			rng.specificity = 2
+		}
+		valid := true
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") &&
+				!strings.HasPrefix(param, "Q=") {
+				continue
+			}
+			q, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil || q < 0 || q > 1 {
+				valid = false
+				break
+			}
+			rng.q = q
+		}
+		if valid {
+			ret = append(ret, rng)
+		}
+	}
+	return ret
+} //                                                                 parseAccept
+
 // readPostData reads the content of a POST HTTP request into a byte array.
 // This function should only be called once on a Request.
 // Subsequent calls on the same Request will return an empty array.
@@ -325,4 +607,14 @@ func readPostData(req *http.Request) []byte {
 	return ret
 } //                                                                readPostData
 
+// readerWriterTo turns an io.Reader into an io.WriterTo
+type readerWriterTo struct {
+	r io.Reader
+} //                                                              readerWriterTo
+
+// WriteTo copies the reader's content to 'w'.
+func (ob readerWriterTo) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, ob.r)
+} //                                                                     WriteTo
+
 // end
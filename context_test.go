@@ -0,0 +1,374 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                             zr-web/[context_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Request Properties
+//   Test_ctxt_Context_Accepts_
+//   Test_ctxt_Context_ClientIP_
+//   Test_ctxt_Context_Host_
+//   Test_ctxt_Context_Scheme_
+//
+// # Methods
+//   Test_ctxt_Context_IsSafeRedirect_
+//   Test_ctxt_Context_Negotiate_
+//   Test_ctxt_Context_RedirectBack_
+//   Test_ctxt_Context_RedirectPermanent_
+//   Test_ctxt_Context_Reply_
+//   Test_ctxt_Context_SafeRedirect_
+
+//  to test all items in context.go use:
+//      go test --run Test_ctxt_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Request Properties
+
+// go test --run Test_ctxt_Context_Accepts_
+func Test_ctxt_Context_Accepts_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) Accepts(types ...string) string
+	//
+	test := func(accept string, types []string, expect string) {
+		req := httptest.NewRequest("GET", "/", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		ctx := NewContext(httptest.NewRecorder(), req, nil)
+		zr.TEqual(t, ctx.Accepts(types...), expect)
+	}
+	// no header: first type wins
+	test("", []string{"json", "html"}, "json")
+	//
+	// exact matches and short names
+	test("application/json", []string{"html", "json"}, "json")
+	test("text/html", []string{"json", "text/html"}, "text/html")
+	test("text/csv", []string{"html", "json", "csv"}, "csv")
+	//
+	// q-values
+	test("text/html;q=0.5, application/json", []string{"html", "json"},
+		"json")
+	test("text/html, application/json;q=0.9", []string{"json", "html"},
+		"html")
+	//
+	// wildcards and specificity
+	test("*/*", []string{"json", "html"}, "json")
+	test("text/*", []string{"json", "html"}, "html")
+	test("text/*;q=0.1, text/html", []string{"csv", "html"}, "html")
+	test("*/*;q=0.1, text/html;q=0", []string{"html", "json"}, "json")
+	//
+	// nothing acceptable
+	test("image/png", []string{"html", "json"}, "")
+	test("text/html;q=0", []string{"html"}, "")
+	test("text/html", nil, "")
+} //                                                  Test_ctxt_Context_Accepts_
+
+// go test --run Test_ctxt_Context_ClientIP_
+func Test_ctxt_Context_ClientIP_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) ClientIP() string
+	//
+	defer SetTrustedProxies()
+	zr.TEqual(t, SetTrustedProxies("10.0.0.0/8", "::1"), nil)
+	zr.TTrue(t, SetTrustedProxies("10.0.0.0/33") != nil)
+	zr.TEqual(t, TrustedProxies(), []string{"10.0.0.0/8", "::1/128"})
+	//
+	test := func(remoteAddr string, headers map[string]string, expect string) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		ctx := NewContext(httptest.NewRecorder(), req, nil)
+		zr.TEqual(t, ctx.ClientIP(), expect)
+	}
+	// no proxy
+	test("1.2.3.4:5678", nil, "1.2.3.4")
+	//
+	// headers from untrusted peers are ignored
+	test("1.2.3.4:5678", map[string]string{
+		"X-Forwarded-For": "6.6.6.6",
+	}, "1.2.3.4")
+	//
+	// X-Forwarded-For from trusted proxies
+	test("10.0.0.1:80", map[string]string{
+		"X-Forwarded-For": "6.6.6.6, 1.2.3.4",
+	}, "1.2.3.4")
+	test("10.0.0.1:80", map[string]string{
+		"X-Forwarded-For": "1.2.3.4, 10.0.0.2",
+	}, "1.2.3.4")
+	test("[::1]:80", map[string]string{
+		"X-Forwarded-For": "2001:db8::17",
+	}, "2001:db8::17")
+	//
+	// Forwarded takes precedence over X-Forwarded-For
+	test("10.0.0.1:80", map[string]string{
+		"Forwarded":       `for="[2001:db8::17]:4711";proto=https`,
+		"X-Forwarded-For": "6.6.6.6",
+	}, "2001:db8::17")
+	test("10.0.0.1:80", map[string]string{
+		"Forwarded": "for=1.2.3.4, for=10.0.0.5",
+	}, "1.2.3.4")
+	test("10.0.0.1:80", map[string]string{
+		"Forwarded": "for=_hidden, for=10.0.0.5",
+	}, "10.0.0.5")
+} //                                                 Test_ctxt_Context_ClientIP_
+
+// go test --run Test_ctxt_Context_Host_
+func Test_ctxt_Context_Host_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) Host() string
+	//
+	defer SetTrustedProxies()
+	SetTrustedProxies("10.0.0.0/8")
+	//
+	test := func(remoteAddr string, headers map[string]string, expect string) {
+		req := httptest.NewRequest("GET", "http://internal:8080/", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		ctx := NewContext(httptest.NewRecorder(), req, nil)
+		zr.TEqual(t, ctx.Host(), expect)
+	}
+	test("1.2.3.4:5678", nil, "internal:8080")
+	test("1.2.3.4:5678", map[string]string{
+		"X-Forwarded-Host": "example.com",
+	}, "internal:8080")
+	test("10.0.0.1:80", map[string]string{
+		"X-Forwarded-Host": "example.com",
+	}, "example.com")
+	test("10.0.0.1:80", map[string]string{
+		"Forwarded": `host="example.org";proto=https`,
+	}, "example.org")
+	//
+	// hosts forged by the client, before those added by trusted proxies
+	test("10.0.0.1:80", map[string]string{
+		"X-Forwarded-Host": "evil.com, example.com",
+	}, "example.com")
+	test("10.0.0.1:80", map[string]string{
+		"Forwarded": `for=1.2.3.4;host=evil.com, for=5.6.7.8;host=example.org`,
+	}, "example.org")
+	test("10.0.0.1:80", map[string]string{
+		"Forwarded": `host=evil.com, for=5.6.7.8`,
+	}, "internal:8080")
+	//
+	// hops added by trusted proxies are skipped
+	test("10.0.0.1:80", map[string]string{
+		"Forwarded": `for=1.2.3.4;host=evil.com, ` +
+			`for=5.6.7.8;host=example.org, for=10.0.0.2;host=internal`,
+	}, "example.org")
+} //                                                     Test_ctxt_Context_Host_
+
+// go test --run Test_ctxt_Context_Scheme_
+func Test_ctxt_Context_Scheme_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) Scheme() string
+	//
+	defer SetTrustedProxies()
+	SetTrustedProxies("10.0.0.0/8")
+	//
+	test := func(remoteAddr string, headers map[string]string, expect string) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		ctx := NewContext(httptest.NewRecorder(), req, nil)
+		zr.TEqual(t, ctx.Scheme(), expect)
+	}
+	test("1.2.3.4:5678", nil, "http")
+	test("1.2.3.4:5678", map[string]string{
+		"X-Forwarded-Proto": "https",
+	}, "http")
+	test("10.0.0.1:80", map[string]string{
+		"X-Forwarded-Proto": "https",
+	}, "https")
+	test("10.0.0.1:80", map[string]string{
+		"Forwarded": "for=1.2.3.4;proto=https",
+	}, "https")
+	test("10.0.0.1:80", map[string]string{
+		"X-Forwarded-Proto": "gopher",
+	}, "http")
+	//
+	// schemes forged by the client are ignored
+	test("10.0.0.1:80", map[string]string{
+		"X-Forwarded-Proto": "https, http",
+	}, "http")
+	test("10.0.0.1:80", map[string]string{
+		"Forwarded": "for=1.2.3.4;proto=https, for=5.6.7.8;proto=http",
+	}, "http")
+	//
+	// direct TLS connection
+	req := httptest.NewRequest("GET", "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	ctx := NewContext(httptest.NewRecorder(), req, nil)
+	zr.TEqual(t, ctx.Scheme(), "https")
+} //                                                   Test_ctxt_Context_Scheme_
+
+// -----------------------------------------------------------------------------
+// # Methods
+
+// go test --run Test_ctxt_Context_IsSafeRedirect_
+func Test_ctxt_Context_IsSafeRedirect_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) IsSafeRedirect(url string) bool
+	//
+	defer SetRedirectHosts()
+	SetRedirectHosts("accounts.example.org", "*.example.net")
+	zr.TEqual(t, RedirectHosts(),
+		[]string{"accounts.example.org", "*.example.net"})
+	//
+	req := httptest.NewRequest("GET", "http://example.com/login", nil)
+	ctx := NewContext(httptest.NewRecorder(), req, nil)
+	test := func(url string, expect bool) {
+		zr.TEqual(t, ctx.IsSafeRedirect(url), expect)
+	}
+	// relative and same-host URLs
+	test("/", true)
+	test("/account?tab=1", true)
+	test("page", true)
+	test("http://example.com/x", true)
+	test("https://EXAMPLE.com:8443/x", true)
+	//
+	// allowed hosts
+	test("https://accounts.example.org/", true)
+	test("https://a.example.net/", true)
+	test("https://a.b.example.net/", true)
+	test("https://example.net/", false)
+	test("https://badexample.net/", false)
+	//
+	// open redirect attempts
+	test("", false)
+	test("//evil.com", false)
+	test("https://evil.com", false)
+	test(`/\evil.com`, false)
+	test("/\tevil.com", false)
+	test("javascript:alert(1)", false)
+	test("http:evil.com", false)
+	test("ftp://example.com/", false)
+} //                                           Test_ctxt_Context_IsSafeRedirect_
+
+// go test --run Test_ctxt_Context_Negotiate_
+func Test_ctxt_Context_Negotiate_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) Negotiate(handlers map[string]func())
+	//
+	test := func(accept string, expectCalled string, expectStatus int) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		ctx := NewContext(rec, req, nil)
+		var called string
+		ctx.Negotiate(map[string]func(){
+			"html": func() { called = "html" },
+			"json": func() { called = "json" },
+		})
+		zr.TEqual(t, called, expectCalled)
+		zr.TEqual(t, rec.Code, expectStatus)
+	}
+	test("application/json", "json", http.StatusOK)
+	test("text/html, */*;q=0.8", "html", http.StatusOK)
+	test("*/*", "html", http.StatusOK)
+	test("image/png", "", http.StatusNotAcceptable)
+} //                                                Test_ctxt_Context_Negotiate_
+
+// go test --run Test_ctxt_Context_RedirectBack_
+func Test_ctxt_Context_RedirectBack_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) RedirectBack(fallback string)
+	//
+	test := func(referer, expect string) {
+		req := httptest.NewRequest("POST", "http://example.com/save", nil)
+		req.Header.Set("Referer", referer)
+		rec := httptest.NewRecorder()
+		ctx := NewContext(rec, req, nil)
+		ctx.RedirectBack("/home")
+		zr.TEqual(t, rec.Code, http.StatusSeeOther)
+		zr.TEqual(t, rec.Header().Get("Location"), expect)
+	}
+	test("http://example.com/edit/5", "http://example.com/edit/5")
+	test("", "/home")
+	test("https://evil.com/", "/home")
+	//
+	// an unsafe referer falls back to its base path, then to 'fallback'
+	test(`http://example.com/edit/5\`, "http://example.com/edit")
+	test(`https://evil.com/edit/5\`, "/home")
+} //                                             Test_ctxt_Context_RedirectBack_
+
+// go test --run Test_ctxt_Context_RedirectPermanent_
+func Test_ctxt_Context_RedirectPermanent_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) RedirectPermanent(url string)
+	//
+	test := func(method string, expect int) {
+		req := httptest.NewRequest(method, "/old", nil)
+		rec := httptest.NewRecorder()
+		ctx := NewContext(rec, req, nil)
+		ctx.RedirectPermanent("/new")
+		zr.TEqual(t, rec.Code, expect)
+		zr.TEqual(t, rec.Header().Get("Location"), "/new")
+	}
+	test("GET", http.StatusMovedPermanently)
+	test("HEAD", http.StatusMovedPermanently)
+	test("POST", http.StatusPermanentRedirect)
+} //                                        Test_ctxt_Context_RedirectPermanent_
+
+// go test --run Test_ctxt_Context_Reply_
+func Test_ctxt_Context_Reply_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) Reply(content interface{}, mediaType string)
+	//
+	test := func(content interface{}, mediaType, expectType, expect string) {
+		rec := httptest.NewRecorder()
+		ctx := NewContext(rec, httptest.NewRequest("GET", "/", nil), nil)
+		before := atomic.LoadUint64(&replyBytesTotal)
+		ctx.Reply(content, mediaType)
+		zr.TEqual(t, rec.Header().Get("Content-Type"), expectType)
+		zr.TEqual(t, rec.Body.String(), expect)
+		zr.TTrue(t, atomic.LoadUint64(&replyBytesTotal)-before >=
+			uint64(len(expect)))
+	}
+	test([]byte("abc"), "txt", "text/plain", "abc")
+	test("<p>x</p>", "html", "text/html", "<p>x</p>")
+	test(P("x"), "html", "text/html", "<p>x</p>\r\n")
+	test(ContainerNode("p", "x"), "html", "text/html", "<p>x</p>\r\n")
+	test(strings.NewReader(`{"a":1}`), "json", "application/json", `{"a":1}`)
+	test(nil, "txt", "text/plain", "")
+} //                                                    Test_ctxt_Context_Reply_
+
+// go test --run Test_ctxt_Context_SafeRedirect_
+func Test_ctxt_Context_SafeRedirect_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) SafeRedirect(url, fallback string)
+	//
+	test := func(next, expect string) {
+		req := httptest.NewRequest("POST", "http://example.com/login", nil)
+		rec := httptest.NewRecorder()
+		ctx := NewContext(rec, req, nil)
+		ctx.SafeRedirect(next, "/")
+		zr.TEqual(t, rec.Code, http.StatusSeeOther)
+		zr.TEqual(t, rec.Header().Get("Location"), expect)
+	}
+	test("/orders", "/orders")
+	test("//evil.com/", "/")
+	test("https://evil.com/", "/")
+} //                                             Test_ctxt_Context_SafeRedirect_
+
+// end
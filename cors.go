@@ -0,0 +1,244 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                     zr-web/[cors.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	CORS is a middleware that lets pages from other origins call
+//	the wrapped handlers (Cross-Origin Resource Sharing):
+//
+//	cors := &web.CORS{
+//		AllowedOrigins:   []string{"https://app.example.com"},
+//		AllowedMethods:   []string{"GET", "POST", "DELETE"},
+//		AllowedHeaders:   []string{"Content-Type", "X-Requested-With"},
+//		AllowCredentials: true,
+//		MaxAge:           time.Hour,
+//	}
+//	http.Handle("/api/", cors.Handler(http.HandlerFunc(apiServe)))
+//
+//	Preflight requests (OPTIONS with 'Access-Control-Request-Method')
+//	are answered by the middleware and never reach the handler, so
+//	handlers that reply 405 (Method Not Allowed) to OPTIONS don't
+//	break them. CORS headers are set before the handler runs, so
+//	they are also present on the handler's error replies.
+
+//  CORS struct
+//
+// # Methods (ob *CORS)
+//   ) Handler(next http.Handler) http.Handler
+//   ) HandlerFunc(next http.HandlerFunc) http.HandlerFunc
+//
+// # Support (File Scope)
+//   (ob *CORS) isAllowedOrigin(origin string) bool
+//   (ob *CORS) isListedOrigin(origin string) bool
+//   (ob *CORS) isAllowedMethod(method string) bool
+//   (ob *CORS) areAllowedHeaders(headers string) bool
+//   (ob *CORS) preflight(w http.ResponseWriter, req *http.Request,
+//       origin string)
+//   (ob *CORS) setOriginHeaders(header http.Header, origin string)
+//   containsFold(list []string, s string) bool
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS holds the settings of the CORS middleware.
+type CORS struct {
+	// AllowedOrigins lists the origins allowed to make requests, e.g.
+	// "https://example.com". An origin can have a wildcard subdomain,
+	// e.g. "https://*.example.com". "*" allows all origins, but only
+	// without credentials: see AllowCredentials.
+	AllowedOrigins []string
+
+	// AllowOriginFunc, if set, is called for origins not
+	// in AllowedOrigins, and allows them if it returns true.
+	AllowOriginFunc func(origin string) bool
+
+	// AllowedMethods lists the allowed methods.
+	// If empty, only GET, HEAD and POST are allowed.
+	AllowedMethods []string
+
+	// AllowedHeaders lists the request headers clients can send,
+	// in addition to the always-allowed simple headers. "*"
+	// allows all headers.
+	AllowedHeaders []string
+
+	// ExposedHeaders lists reply headers that
+	// scripts are allowed to read.
+	ExposedHeaders []string
+
+	// AllowCredentials allows requests with cookies or
+	// HTTP authentication, e.g. to use the session. Credentials
+	// are only allowed for origins listed in AllowedOrigins or
+	// allowed by AllowOriginFunc, never for those allowed by "*".
+	AllowCredentials bool
+
+	// MaxAge specifies how long browsers may cache a preflight reply.
+	MaxAge time.Duration
+} //                                                                        CORS
+
+// corsDefaultMethods are allowed when CORS.AllowedMethods is empty
+var corsDefaultMethods = []string{"GET", "HEAD", "POST"}
+
+// corsSimpleHeaders can be sent without being listed in AllowedHeaders
+var corsSimpleHeaders = []string{
+	"Accept", "Accept-Language", "Content-Language", "Content-Type",
+}
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *CORS)
+
+// Handler wraps 'next' with CORS handling. Requests from allowed
+// origins get CORS headers; preflight requests are answered with
+// HTTP status 204 (No Content) if allowed, or 403 (Forbidden).
+func (ob *CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		origin := req.Header.Get("Origin")
+		if req.Method == "OPTIONS" &&
+			req.Header.Get("Access-Control-Request-Method") != "" {
+			ob.preflight(w, req, origin)
+			return
+		}
+		if origin != "" && ob.isAllowedOrigin(origin) {
+			ob.setOriginHeaders(header, origin)
+			if len(ob.ExposedHeaders) > 0 {
+				header.Set("Access-Control-Expose-Headers",
+					strings.Join(ob.ExposedHeaders, ", "))
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+} //                                                                     Handler
+
+// HandlerFunc is like Handler(), but wraps a handler function
+// such as one passed to http.HandleFunc().
+func (ob *CORS) HandlerFunc(next http.HandlerFunc) http.HandlerFunc {
+	return ob.Handler(next).ServeHTTP
+} //                                                                 HandlerFunc
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// isAllowedOrigin returns true if requests from 'origin' are allowed.
+func (ob *CORS) isAllowedOrigin(origin string) bool {
+	return containsFold(ob.AllowedOrigins, "*") || ob.isListedOrigin(origin)
+} //                                                             isAllowedOrigin
+
+// isListedOrigin returns true if 'origin' is allowed by name or by
+// a wildcard subdomain in AllowedOrigins, or by AllowOriginFunc.
+// Unlike isAllowedOrigin(), it ignores "*".
+func (ob *CORS) isListedOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range ob.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if allowed == origin {
+			return true
+		}
+		// wildcard subdomain, e.g. "https://*.example.com"
+		if i := strings.Index(allowed, "://*."); i != -1 {
+			prefix, suffix := allowed[:i+3], allowed[i+4:]
+			if strings.HasPrefix(origin, prefix) &&
+				strings.HasSuffix(origin, suffix) &&
+				len(origin) > len(prefix)+len(suffix) {
+				return true
+			}
+		}
+	}
+	return ob.AllowOriginFunc != nil && ob.AllowOriginFunc(origin)
+} //                                                              isListedOrigin
+
+// isAllowedMethod returns true if 'method' is allowed.
+func (ob *CORS) isAllowedMethod(method string) bool {
+	methods := ob.AllowedMethods
+	if len(methods) == 0 {
+		methods = corsDefaultMethods
+	}
+	return containsFold(methods, method)
+} //                                                             isAllowedMethod
+
+// areAllowedHeaders returns true if all headers in the
+// comma-separated list 'headers' are allowed.
+func (ob *CORS) areAllowedHeaders(headers string) bool {
+	if containsFold(ob.AllowedHeaders, "*") {
+		return true
+	}
+	for _, name := range strings.Split(headers, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || containsFold(corsSimpleHeaders, name) {
+			continue
+		}
+		if !containsFold(ob.AllowedHeaders, name) {
+			return false
+		}
+	}
+	return true
+} //                                                           areAllowedHeaders
+
+// preflight replies to a preflight request.
+func (ob *CORS) preflight(w http.ResponseWriter, req *http.Request,
+	origin string) {
+	header := w.Header()
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+	var (
+		method   = req.Header.Get("Access-Control-Request-Method")
+		headers  = req.Header.Get("Access-Control-Request-Headers")
+		mayCross = origin != "" && ob.isAllowedOrigin(origin)
+	)
+	if !mayCross || !ob.isAllowedMethod(method) ||
+		!ob.areAllowedHeaders(headers) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	ob.setOriginHeaders(header, origin)
+	methods := ob.AllowedMethods
+	if len(methods) == 0 {
+		methods = corsDefaultMethods
+	}
+	header.Set("Access-Control-Allow-Methods",
+		strings.ToUpper(strings.Join(methods, ", ")))
+	if strings.TrimSpace(headers) != "" {
+		// echo the requested headers, which were all found allowed
+		header.Set("Access-Control-Allow-Headers", headers)
+	}
+	if ob.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age",
+			strconv.FormatInt(int64(ob.MaxAge.Seconds()), 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+} //                                                                   preflight
+
+// setOriginHeaders sets 'Access-Control-Allow-Origin' and
+// 'Access-Control-Allow-Credentials' for an allowed origin.
+func (ob *CORS) setOriginHeaders(header http.Header, origin string) {
+	// echoing any origin with credentials would let every site
+	// read replies using the user's cookies, so "*" never does
+	if ob.AllowCredentials && ob.isListedOrigin(origin) {
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Allow-Credentials", "true")
+		return
+	}
+	if containsFold(ob.AllowedOrigins, "*") {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+} //                                                            setOriginHeaders
+
+// containsFold returns true if 'list' contains 's', ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+} //                                                                containsFold
+
+// end
@@ -0,0 +1,170 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                zr-web/[cors_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Methods (ob *CORS)
+//   Test_cors_CORS_Handler_
+
+//  to test all items in cors.go use:
+//      go test --run Test_cors_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *CORS)
+
+// go test --run Test_cors_CORS_Handler_
+func Test_cors_CORS_Handler_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *CORS) Handler(next http.Handler) http.Handler
+	//
+	cors := &CORS{
+		AllowedOrigins: []string{
+			"https://app.example.com", "https://*.example.org",
+		},
+		AllowOriginFunc: func(origin string) bool {
+			return strings.HasSuffix(origin, ".test")
+		},
+		AllowedMethods: []string{"GET", "POST", "DELETE"},
+		AllowedHeaders: []string{"X-Token"},
+		ExposedHeaders: []string{"X-Total"},
+		MaxAge:         10 * time.Minute,
+	}
+	// the handler only allows GET, like a router replying 405
+	handler := cors.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.Error(w, "", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	serve := func(method, origin string, headers map[string]string,
+	) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+	// simple requests
+	{
+		rec := serve("GET", "https://app.example.com", nil)
+		zr.TEqual(t, rec.Code, http.StatusOK)
+		zr.TEqual(t, rec.Header().Get("Access-Control-Allow-Origin"),
+			"https://app.example.com")
+		zr.TEqual(t, rec.Header().Get("Access-Control-Expose-Headers"),
+			"X-Total")
+		zr.TEqual(t, rec.Header().Get("Vary"), "Origin")
+	}
+	test := func(origin string, expectAllowed bool) {
+		rec := serve("GET", origin, nil)
+		allowed := rec.Header().Get("Access-Control-Allow-Origin") != ""
+		zr.TEqual(t, allowed, expectAllowed)
+	}
+	test("", false)
+	test("https://evil.com", false)
+	test("https://a.example.org", true)
+	test("https://a.b.example.org", true)
+	test("https://example.org", false)
+	test("http://a.example.org", false)
+	test("https://x.test", true)
+	//
+	// CORS headers are kept on the handler's error replies
+	{
+		rec := serve("DELETE", "https://app.example.com", nil)
+		zr.TEqual(t, rec.Code, http.StatusMethodNotAllowed)
+		zr.TEqual(t, rec.Header().Get("Access-Control-Allow-Origin"),
+			"https://app.example.com")
+	}
+	// preflight requests don't reach the handler
+	{
+		rec := serve("OPTIONS", "https://app.example.com", map[string]string{
+			"Access-Control-Request-Method":  "DELETE",
+			"Access-Control-Request-Headers": "x-token, content-type",
+		})
+		h := rec.Header()
+		zr.TEqual(t, rec.Code, http.StatusNoContent)
+		zr.TEqual(t, h.Get("Access-Control-Allow-Origin"),
+			"https://app.example.com")
+		zr.TEqual(t, h.Get("Access-Control-Allow-Methods"),
+			"GET, POST, DELETE")
+		zr.TEqual(t, h.Get("Access-Control-Allow-Headers"),
+			"x-token, content-type")
+		zr.TEqual(t, h.Get("Access-Control-Max-Age"), "600")
+		zr.TEqual(t, h.Values("Vary"), []string{"Origin",
+			"Access-Control-Request-Method", "Access-Control-Request-Headers"})
+	}
+	preflight := func(origin, method, headers string, expect int) {
+		rec := serve("OPTIONS", origin, map[string]string{
+			"Access-Control-Request-Method":  method,
+			"Access-Control-Request-Headers": headers,
+		})
+		zr.TEqual(t, rec.Code, expect)
+	}
+	preflight("https://evil.com", "GET", "", http.StatusForbidden)
+	preflight("https://app.example.com", "PUT", "", http.StatusForbidden)
+	preflight("https://app.example.com", "GET", "X-Other",
+		http.StatusForbidden)
+	preflight("https://a.example.org", "post", "", http.StatusNoContent)
+	//
+	// plain OPTIONS requests are passed to the handler
+	zr.TEqual(t, serve("OPTIONS", "https://app.example.com", nil).Code,
+		http.StatusMethodNotAllowed)
+	//
+	// wildcard origin, with and without credentials
+	{
+		cors := &CORS{AllowedOrigins: []string{"*"}}
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Origin", "https://x.com")
+		cors.HandlerFunc(func(http.ResponseWriter, *http.Request) {})(rec, req)
+		zr.TEqual(t, rec.Header().Get("Access-Control-Allow-Origin"), "*")
+		//
+		// credentials are not allowed for origins allowed by "*"
+		cors.AllowCredentials = true
+		rec = httptest.NewRecorder()
+		cors.HandlerFunc(func(http.ResponseWriter, *http.Request) {})(rec, req)
+		zr.TEqual(t, rec.Header().Get("Access-Control-Allow-Origin"), "*")
+		zr.TEqual(t, rec.Header().Get("Access-Control-Allow-Credentials"),
+			"")
+		//
+		// but they are for listed origins
+		cors.AllowedOrigins = append(cors.AllowedOrigins, "https://x.com")
+		rec = httptest.NewRecorder()
+		cors.HandlerFunc(func(http.ResponseWriter, *http.Request) {})(rec, req)
+		zr.TEqual(t, rec.Header().Get("Access-Control-Allow-Origin"),
+			"https://x.com")
+		zr.TEqual(t, rec.Header().Get("Access-Control-Allow-Credentials"),
+			"true")
+		//
+		// and an unlisted origin still gets no credentials
+		req.Header.Set("Origin", "https://evil.com")
+		rec = httptest.NewRecorder()
+		cors.HandlerFunc(func(http.ResponseWriter, *http.Request) {})(rec, req)
+		zr.TEqual(t, rec.Header().Get("Access-Control-Allow-Origin"), "*")
+		zr.TEqual(t, rec.Header().Get("Access-Control-Allow-Credentials"),
+			"")
+	}
+} //                                                     Test_cors_CORS_Handler_
+
+// end
@@ -0,0 +1,571 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                 zr-web/[elements.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+// Code generated by elements_gen.go. DO NOT EDIT.
+
+package web
+
+// # Sections
+//   Aside(content ...interface{}) *Buffer
+//   AsideNode(content ...interface{}) *Node
+//   Footer(content ...interface{}) *Buffer
+//   FooterNode(content ...interface{}) *Node
+//   Main(content ...interface{}) *Buffer
+//   MainNode(content ...interface{}) *Node
+//   Section(content ...interface{}) *Buffer
+//   SectionNode(content ...interface{}) *Node
+//
+// # Grouping
+//   Dd(content ...interface{}) *Buffer
+//   DdNode(content ...interface{}) *Node
+//   Dl(content ...interface{}) *Buffer
+//   DlNode(content ...interface{}) *Node
+//   Dt(content ...interface{}) *Buffer
+//   DtNode(content ...interface{}) *Node
+//   Figcaption(content ...interface{}) *Buffer
+//   FigcaptionNode(content ...interface{}) *Node
+//   Figure(content ...interface{}) *Buffer
+//   FigureNode(content ...interface{}) *Node
+//   Ol(content ...interface{}) *Buffer
+//   OlNode(content ...interface{}) *Node
+//   Pre(content ...interface{}) *Buffer
+//   PreNode(content ...interface{}) *Node
+//
+// # Text
+//   Code(content ...interface{}) *Buffer
+//   CodeNode(content ...interface{}) *Node
+//   Em(content ...interface{}) *Buffer
+//   EmNode(content ...interface{}) *Node
+//   Strong(content ...interface{}) *Buffer
+//   StrongNode(content ...interface{}) *Node
+//
+// # Tables
+//   Caption(content ...interface{}) *Buffer
+//   CaptionNode(content ...interface{}) *Node
+//   Col(attributes ...Attribute) *Buffer
+//   ColNode(attributes ...Attribute) *Node
+//   Colgroup(content ...interface{}) *Buffer
+//   ColgroupNode(content ...interface{}) *Node
+//   Table(content ...interface{}) *Buffer
+//   TableNode(content ...interface{}) *Node
+//   Tbody(content ...interface{}) *Buffer
+//   TbodyNode(content ...interface{}) *Node
+//   Td(content ...interface{}) *Buffer
+//   TdNode(content ...interface{}) *Node
+//   Tfoot(content ...interface{}) *Buffer
+//   TfootNode(content ...interface{}) *Node
+//   Th(content ...interface{}) *Buffer
+//   ThNode(content ...interface{}) *Node
+//   Thead(content ...interface{}) *Buffer
+//   TheadNode(content ...interface{}) *Node
+//   Tr(content ...interface{}) *Buffer
+//   TrNode(content ...interface{}) *Node
+//
+// # Forms
+//   Button(content ...interface{}) *Buffer
+//   ButtonNode(content ...interface{}) *Node
+//   Fieldset(content ...interface{}) *Buffer
+//   FieldsetNode(content ...interface{}) *Node
+//   Legend(content ...interface{}) *Buffer
+//   LegendNode(content ...interface{}) *Node
+//   Optgroup(content ...interface{}) *Buffer
+//   OptgroupNode(content ...interface{}) *Node
+//   Option(content ...interface{}) *Buffer
+//   OptionNode(content ...interface{}) *Node
+//   Select(content ...interface{}) *Buffer
+//   SelectNode(content ...interface{}) *Node
+//   Textarea(content ...interface{}) *Buffer
+//   TextareaNode(content ...interface{}) *Node
+//
+// # Embedded Content
+//   Audio(content ...interface{}) *Buffer
+//   AudioNode(content ...interface{}) *Node
+//   Picture(content ...interface{}) *Buffer
+//   PictureNode(content ...interface{}) *Node
+//   Source(attributes ...Attribute) *Buffer
+//   SourceNode(attributes ...Attribute) *Node
+//   Track(attributes ...Attribute) *Buffer
+//   TrackNode(attributes ...Attribute) *Node
+//   Video(content ...interface{}) *Buffer
+//   VideoNode(content ...interface{}) *Node
+//
+// # Interactive Elements
+//   Details(content ...interface{}) *Buffer
+//   DetailsNode(content ...interface{}) *Node
+//   Dialog(content ...interface{}) *Buffer
+//   DialogNode(content ...interface{}) *Node
+//   Summary(content ...interface{}) *Buffer
+//   SummaryNode(content ...interface{}) *Node
+//
+// # Metadata and Scripting
+//   Base(attributes ...Attribute) *Buffer
+//   BaseNode(attributes ...Attribute) *Node
+//   Link(attributes ...Attribute) *Buffer
+//   LinkNode(attributes ...Attribute) *Node
+//   Script(content ...interface{}) *Buffer
+//   ScriptNode(content ...interface{}) *Node
+//   Template(content ...interface{}) *Buffer
+//   TemplateNode(content ...interface{}) *Node
+
+// -----------------------------------------------------------------------------
+// # Sections
+
+// Aside tag defines content aside from the main content, e.g. a sidebar.
+func Aside(content ...interface{}) *Buffer {
+	return AsideNode(content...).Render()
+} //                                                                       Aside
+
+// AsideNode returns an <aside> element node. See Aside().
+func AsideNode(content ...interface{}) *Node {
+	return ContainerNode("aside", content...)
+} //                                                                   AsideNode
+
+// Footer tag defines the footer of a page or section.
+func Footer(content ...interface{}) *Buffer {
+	return FooterNode(content...).Render()
+} //                                                                      Footer
+
+// FooterNode returns a <footer> element node. See Footer().
+func FooterNode(content ...interface{}) *Node {
+	return ContainerNode("footer", content...)
+} //                                                                  FooterNode
+
+// Main tag defines the main content of a page.
+func Main(content ...interface{}) *Buffer {
+	return MainNode(content...).Render()
+} //                                                                        Main
+
+// MainNode returns a <main> element node. See Main().
+func MainNode(content ...interface{}) *Node {
+	return ContainerNode("main", content...)
+} //                                                                    MainNode
+
+// Section tag defines a standalone section of a document.
+func Section(content ...interface{}) *Buffer {
+	return SectionNode(content...).Render()
+} //                                                                     Section
+
+// SectionNode returns a <section> element node. See Section().
+func SectionNode(content ...interface{}) *Node {
+	return ContainerNode("section", content...)
+} //                                                                 SectionNode
+
+// -----------------------------------------------------------------------------
+// # Grouping
+
+// Dd tag defines the description of a term in a description list.
+func Dd(content ...interface{}) *Buffer {
+	return DdNode(content...).Render()
+} //                                                                          Dd
+
+// DdNode returns a <dd> element node. See Dd().
+func DdNode(content ...interface{}) *Node {
+	return ContainerNode("dd", content...)
+} //                                                                      DdNode
+
+// Dl tag defines a description list of terms (Dt) and descriptions.
+func Dl(content ...interface{}) *Buffer {
+	return DlNode(content...).Render()
+} //                                                                          Dl
+
+// DlNode returns a <dl> element node. See Dl().
+func DlNode(content ...interface{}) *Node {
+	return ContainerNode("dl", content...)
+} //                                                                      DlNode
+
+// Dt tag defines a term in a description list.
+func Dt(content ...interface{}) *Buffer {
+	return DtNode(content...).Render()
+} //                                                                          Dt
+
+// DtNode returns a <dt> element node. See Dt().
+func DtNode(content ...interface{}) *Node {
+	return ContainerNode("dt", content...)
+} //                                                                      DtNode
+
+// Figcaption tag defines the caption of a figure.
+func Figcaption(content ...interface{}) *Buffer {
+	return FigcaptionNode(content...).Render()
+} //                                                                  Figcaption
+
+// FigcaptionNode returns a <figcaption> element node. See Figcaption().
+func FigcaptionNode(content ...interface{}) *Node {
+	return ContainerNode("figcaption", content...)
+} //                                                              FigcaptionNode
+
+// Figure tag defines self-contained content, e.g. an image with a caption.
+func Figure(content ...interface{}) *Buffer {
+	return FigureNode(content...).Render()
+} //                                                                      Figure
+
+// FigureNode returns a <figure> element node. See Figure().
+func FigureNode(content ...interface{}) *Node {
+	return ContainerNode("figure", content...)
+} //                                                                  FigureNode
+
+// Ol tag defines an ordered list of <li> elements.
+func Ol(content ...interface{}) *Buffer {
+	return OlNode(content...).Render()
+} //                                                                          Ol
+
+// OlNode returns an <ol> element node. See Ol().
+func OlNode(content ...interface{}) *Node {
+	return ContainerNode("ol", content...)
+} //                                                                      OlNode
+
+// Pre tag defines preformatted text, which is shown as written.
+func Pre(content ...interface{}) *Buffer {
+	return PreNode(content...).Render()
+} //                                                                         Pre
+
+// PreNode returns a <pre> element node. See Pre().
+func PreNode(content ...interface{}) *Node {
+	return ContainerNode("pre", content...)
+} //                                                                     PreNode
+
+// -----------------------------------------------------------------------------
+// # Text
+
+// Code tag defines a fragment of computer code.
+func Code(content ...interface{}) *Buffer {
+	return CodeNode(content...).Render()
+} //                                                                        Code
+
+// CodeNode returns a <code> element node. See Code().
+func CodeNode(content ...interface{}) *Node {
+	return ContainerNode("code", content...)
+} //                                                                    CodeNode
+
+// Em tag defines emphasized text.
+func Em(content ...interface{}) *Buffer {
+	return EmNode(content...).Render()
+} //                                                                          Em
+
+// EmNode returns an <em> element node. See Em().
+func EmNode(content ...interface{}) *Node {
+	return ContainerNode("em", content...)
+} //                                                                      EmNode
+
+// Strong tag defines text of strong importance.
+func Strong(content ...interface{}) *Buffer {
+	return StrongNode(content...).Render()
+} //                                                                      Strong
+
+// StrongNode returns a <strong> element node. See Strong().
+func StrongNode(content ...interface{}) *Node {
+	return ContainerNode("strong", content...)
+} //                                                                  StrongNode
+
+// -----------------------------------------------------------------------------
+// # Tables
+
+// Caption tag defines the title of a table.
+func Caption(content ...interface{}) *Buffer {
+	return CaptionNode(content...).Render()
+} //                                                                     Caption
+
+// CaptionNode returns a <caption> element node. See Caption().
+func CaptionNode(content ...interface{}) *Node {
+	return ContainerNode("caption", content...)
+} //                                                                 CaptionNode
+
+// Col tag defines the properties of a table column in a Colgroup.
+// This tag has no closing tag and is not a container.
+func Col(attributes ...Attribute) *Buffer {
+	return ColNode(attributes...).Render()
+} //                                                                         Col
+
+// ColNode returns a <col> element node. See Col().
+func ColNode(attributes ...Attribute) *Node {
+	return ElementNode("col", attributes...)
+} //                                                                     ColNode
+
+// Colgroup tag defines a group of table columns.
+func Colgroup(content ...interface{}) *Buffer {
+	return ColgroupNode(content...).Render()
+} //                                                                    Colgroup
+
+// ColgroupNode returns a <colgroup> element node. See Colgroup().
+func ColgroupNode(content ...interface{}) *Node {
+	return ContainerNode("colgroup", content...)
+} //                                                                ColgroupNode
+
+// Table tag defines a table of rows (Tr) and cells (Td and Th).
+func Table(content ...interface{}) *Buffer {
+	return TableNode(content...).Render()
+} //                                                                       Table
+
+// TableNode returns a <table> element node. See Table().
+func TableNode(content ...interface{}) *Node {
+	return ContainerNode("table", content...)
+} //                                                                   TableNode
+
+// Tbody tag groups the body rows of a table.
+func Tbody(content ...interface{}) *Buffer {
+	return TbodyNode(content...).Render()
+} //                                                                       Tbody
+
+// TbodyNode returns a <tbody> element node. See Tbody().
+func TbodyNode(content ...interface{}) *Node {
+	return ContainerNode("tbody", content...)
+} //                                                                   TbodyNode
+
+// Td tag defines a data cell of a table row.
+func Td(content ...interface{}) *Buffer {
+	return TdNode(content...).Render()
+} //                                                                          Td
+
+// TdNode returns a <td> element node. See Td().
+func TdNode(content ...interface{}) *Node {
+	return ContainerNode("td", content...)
+} //                                                                      TdNode
+
+// Tfoot tag groups the footer rows of a table.
+func Tfoot(content ...interface{}) *Buffer {
+	return TfootNode(content...).Render()
+} //                                                                       Tfoot
+
+// TfootNode returns a <tfoot> element node. See Tfoot().
+func TfootNode(content ...interface{}) *Node {
+	return ContainerNode("tfoot", content...)
+} //                                                                   TfootNode
+
+// Th tag defines a header cell of a table row.
+func Th(content ...interface{}) *Buffer {
+	return ThNode(content...).Render()
+} //                                                                          Th
+
+// ThNode returns a <th> element node. See Th().
+func ThNode(content ...interface{}) *Node {
+	return ContainerNode("th", content...)
+} //                                                                      ThNode
+
+// Thead tag groups the header rows of a table.
+func Thead(content ...interface{}) *Buffer {
+	return TheadNode(content...).Render()
+} //                                                                       Thead
+
+// TheadNode returns a <thead> element node. See Thead().
+func TheadNode(content ...interface{}) *Node {
+	return ContainerNode("thead", content...)
+} //                                                                   TheadNode
+
+// Tr tag defines a row of table cells.
+func Tr(content ...interface{}) *Buffer {
+	return TrNode(content...).Render()
+} //                                                                          Tr
+
+// TrNode returns a <tr> element node. See Tr().
+func TrNode(content ...interface{}) *Node {
+	return ContainerNode("tr", content...)
+} //                                                                      TrNode
+
+// -----------------------------------------------------------------------------
+// # Forms
+
+// Button tag defines a clickable button.
+func Button(content ...interface{}) *Buffer {
+	return ButtonNode(content...).Render()
+} //                                                                      Button
+
+// ButtonNode returns a <button> element node. See Button().
+func ButtonNode(content ...interface{}) *Node {
+	return ContainerNode("button", content...)
+} //                                                                  ButtonNode
+
+// Fieldset tag groups related controls of a form, with a Legend.
+func Fieldset(content ...interface{}) *Buffer {
+	return FieldsetNode(content...).Render()
+} //                                                                    Fieldset
+
+// FieldsetNode returns a <fieldset> element node. See Fieldset().
+func FieldsetNode(content ...interface{}) *Node {
+	return ContainerNode("fieldset", content...)
+} //                                                                FieldsetNode
+
+// Legend tag defines the caption of a Fieldset.
+func Legend(content ...interface{}) *Buffer {
+	return LegendNode(content...).Render()
+} //                                                                      Legend
+
+// LegendNode returns a <legend> element node. See Legend().
+func LegendNode(content ...interface{}) *Node {
+	return ContainerNode("legend", content...)
+} //                                                                  LegendNode
+
+// Optgroup tag groups options of a Select under a label.
+func Optgroup(content ...interface{}) *Buffer {
+	return OptgroupNode(content...).Render()
+} //                                                                    Optgroup
+
+// OptgroupNode returns an <optgroup> element node. See Optgroup().
+func OptgroupNode(content ...interface{}) *Node {
+	return ContainerNode("optgroup", content...)
+} //                                                                OptgroupNode
+
+// Option tag defines an option of a Select.
+func Option(content ...interface{}) *Buffer {
+	return OptionNode(content...).Render()
+} //                                                                      Option
+
+// OptionNode returns an <option> element node. See Option().
+func OptionNode(content ...interface{}) *Node {
+	return ContainerNode("option", content...)
+} //                                                                  OptionNode
+
+// Select tag defines a drop-down list of options.
+func Select(content ...interface{}) *Buffer {
+	return SelectNode(content...).Render()
+} //                                                                      Select
+
+// SelectNode returns a <select> element node. See Select().
+func SelectNode(content ...interface{}) *Node {
+	return ContainerNode("select", content...)
+} //                                                                  SelectNode
+
+// Textarea tag defines a multi-line text input.
+func Textarea(content ...interface{}) *Buffer {
+	return TextareaNode(content...).Render()
+} //                                                                    Textarea
+
+// TextareaNode returns a <textarea> element node. See Textarea().
+func TextareaNode(content ...interface{}) *Node {
+	return ContainerNode("textarea", content...)
+} //                                                                TextareaNode
+
+// -----------------------------------------------------------------------------
+// # Embedded Content
+
+// Audio tag embeds sound content from Source elements or a 'src'.
+func Audio(content ...interface{}) *Buffer {
+	return AudioNode(content...).Render()
+} //                                                                       Audio
+
+// AudioNode returns an <audio> element node. See Audio().
+func AudioNode(content ...interface{}) *Node {
+	return ContainerNode("audio", content...)
+} //                                                                   AudioNode
+
+// Picture tag holds Source elements and an Img to choose an image from.
+func Picture(content ...interface{}) *Buffer {
+	return PictureNode(content...).Render()
+} //                                                                     Picture
+
+// PictureNode returns a <picture> element node. See Picture().
+func PictureNode(content ...interface{}) *Node {
+	return ContainerNode("picture", content...)
+} //                                                                 PictureNode
+
+// Source tag specifies a media resource of a Picture, Video or Audio.
+// This tag has no closing tag and is not a container.
+func Source(attributes ...Attribute) *Buffer {
+	return SourceNode(attributes...).Render()
+} //                                                                      Source
+
+// SourceNode returns a <source> element node. See Source().
+func SourceNode(attributes ...Attribute) *Node {
+	return ElementNode("source", attributes...)
+} //                                                                  SourceNode
+
+// Track tag specifies a text track (e.g. subtitles) of a Video or Audio.
+// This tag has no closing tag and is not a container.
+func Track(attributes ...Attribute) *Buffer {
+	return TrackNode(attributes...).Render()
+} //                                                                       Track
+
+// TrackNode returns a <track> element node. See Track().
+func TrackNode(attributes ...Attribute) *Node {
+	return ElementNode("track", attributes...)
+} //                                                                   TrackNode
+
+// Video tag embeds a video from Source elements or a 'src'.
+func Video(content ...interface{}) *Buffer {
+	return VideoNode(content...).Render()
+} //                                                                       Video
+
+// VideoNode returns a <video> element node. See Video().
+func VideoNode(content ...interface{}) *Node {
+	return ContainerNode("video", content...)
+} //                                                                   VideoNode
+
+// -----------------------------------------------------------------------------
+// # Interactive Elements
+
+// Details tag defines details that the user can open and close.
+func Details(content ...interface{}) *Buffer {
+	return DetailsNode(content...).Render()
+} //                                                                     Details
+
+// DetailsNode returns a <details> element node. See Details().
+func DetailsNode(content ...interface{}) *Node {
+	return ContainerNode("details", content...)
+} //                                                                 DetailsNode
+
+// Dialog tag defines a dialog box or window.
+func Dialog(content ...interface{}) *Buffer {
+	return DialogNode(content...).Render()
+} //                                                                      Dialog
+
+// DialogNode returns a <dialog> element node. See Dialog().
+func DialogNode(content ...interface{}) *Node {
+	return ContainerNode("dialog", content...)
+} //                                                                  DialogNode
+
+// Summary tag defines the visible heading of a Details element.
+func Summary(content ...interface{}) *Buffer {
+	return SummaryNode(content...).Render()
+} //                                                                     Summary
+
+// SummaryNode returns a <summary> element node. See Summary().
+func SummaryNode(content ...interface{}) *Node {
+	return ContainerNode("summary", content...)
+} //                                                                 SummaryNode
+
+// -----------------------------------------------------------------------------
+// # Metadata and Scripting
+
+// Base tag specifies the base URL of relative URLs in a page.
+// This tag has no closing tag and is not a container.
+func Base(attributes ...Attribute) *Buffer {
+	return BaseNode(attributes...).Render()
+} //                                                                        Base
+
+// BaseNode returns a <base> element node. See Base().
+func BaseNode(attributes ...Attribute) *Node {
+	return ElementNode("base", attributes...)
+} //                                                                    BaseNode
+
+// Link tag links a resource, e.g. a style sheet, to a page.
+// This tag has no closing tag and is not a container.
+func Link(attributes ...Attribute) *Buffer {
+	return LinkNode(attributes...).Render()
+} //                                                                        Link
+
+// LinkNode returns a <link> element node. See Link().
+func LinkNode(attributes ...Attribute) *Node {
+	return ElementNode("link", attributes...)
+} //                                                                    LinkNode
+
+// Script tag embeds or links a script. See also JS().
+func Script(content ...interface{}) *Buffer {
+	return ScriptNode(content...).Render()
+} //                                                                      Script
+
+// ScriptNode returns a <script> element node. See Script().
+func ScriptNode(content ...interface{}) *Node {
+	return ContainerNode("script", content...)
+} //                                                                  ScriptNode
+
+// Template tag holds content that is not shown, to be used by scripts.
+func Template(content ...interface{}) *Buffer {
+	return TemplateNode(content...).Render()
+} //                                                                    Template
+
+// TemplateNode returns a <template> element node. See Template().
+func TemplateNode(content ...interface{}) *Node {
+	return ContainerNode("template", content...)
+} //                                                                TemplateNode
+
+// end
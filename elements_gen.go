@@ -0,0 +1,313 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                             zr-web/[elements_gen.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+//go:build ignore
+// +build ignore
+
+// This program generates elements.go and elements_test.go from the
+// table of HTML5 elements below. To add an element, add it to the
+// table and run 'go generate' in the package's directory.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// elementSpec describes an element helper
+type elementSpec struct {
+	Name string // name of the helper function
+	Tag  string // name of the HTML element
+	Void bool   // element has no content or end tag
+	Doc  string // first line of the helper's doc comment
+} //                                                                 elementSpec
+
+// elementGroup is a group of helpers that gets its own section
+type elementGroup struct {
+	Section  string
+	Elements []elementSpec
+} //                                                                elementGroup
+
+// elementGroups lists the generated helpers
+var elementGroups = []elementGroup{
+	{"Sections", []elementSpec{
+		{"Aside", "aside", false,
+			"defines content aside from the main content, e.g. a sidebar."},
+		{"Footer", "footer", false,
+			"defines the footer of a page or section."},
+		{"Main", "main", false,
+			"defines the main content of a page."},
+		{"Section", "section", false,
+			"defines a standalone section of a document."},
+	}},
+	{"Grouping", []elementSpec{
+		{"Dd", "dd", false,
+			"defines the description of a term in a description list."},
+		{"Dl", "dl", false,
+			"defines a description list of terms (Dt) and descriptions."},
+		{"Dt", "dt", false,
+			"defines a term in a description list."},
+		{"Figcaption", "figcaption", false,
+			"defines the caption of a figure."},
+		{"Figure", "figure", false,
+			"defines self-contained content, e.g. an image with a caption."},
+		{"Ol", "ol", false,
+			"defines an ordered list of <li> elements."},
+		{"Pre", "pre", false,
+			"defines preformatted text, which is shown as written."},
+	}},
+	{"Text", []elementSpec{
+		{"Code", "code", false,
+			"defines a fragment of computer code."},
+		{"Em", "em", false,
+			"defines emphasized text."},
+		{"Strong", "strong", false,
+			"defines text of strong importance."},
+	}},
+	{"Tables", []elementSpec{
+		{"Caption", "caption", false,
+			"defines the title of a table."},
+		{"Col", "col", true,
+			"defines the properties of a table column in a Colgroup."},
+		{"Colgroup", "colgroup", false,
+			"defines a group of table columns."},
+		{"Table", "table", false,
+			"defines a table of rows (Tr) and cells (Td and Th)."},
+		{"Tbody", "tbody", false,
+			"groups the body rows of a table."},
+		{"Td", "td", false,
+			"defines a data cell of a table row."},
+		{"Tfoot", "tfoot", false,
+			"groups the footer rows of a table."},
+		{"Th", "th", false,
+			"defines a header cell of a table row."},
+		{"Thead", "thead", false,
+			"groups the header rows of a table."},
+		{"Tr", "tr", false,
+			"defines a row of table cells."},
+	}},
+	{"Forms", []elementSpec{
+		{"Button", "button", false,
+			"defines a clickable button."},
+		{"Fieldset", "fieldset", false,
+			"groups related controls of a form, with a Legend."},
+		{"Legend", "legend", false,
+			"defines the caption of a Fieldset."},
+		{"Optgroup", "optgroup", false,
+			"groups options of a Select under a label."},
+		{"Option", "option", false,
+			"defines an option of a Select."},
+		{"Select", "select", false,
+			"defines a drop-down list of options."},
+		{"Textarea", "textarea", false,
+			"defines a multi-line text input."},
+	}},
+	{"Embedded Content", []elementSpec{
+		{"Audio", "audio", false,
+			"embeds sound content from Source elements or a 'src'."},
+		{"Picture", "picture", false,
+			"holds Source elements and an Img to choose an image from."},
+		{"Source", "source", true,
+			"specifies a media resource of a Picture, Video or Audio."},
+		{"Track", "track", true,
+			"specifies a text track (e.g. subtitles) of a Video or Audio."},
+		{"Video", "video", false,
+			"embeds a video from Source elements or a 'src'."},
+	}},
+	{"Interactive Elements", []elementSpec{
+		{"Details", "details", false,
+			"defines details that the user can open and close."},
+		{"Dialog", "dialog", false,
+			"defines a dialog box or window."},
+		{"Summary", "summary", false,
+			"defines the visible heading of a Details element."},
+	}},
+	{"Metadata and Scripting", []elementSpec{
+		{"Base", "base", true,
+			"specifies the base URL of relative URLs in a page."},
+		{"Link", "link", true,
+			"links a resource, e.g. a style sheet, to a page."},
+		{"Script", "script", false,
+			"embeds or links a script. See also JS()."},
+		{"Template", "template", false,
+			"holds content that is not shown, to be used by scripts."},
+	}},
+}
+
+func main() {
+	write("elements.go", genElements())
+	write("elements_test.go", genTests())
+} //                                                                        main
+
+// genElements returns the source of elements.go
+func genElements() []byte {
+	var buf bytes.Buffer
+	pr := func(a ...interface{}) { fmt.Fprint(&buf, a...) }
+	pr(header("elements.go"),
+		"// Code generated by elements_gen.go. DO NOT EDIT.\n\n",
+		"package web\n\n")
+	for i, group := range elementGroups {
+		if i > 0 {
+			pr("//\n")
+		}
+		pr("// # ", group.Section, "\n")
+		for _, el := range group.Elements {
+			params := "content ...interface{}"
+			if el.Void {
+				params = "attributes ...Attribute"
+			}
+			pr("//   ", el.Name, "(", params, ") *Buffer\n")
+			pr("//   ", el.Name, "Node(", params, ") *Node\n")
+		}
+	}
+	for _, group := range elementGroups {
+		pr("\n// ", strings.Repeat("-", 77), "\n// # ", group.Section, "\n")
+		for _, el := range group.Elements {
+			name, nodeName := el.Name, el.Name+"Node"
+			params, args, ctor := "content ...interface{}", "content...",
+				"ContainerNode"
+			note := ""
+			if el.Void {
+				params, args, ctor = "attributes ...Attribute",
+					"attributes...", "ElementNode"
+				note = "\n// This tag has no closing tag" +
+					" and is not a container."
+			}
+			pr("\n// ", name, " tag ", el.Doc, note, "\n",
+				"func ", name, "(", params, ") *Buffer {\n",
+				"\treturn ", nodeName, "(", args, ").Render()\n",
+				closing(name), "\n")
+			article := "a"
+			if strings.ContainsAny(el.Tag[:1], "aeiou") {
+				article = "an"
+			}
+			pr("\n// ", nodeName, " returns ", article, " <", el.Tag,
+				"> element node. See ", name, "().\n",
+				"func ", nodeName, "(", params, ") *Node {\n",
+				"\treturn ", ctor, "(\"", el.Tag, "\", ", args, ")\n",
+				closing(nodeName), "\n")
+		}
+	}
+	pr("\n// end\n")
+	return buf.Bytes()
+} //                                                                 genElements
+
+// genTests returns the source of elements_test.go
+func genTests() []byte {
+	var buf bytes.Buffer
+	pr := func(a ...interface{}) { fmt.Fprint(&buf, a...) }
+	pr(header("elements_test.go"),
+		"// Code generated by elements_gen.go. DO NOT EDIT.\n\n",
+		"package web\n\n",
+		"// # Elements\n",
+		"//   Test_elem_containers_\n",
+		"//   Test_elem_voidElements_\n\n",
+		"//  to test all items in elements.go use:\n",
+		"//      go test --run Test_elem_\n",
+		"//\n",
+		"//  to generate a test coverage report for the whole module use:\n",
+		"//      go test -coverprofile cover.out\n",
+		"//      go tool cover -html=cover.out\n\n",
+		"import (\n\t\"testing\"\n\n\t\"github.com/balacode/zr\"\n)\n\n",
+		"// ", strings.Repeat("-", 77), "\n// # Elements\n")
+	//
+	pr("\n// go test --run Test_elem_containers_\n",
+		"func Test_elem_containers_(t *testing.T) {\n",
+		"\tzr.TBegin(t)\n",
+		"\t// Name(content ...interface{}) *Buffer\n",
+		"\t// NameNode(content ...interface{}) *Node\n",
+		"\t//\n",
+		"\tfor _, it := range []struct {\n",
+		"\t\ttag  string\n",
+		"\t\tfn   func(...interface{}) *Buffer\n",
+		"\t\tnode func(...interface{}) *Node\n",
+		"\t}{\n")
+	for _, group := range elementGroups {
+		for _, el := range group.Elements {
+			if !el.Void {
+				pr("\t\t{\"", el.Tag, "\", ", el.Name, ", ",
+					el.Name, "Node},\n")
+			}
+		}
+	}
+	pr("\t} {\n",
+		"\t\texpect := Container(it.tag, Class(\"c\"), \"x\").String()\n",
+		"\t\tzr.TEqual(t, it.fn(Class(\"c\"), \"x\").String(), expect)\n",
+		"\t\tzr.TEqual(t, it.node(Class(\"c\"), \"x\").String(), expect)\n",
+		"\t\tzr.TEqual(t, it.node().Tag(), it.tag)\n",
+		"\t\tzr.TTrue(t, !IsVoidElement(it.tag))\n",
+		"\t}\n",
+		closing("Test_elem_containers_"), "\n")
+	//
+	pr("\n// go test --run Test_elem_voidElements_\n",
+		"func Test_elem_voidElements_(t *testing.T) {\n",
+		"\tzr.TBegin(t)\n",
+		"\t// Name(attributes ...Attribute) *Buffer\n",
+		"\t// NameNode(attributes ...Attribute) *Node\n",
+		"\t//\n",
+		"\tfor _, it := range []struct {\n",
+		"\t\ttag  string\n",
+		"\t\tfn   func(...Attribute) *Buffer\n",
+		"\t\tnode func(...Attribute) *Node\n",
+		"\t}{\n")
+	for _, group := range elementGroups {
+		for _, el := range group.Elements {
+			if el.Void {
+				pr("\t\t{\"", el.Tag, "\", ", el.Name, ", ",
+					el.Name, "Node},\n")
+			}
+		}
+	}
+	pr("\t} {\n",
+		"\t\texpect := \"<\" + it.tag + ` class=\"c\">` + \"\\r\\n\"\n",
+		"\t\tzr.TEqual(t, it.fn(Class(\"c\")).String(), expect)\n",
+		"\t\tzr.TEqual(t, it.node(Class(\"c\")).String(), expect)\n",
+		"\t\tzr.TEqual(t, it.node().Tag(), it.tag)\n",
+		"\t\tzr.TTrue(t, IsVoidElement(it.tag))\n",
+		"\t}\n",
+		closing("Test_elem_voidElements_"), "\n",
+		"\n// end\n")
+	return buf.Bytes()
+} //                                                                    genTests
+
+// closing returns the closing line of function 'name', with
+// the name right-aligned to column 80 as in the other files.
+func closing(name string) string {
+	return "} //" + strings.Repeat(" ", 80-4-len(name)) + name
+} //                                                                     closing
+
+// header returns the comment box at the top of file 'filename'.
+func header(filename string) string {
+	line := "// " + strings.Repeat("-", 77) + "\n"
+	title := "// ZR Library - Web Package"
+	tag := "zr-web/[" + filename + "]"
+	author := "// (c) balarabe@protonmail.com"
+	license := "License: MIT"
+	return line +
+		title + strings.Repeat(" ", 80-len(title)-len(tag)) + tag + "\n" +
+		author + strings.Repeat(" ", 80-len(author)-len(license)) +
+		license + "\n" +
+		line + "\n"
+} //                                                                      header
+
+// write formats 'src' and writes it to file 'filename'.
+func write(filename string, src []byte) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, filename+":", err)
+		os.Exit(1)
+	}
+	err = ioutil.WriteFile(filename, formatted, 0644)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+} //                                                                       write
+
+// end
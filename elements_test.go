@@ -0,0 +1,113 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                            zr-web/[elements_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+// Code generated by elements_gen.go. DO NOT EDIT.
+
+package web
+
+// # Elements
+//   Test_elem_containers_
+//   Test_elem_voidElements_
+
+//  to test all items in elements.go use:
+//      go test --run Test_elem_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Elements
+
+// go test --run Test_elem_containers_
+func Test_elem_containers_(t *testing.T) {
+	zr.TBegin(t)
+	// Name(content ...interface{}) *Buffer
+	// NameNode(content ...interface{}) *Node
+	//
+	for _, it := range []struct {
+		tag  string
+		fn   func(...interface{}) *Buffer
+		node func(...interface{}) *Node
+	}{
+		{"aside", Aside, AsideNode},
+		{"footer", Footer, FooterNode},
+		{"main", Main, MainNode},
+		{"section", Section, SectionNode},
+		{"dd", Dd, DdNode},
+		{"dl", Dl, DlNode},
+		{"dt", Dt, DtNode},
+		{"figcaption", Figcaption, FigcaptionNode},
+		{"figure", Figure, FigureNode},
+		{"ol", Ol, OlNode},
+		{"pre", Pre, PreNode},
+		{"code", Code, CodeNode},
+		{"em", Em, EmNode},
+		{"strong", Strong, StrongNode},
+		{"caption", Caption, CaptionNode},
+		{"colgroup", Colgroup, ColgroupNode},
+		{"table", Table, TableNode},
+		{"tbody", Tbody, TbodyNode},
+		{"td", Td, TdNode},
+		{"tfoot", Tfoot, TfootNode},
+		{"th", Th, ThNode},
+		{"thead", Thead, TheadNode},
+		{"tr", Tr, TrNode},
+		{"button", Button, ButtonNode},
+		{"fieldset", Fieldset, FieldsetNode},
+		{"legend", Legend, LegendNode},
+		{"optgroup", Optgroup, OptgroupNode},
+		{"option", Option, OptionNode},
+		{"select", Select, SelectNode},
+		{"textarea", Textarea, TextareaNode},
+		{"audio", Audio, AudioNode},
+		{"picture", Picture, PictureNode},
+		{"video", Video, VideoNode},
+		{"details", Details, DetailsNode},
+		{"dialog", Dialog, DialogNode},
+		{"summary", Summary, SummaryNode},
+		{"script", Script, ScriptNode},
+		{"template", Template, TemplateNode},
+	} {
+		expect := Container(it.tag, Class("c"), "x").String()
+		zr.TEqual(t, it.fn(Class("c"), "x").String(), expect)
+		zr.TEqual(t, it.node(Class("c"), "x").String(), expect)
+		zr.TEqual(t, it.node().Tag(), it.tag)
+		zr.TTrue(t, !IsVoidElement(it.tag))
+	}
+} //                                                       Test_elem_containers_
+
+// go test --run Test_elem_voidElements_
+func Test_elem_voidElements_(t *testing.T) {
+	zr.TBegin(t)
+	// Name(attributes ...Attribute) *Buffer
+	// NameNode(attributes ...Attribute) *Node
+	//
+	for _, it := range []struct {
+		tag  string
+		fn   func(...Attribute) *Buffer
+		node func(...Attribute) *Node
+	}{
+		{"col", Col, ColNode},
+		{"source", Source, SourceNode},
+		{"track", Track, TrackNode},
+		{"base", Base, BaseNode},
+		{"link", Link, LinkNode},
+	} {
+		expect := "<" + it.tag + ` class="c">` + "\r\n"
+		zr.TEqual(t, it.fn(Class("c")).String(), expect)
+		zr.TEqual(t, it.node(Class("c")).String(), expect)
+		zr.TEqual(t, it.node().Tag(), it.tag)
+		zr.TTrue(t, IsVoidElement(it.tag))
+	}
+} //                                                     Test_elem_voidElements_
+
+// end
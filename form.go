@@ -0,0 +1,606 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                     zr-web/[form.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	StructForm renders an HTML form for editing a struct, binds POSTed
+//	values back into it and shows validation errors next to the inputs.
+//	Each exported field becomes a labeled input, described by its 'web'
+//	tag, which holds options separated by semicolons:
+//
+//	type Signup struct {
+//		Name  string `web:"label=Full name; required; max=50"`
+//		Email string `web:"type=email; required"`
+//		Age   int    `web:"min=18; max=130"`
+//		Plan  string `web:"options=free:Free|pro:Professional"`
+//		Terms bool   `web:"label=I accept the terms; required"`
+//		Notes string `web:"type=textarea"`
+//		Token string `web:"-"`
+//	}
+//
+//	func signupPage(ctx *web.Context) {
+//		var signup Signup
+//		form := web.NewStructForm(&signup)
+//		if ctx.Method() == "POST" && form.BindPost(ctx) {
+//			// save 'signup', then redirect
+//		}
+//		ctx.Reply(form.Form(web.Attr("action", "/signup"),
+//			web.Button(web.Type("submit"), "Sign up")), "html")
+//	}
+//
+//	The tag options are:
+//	  label=text      the label of the input, instead of the field's name
+//	  name=name       the name of the input, instead of the field's name
+//	  type=type       the input's type, e.g. email, password, hidden,
+//	                  or textarea. By default: text, number or checkbox
+//	  required        the field must have a value (bools must be true)
+//	  min=n, max=n    the lowest and highest number, or the shortest and
+//	                  longest text, in characters
+//	  pattern=regex   a regular expression that text must match fully
+//	  options=a|b     the allowed values, shown in a <select>. A value
+//	                  can have a label after a colon, e.g. 'eur:Euro'
+//
+//	As in 'validate' tags, a semicolon in an option is written after a
+//	backslash, e.g. `web:"pattern=[^\\;]+"`.
+//
+//	A tag of "-" leaves out the field. Fields of types other than
+//	strings, bools, integers and floats are left out. Fields can also
+//	have a 'validate' tag with other rules, e.g. `validate:"email"`,
+//	which are checked after those in the 'web' tag (see validate.go).
+//	Error messages are localized with SetLocale().
+
+//  StructForm struct
+//
+// # Constructor
+//   NewStructForm(model interface{}) *StructForm
+//
+// # Methods (ob *StructForm)
+//   ) Bind(values url.Values) bool
+//   ) BindPost(ctx *Context) bool
+//   ) Errors() map[string]string
+//   ) FieldError(name string) string
+//   ) Form(content ...interface{}) *Buffer
+//   ) FormNode(content ...interface{}) *Node
+//   ) Result() *ValidationResult
+//   ) SetError(name, message string)
+//   ) SetLocale(locale string)
+//   ) Validate() bool
+//
+// # Support (File Scope)
+//   formField struct
+//   (ob *StructForm) fieldNode(field *formField) *Node
+//   (ob *StructForm) inputValue(field *formField) string
+//   (ob *StructForm) limitAttrs(field *formField) Attributes
+//   (ob *StructForm) setError(field *formField, key string, args ...string)
+//   (ob *StructForm) validateField(field *formField)
+//   parseFormField(sf reflect.StructField, index int) (*formField, bool)
+//   formatFormNumber(n float64) string
+//   formatFormValue(value reflect.Value) string
+//   isTrimmedFormInput(kind string) bool
+//   setFormValue(value reflect.Value, s string) bool
+
+import (
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/balacode/zr"
+)
+
+// StructForm is an HTML form for editing a struct. See NewStructForm().
+type StructForm struct {
+	model  reflect.Value // the struct being edited
+	fields []*formField
+	raw    map[string]string          // values as submitted, by input name
+	errors map[string]ValidationError // by input name
+	locale string                     // locale of error messages
+} //                                                                  StructForm
+
+// formField describes an input of a StructForm
+type formField struct {
+	index       int // index of the field in the struct
+	name        string
+	label       string
+	kind        string // the input's type, or "select" or "textarea"
+	required    bool
+	min, max    float64
+	hasMin      bool
+	hasMax      bool
+	pattern     *regexp.Regexp
+	patternText string      // the pattern as given in the tag
+	options     [][2]string // pairs of values and labels
+	rules       string      // the field's 'validate' tag
+} //                                                                   formField
+
+// -----------------------------------------------------------------------------
+// # Constructor
+
+// NewStructForm creates a form for editing the struct that 'model'
+// points to. The inputs show the struct's current values. Errors in
+// 'web' tags are reported with zr.Error() and those fields left out.
+func NewStructForm(model interface{}) *StructForm {
+	ret := &StructForm{
+		raw:    map[string]string{},
+		errors: map[string]ValidationError{},
+	}
+	val := reflect.ValueOf(model)
+	if val.Kind() != reflect.Ptr || val.IsNil() ||
+		val.Elem().Kind() != reflect.Struct {
+		zr.Error("NewStructForm() needs a pointer to a struct, got",
+			reflect.TypeOf(model))
+		return ret
+	}
+	ret.model = val.Elem()
+	typ := ret.model.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field, ok := parseFormField(typ.Field(i), i)
+		if ok {
+			ret.fields = append(ret.fields, field)
+		}
+	}
+	return ret
+} //                                                               NewStructForm
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *StructForm)
+
+// Bind sets the struct's fields from the submitted form 'values',
+// then validates them. Returns true if all the values are valid.
+// Values that can't be stored in their fields, e.g. "abc" in an
+// int, leave the field unchanged and give an error. Spaces around
+// the values of text-like inputs are removed, but passwords, text
+// areas and hidden inputs are kept as submitted.
+func (ob *StructForm) Bind(values url.Values) bool {
+	ob.raw = map[string]string{}
+	ob.errors = map[string]ValidationError{}
+	for _, field := range ob.fields {
+		s := values.Get(field.name)
+		if isTrimmedFormInput(field.kind) {
+			s = strings.TrimSpace(s)
+		}
+		ob.raw[field.name] = s
+		value := ob.model.Field(field.index)
+		if value.Kind() == reflect.Bool {
+			// unchecked checkboxes are not submitted
+			s = strconv.FormatBool(s != "")
+		}
+		if !setFormValue(value, s) {
+			ob.setError(field, "number")
+		}
+	}
+	return ob.Validate()
+} //                                                                        Bind
+
+// BindPost binds the values POSTed in the body of the request in
+// 'ctx', which must be URL-encoded (the default for forms). See Bind().
+// A malformed body is not valid: it gives an error that is not about
+// an input, which Form() shows above the inputs.
+func (ob *StructForm) BindPost(ctx *Context) bool {
+	values, err := url.ParseQuery(string(ctx.PostData()))
+	ok := ob.Bind(values)
+	if err != nil {
+		ob.errors[""] = ValidationError{
+			Rule:    "form",
+			Message: ValidationMessage(ob.locale, "form", ""),
+		}
+		return false
+	}
+	return ok
+} //                                                                    BindPost
+
+// Errors returns a copy of the error messages, by input name.
+func (ob *StructForm) Errors() map[string]string {
+	ret := make(map[string]string, len(ob.errors))
+	for name, it := range ob.errors {
+		ret[name] = it.Message
+	}
+	return ret
+} //                                                                      Errors
+
+// FieldError returns the error message of input 'name',
+// or a blank string if its value is valid.
+func (ob *StructForm) FieldError(name string) string {
+	return ob.errors[name].Message
+} //                                                                  FieldError
+
+// Form composes a <form> with the inputs, which is POSTed to the same
+// URL. 'content' is added after the inputs: add a submit button, and
+// attributes for the <form>, e.g. Attr("action", "/save").
+func (ob *StructForm) Form(content ...interface{}) *Buffer {
+	return ob.FormNode(content...).Render()
+} //                                                                        Form
+
+// FormNode returns the form as a node. See Form().
+//
+// Each input is in a <div class="field">, after its <label>, except
+// for checkboxes, which are inside their label. Inputs with errors
+// get an 'error' class and the message is in a following
+// <span class="error">. An error that is not about an input, e.g.
+// from BindPost(), is in a <p class="error"> before the inputs.
+func (ob *StructForm) FormNode(content ...interface{}) *Node {
+	ret := FormNode(Attr("method", "post"))
+	if msg := ob.errors[""].Message; msg != "" {
+		ret.AppendChild(PNode(Class("error"), TextNode(msg)))
+	}
+	for _, field := range ob.fields {
+		ret.AppendChild(ob.fieldNode(field))
+	}
+	ret.addContent(content)
+	return ret
+} //                                                                    FormNode
+
+// Result returns the errors as a ValidationResult, in the order of
+// the inputs after any error that is not about an input, e.g. to
+// send them to a script with Context.ReplyErrors().
+func (ob *StructForm) Result() *ValidationResult {
+	ret := &ValidationResult{}
+	if it, ok := ob.errors[""]; ok {
+		ret.Errors = append(ret.Errors, it)
+	}
+	for _, field := range ob.fields {
+		if it, ok := ob.errors[field.name]; ok {
+			ret.Errors = append(ret.Errors, it)
+		}
+	}
+	return ret
+} //                                                                      Result
+
+// SetError sets the error message of input 'name', e.g. for checks
+// that need a database. A blank 'message' clears the error.
+func (ob *StructForm) SetError(name, message string) {
+	if message == "" {
+		delete(ob.errors, name)
+		return
+	}
+	ob.errors[name] = ValidationError{Field: name, Message: message}
+} //                                                                    SetError
+
+// SetLocale sets the locale of error messages, e.g. "de". By default,
+// they are in DefaultValidationLocale. See ValidationMessage().
+func (ob *StructForm) SetLocale(locale string) {
+	ob.locale = locale
+} //                                                                   SetLocale
+
+// Validate checks the values of the struct's fields against their
+// 'web' tags, then their 'validate' tags (see ValidateStruct).
+// Returns true if they are valid, or false and sets the errors.
+// Errors of values that could not be bound are kept.
+func (ob *StructForm) Validate() bool {
+	for _, field := range ob.fields {
+		if _, failed := ob.errors[field.name]; !failed {
+			ob.validateField(field)
+		}
+		if _, failed := ob.errors[field.name]; !failed && field.rules != "" {
+			var result ValidationResult
+			validateRules(&result, field.name, field.label,
+				ob.model.Field(field.index), ob.model, field.rules,
+				ob.locale)
+			if !result.OK() {
+				ob.errors[field.name] = result.Errors[0]
+			}
+		}
+	}
+	return len(ob.errors) == 0
+} //                                                                    Validate
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// fieldNode returns the label, input and error message of 'field'.
+func (ob *StructForm) fieldNode(field *formField) *Node {
+	id := "field-" + field.name
+	value := ob.inputValue(field)
+	msg := ob.errors[field.name].Message
+	attrs := Attributes{ID(id), Name(field.name)}
+	if msg != "" {
+		attrs.Merge(Class("error"), Aria("invalid", "true"))
+	}
+	if field.required {
+		attrs.Merge(BoolAttr("required", true))
+	}
+	var input *Node
+	switch field.kind {
+	case "hidden":
+		return InputNode(Type("hidden"), Name(field.name), Value(value))
+	case "checkbox":
+		attrs.Merge(Type("checkbox"), Value("true"),
+			BoolAttr("checked", value == "true"))
+		input = LabelNode(InputNode(attrs...), TextNode(field.label))
+	case "select":
+		input = SelectNode(attrs)
+		if !field.required {
+			input.AppendChild(OptionNode(Value(""), ""))
+		}
+		for _, opt := range field.options {
+			input.AppendChild(OptionNode(Value(opt[0]),
+				BoolAttr("selected", opt[0] == value), TextNode(opt[1])))
+		}
+	case "textarea":
+		attrs.Merge(ob.limitAttrs(field)...)
+		input = TextareaNode(attrs, TextNode(value))
+	default:
+		attrs.Merge(Type(field.kind))
+		if field.kind != "password" {
+			attrs.Merge(Value(value))
+		}
+		attrs.Merge(ob.limitAttrs(field)...)
+		input = InputNode(attrs...)
+	}
+	ret := DivNode(Class("field"))
+	if field.kind != "checkbox" {
+		ret.AppendChild(LabelNode(For(id), TextNode(field.label)))
+	}
+	ret.AppendChild(input)
+	if msg != "" {
+		ret.AddClass("error")
+		ret.AppendChild(SpanNode(Class("error"), TextNode(msg)))
+	}
+	return ret
+} //                                                                   fieldNode
+
+// inputValue returns the value shown in the input of 'field': the
+// submitted text after Bind(), otherwise the value of the field.
+func (ob *StructForm) inputValue(field *formField) string {
+	if s, ok := ob.raw[field.name]; ok {
+		if field.kind == "checkbox" {
+			return strconv.FormatBool(s != "")
+		}
+		return s
+	}
+	return formatFormValue(ob.model.Field(field.index))
+} //                                                                  inputValue
+
+// limitAttrs returns the attributes that let browsers check
+// the value of 'field' before the form is submitted.
+func (ob *StructForm) limitAttrs(field *formField) Attributes {
+	var ret Attributes
+	minName, maxName := "min", "max"
+	if ob.model.Field(field.index).Kind() == reflect.String {
+		minName, maxName = "minlength", "maxlength"
+	}
+	if field.hasMin {
+		ret = append(ret, Attr(minName, formatFormNumber(field.min)))
+	}
+	if field.hasMax {
+		ret = append(ret, Attr(maxName, formatFormNumber(field.max)))
+	}
+	if field.pattern != nil && field.kind != "textarea" {
+		ret = append(ret, Pattern(field.patternText))
+	}
+	return ret
+} //                                                                  limitAttrs
+
+// setError sets the error of 'field' to the
+// message with 'key' and 'args' in the form's locale.
+func (ob *StructForm) setError(field *formField, key string, args ...string) {
+	ob.errors[field.name] = ValidationError{
+		Field:   field.name,
+		Rule:    key,
+		Message: ValidationMessage(ob.locale, key, field.label, args...),
+	}
+} //                                                                    setError
+
+// validateField checks the value of 'field' and sets its error.
+func (ob *StructForm) validateField(field *formField) {
+	value := ob.model.Field(field.index)
+	fail := func(key string, args ...string) {
+		ob.setError(field, key, args...)
+	}
+	var num float64
+	switch value.Kind() {
+	case reflect.Bool:
+		if field.required && !value.Bool() {
+			fail("required")
+		}
+		return
+	case reflect.String:
+		s := value.String()
+		if strings.TrimSpace(s) == "" {
+			if field.required {
+				fail("required")
+			}
+			return
+		}
+		if field.pattern != nil && !field.pattern.MatchString(s) {
+			fail("regex")
+			return
+		}
+		n := float64(utf8.RuneCountInString(s))
+		switch {
+		case field.hasMin && n < field.min:
+			fail("length.min", formatFormNumber(field.min))
+			return
+		case field.hasMax && n > field.max:
+			fail("length.max", formatFormNumber(field.max))
+			return
+		}
+	case reflect.Float32, reflect.Float64:
+		num = value.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		num = float64(value.Int())
+	default:
+		num = float64(value.Uint())
+	}
+	if value.Kind() != reflect.String {
+		// a number is only missing if it was submitted blank
+		if s, bound := ob.raw[field.name]; bound && s == "" {
+			if field.required {
+				fail("required")
+			}
+			return
+		}
+		switch {
+		case field.hasMin && num < field.min:
+			fail("range.min", formatFormNumber(field.min))
+			return
+		case field.hasMax && num > field.max:
+			fail("range.max", formatFormNumber(field.max))
+			return
+		}
+	}
+	if len(field.options) > 0 {
+		s := formatFormValue(value)
+		for _, opt := range field.options {
+			if opt[0] == s {
+				return
+			}
+		}
+		fail("oneof")
+	}
+} //                                                               validateField
+
+// parseFormField reads the 'web' tag of struct field 'sf'.
+// Returns false if the field is not part of the form.
+func parseFormField(sf reflect.StructField, index int) (*formField, bool) {
+	tag, tagged := sf.Tag.Lookup("web")
+	if sf.PkgPath != "" || sf.Anonymous || tag == "-" {
+		return nil, false
+	}
+	ret := &formField{
+		index: index,
+		name:  sf.Name,
+		label: sf.Name,
+		rules: sf.Tag.Get("validate"),
+	}
+	switch sf.Type.Kind() {
+	case reflect.String:
+		ret.kind = "text"
+	case reflect.Bool:
+		ret.kind = "checkbox"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		ret.kind = "number"
+	default:
+		if tagged {
+			zr.Error("Field", sf.Name, "of type", sf.Type,
+				"can't be used in a form")
+		}
+		return nil, false
+	}
+	for _, opt := range splitTagOptions(tag) {
+		key, val := strings.TrimSpace(opt), ""
+		if i := strings.IndexByte(key, '='); i != -1 {
+			key, val = strings.TrimSpace(key[:i]), strings.TrimSpace(key[i+1:])
+		}
+		var err error
+		switch key {
+		case "":
+		case "label":
+			ret.label = val
+		case "name":
+			ret.name = val
+		case "type":
+			ret.kind = val
+		case "required":
+			ret.required = true
+		case "min":
+			ret.min, err = strconv.ParseFloat(val, 64)
+			ret.hasMin = true
+		case "max":
+			ret.max, err = strconv.ParseFloat(val, 64)
+			ret.hasMax = true
+		case "pattern":
+			ret.pattern, err = regexp.Compile("^(?:" + val + ")$")
+			ret.patternText = val
+		case "options":
+			for _, it := range strings.Split(val, "|") {
+				value, label := it, it
+				if i := strings.IndexByte(it, ':'); i != -1 {
+					value, label = it[:i], it[i+1:]
+				}
+				ret.options = append(ret.options, [2]string{value, label})
+			}
+			ret.kind = "select"
+		default:
+			zr.Error("Unknown option", key, "in 'web' tag of field", sf.Name)
+			return nil, false
+		}
+		if err != nil {
+			zr.Error("Invalid 'web' tag of field", sf.Name, ":", err)
+			return nil, false
+		}
+	}
+	return ret, true
+} //                                                              parseFormField
+
+// formatFormNumber formats number 'n' without needless decimals.
+func formatFormNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+} //                                                            formatFormNumber
+
+// formatFormValue returns the text shown in an input for 'value'.
+func formatFormValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Float32:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return formatFormNumber(value.Float())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	}
+	return strconv.FormatUint(value.Uint(), 10)
+} //                                                             formatFormValue
+
+// isTrimmedFormInput returns true if Bind() removes
+// spaces around the values of inputs of type 'kind'.
+func isTrimmedFormInput(kind string) bool {
+	switch kind {
+	case "email", "number", "search", "select", "tel", "text", "url":
+		return true
+	}
+	return false
+} //                                                          isTrimmedFormInput
+
+// setFormValue sets 'value' from submitted text 's'. Blank text sets
+// a zero value. Returns false if 's' doesn't fit the value's type.
+func setFormValue(value reflect.Value, s string) bool {
+	if s == "" && value.Kind() != reflect.String {
+		value.Set(reflect.Zero(value.Type()))
+		return true
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return false
+		}
+		value.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, value.Type().Bits())
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return false
+		}
+		value.SetFloat(n)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, value.Type().Bits())
+		if err != nil {
+			return false
+		}
+		value.SetInt(n)
+	default:
+		n, err := strconv.ParseUint(s, 10, value.Type().Bits())
+		if err != nil {
+			return false
+		}
+		value.SetUint(n)
+	}
+	return true
+} //                                                                setFormValue
+
+// end
@@ -0,0 +1,342 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                zr-web/[form_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Constructor
+//   Test_form_NewStructForm_
+//
+// # Methods (ob *StructForm)
+//   Test_form_StructForm_Bind_
+//   Test_form_StructForm_BindPost_
+//   Test_form_StructForm_Form_
+//   Test_form_StructForm_Result_
+//   Test_form_StructForm_SetError_
+//   Test_form_StructForm_SetLocale_
+
+//  to test all items in form.go use:
+//      go test --run Test_form_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// formTestSignup is the struct edited by the tests
+type formTestSignup struct {
+	Name   string  `web:"label=Full name; required; min=2; max=10"`
+	Email  string  `web:"name=email; type=email; required"`
+	Age    int     `web:"min=18; max=130"`
+	Rating float64 `web:"max=5"`
+	Plan   string  `web:"options=free:Free|pro:Professional"`
+	Code   string  `web:"pattern=[a-z]{2,3}"`
+	Terms  bool    `web:"label=I accept; required"`
+	Notes  string  `web:"type=textarea"`
+	Token  string  `web:"-"`
+	ID     uint    `web:"type=hidden"`
+	Tags   []string
+	secret string
+} //                                                              formTestSignup
+
+// -----------------------------------------------------------------------------
+// # Constructor
+
+// go test --run Test_form_NewStructForm_
+func Test_form_NewStructForm_(t *testing.T) {
+	zr.TBegin(t)
+	// NewStructForm(model interface{}) *StructForm
+	//
+	var signup formTestSignup
+	form := NewStructForm(&signup)
+	var names []string
+	for _, field := range form.fields {
+		names = append(names, field.name)
+	}
+	zr.TEqual(t, strings.Join(names, " "),
+		"Name email Age Rating Plan Code Terms Notes ID")
+	//
+	// a semicolon after a backslash is part of an option
+	pair := NewStructForm(&struct {
+		Pair string `web:"pattern=\\w+\\;\\w+; max=5"`
+	}{})
+	if zr.TEqual(t, len(pair.fields), 1) {
+		zr.TEqual(t, pair.fields[0].patternText, `\w+;\w+`)
+		zr.TTrue(t, pair.fields[0].pattern.MatchString("ab;cd"))
+		zr.TTrue(t, pair.fields[0].hasMax)
+	}
+	//
+	// errors are reported and leave out fields or the whole form
+	zr.DisableErrors()
+	defer zr.EnableErrors()
+	test := func(model interface{}, expectFields int) {
+		count := zr.GetErrorCount()
+		zr.TEqual(t, len(NewStructForm(model).fields), expectFields)
+		zr.TEqual(t, zr.GetErrorCount()-count, 1)
+	}
+	test(signup, 0)
+	test((*formTestSignup)(nil), 0)
+	test(&struct {
+		A string `web:"label=A"`
+		B string `web:"colour=red"`
+	}{}, 1)
+	test(&struct {
+		A int `web:"min=x"`
+	}{}, 0)
+	test(&struct {
+		A string `web:"pattern=("`
+	}{}, 0)
+	test(&struct {
+		A []int `web:"label=A"`
+	}{}, 0)
+} //                                                    Test_form_NewStructForm_
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *StructForm)
+
+// go test --run Test_form_StructForm_Bind_
+func Test_form_StructForm_Bind_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *StructForm) Bind(values url.Values) bool
+	//
+	signup := formTestSignup{Token: "t", Terms: true}
+	form := NewStructForm(&signup)
+	zr.TTrue(t, form.Bind(url.Values{
+		"Name": {" Alice "}, "email": {"a@example.com"}, "Age": {"30"},
+		"Rating": {"4.5"}, "Plan": {"pro"}, "Code": {"ab"},
+		"Terms": {"true"}, "ID": {"7"}, "Token": {"x"},
+	}))
+	zr.TEqual(t, signup, formTestSignup{
+		Name: "Alice", Email: "a@example.com", Age: 30, Rating: 4.5,
+		Plan: "pro", Code: "ab", Terms: true, Token: "t", ID: 7,
+	})
+	zr.TEqual(t, len(form.Errors()), 0)
+	//
+	// invalid values give an error for each field
+	zr.TTrue(t, !form.Bind(url.Values{
+		"Name": {"A"}, "Age": {"abc"}, "Rating": {"9"}, "Plan": {"gold"},
+		"Code": {"ABC"}, "Notes": {"n"},
+	}))
+	zr.TEqual(t, form.Errors(), map[string]string{
+		"Name":   "Full name must have at least 2 characters",
+		"email":  "Email is required",
+		"Age":    "Age must be a number",
+		"Rating": "Rating must be at most 5",
+		"Plan":   "Plan must be one of the options",
+		"Code":   "Code is not in the right format",
+		"Terms":  "I accept is required",
+	})
+	zr.TEqual(t, signup.Age, 30) // not changed by "abc"
+	zr.TEqual(t, signup.Terms, false)
+	zr.TEqual(t, signup.Notes, "n")
+	//
+	// blank numbers are zero, and only checked if required
+	zr.TTrue(t, !form.Bind(url.Values{"Age": {""}, "ID": {""}}))
+	zr.TEqual(t, form.FieldError("Age"), "")
+	zr.TEqual(t, signup.Age, 0)
+	zr.TTrue(t, !form.Bind(url.Values{"Age": {"17"}}))
+	zr.TEqual(t, form.FieldError("Age"), "Age must be at least 18")
+	//
+	// only text-like values are trimmed
+	var model struct {
+		Name string
+		Pass string `web:"type=password"`
+		Note string `web:"type=textarea"`
+		Key  string `web:"type=hidden"`
+		Age  int
+	}
+	zr.TTrue(t, NewStructForm(&model).Bind(url.Values{
+		"Name": {" Ann "}, "Pass": {" pw "}, "Note": {" a\n"},
+		"Key": {" k"}, "Age": {" 7 "},
+	}))
+	zr.TEqual(t, model.Name, "Ann")
+	zr.TEqual(t, model.Pass, " pw ")
+	zr.TEqual(t, model.Note, " a\n")
+	zr.TEqual(t, model.Key, " k")
+	zr.TEqual(t, model.Age, 7)
+} //                                                  Test_form_StructForm_Bind_
+
+// go test --run Test_form_StructForm_BindPost_
+func Test_form_StructForm_BindPost_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *StructForm) BindPost(ctx *Context) bool
+	//
+	var signup formTestSignup
+	req := httptest.NewRequest("POST", "/signup", strings.NewReader(
+		"Name=Bob+Smith&email=b%40example.com&Terms=true&Plan=free"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	ctx := NewContext(httptest.NewRecorder(), req, nil)
+	zr.TTrue(t, NewStructForm(&signup).BindPost(&ctx))
+	zr.TEqual(t, signup.Name, "Bob Smith")
+	zr.TEqual(t, signup.Email, "b@example.com")
+	zr.TEqual(t, signup.Plan, "free")
+	zr.TTrue(t, signup.Terms)
+	//
+	// a malformed body is a validation error, not a logged error
+	zr.DisableErrors()
+	defer zr.EnableErrors()
+	count := zr.GetErrorCount()
+	req = httptest.NewRequest("POST", "/signup", strings.NewReader(
+		"Name=Bob+Smith&email=b%40example.com&Terms=true&Plan=%zz"))
+	ctx = NewContext(httptest.NewRecorder(), req, nil)
+	form := NewStructForm(&signup)
+	zr.TFalse(t, form.BindPost(&ctx))
+	zr.TEqual(t, zr.GetErrorCount(), count)
+	zr.TEqual(t, form.Result().Errors, []ValidationError{
+		{"", "form", "The form data is not valid"},
+	})
+	zr.TTrue(t, strings.Contains(form.Form().String(),
+		`<p class="error">The form data is not valid</p>`))
+} //                                              Test_form_StructForm_BindPost_
+
+// go test --run Test_form_StructForm_Form_
+func Test_form_StructForm_Form_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *StructForm) Form(content ...interface{}) *Buffer
+	//
+	var model struct {
+		Name  string `web:"label=<Name>; required; max=5"`
+		Age   int    `web:"min=18"`
+		Plan  string `web:"options=a:A & B|b"`
+		Terms bool
+		Code  string `web:"type=textarea; pattern=[a-z]+"`
+		Pass  string `web:"type=password"`
+		ID    int    `web:"type=hidden"`
+	}
+	model.Name, model.Age, model.Code, model.Pass = `"Al"`, 20, "<b>", "p"
+	form := NewStructForm(&model)
+	zr.TEqual(t, form.Form(Attr("action", "/save"),
+		Button(Type("submit"), "Save")).String(),
+		`<form method="post" action="/save"><div class="field">`+"\r\n"+
+			`<label for="field-Name">&lt;Name&gt;</label>`+"\r\n"+
+			`<input id="field-Name" name="Name" required type="text"`+
+			` value="&quot;Al&quot;" maxlength="5">`+"\r\n"+
+			"</div>\r\n"+
+			`<div class="field">`+"\r\n"+
+			`<label for="field-Age">Age</label>`+"\r\n"+
+			`<input id="field-Age" name="Age" type="number" value="20"`+
+			` min="18">`+"\r\n"+
+			"</div>\r\n"+
+			`<div class="field">`+"\r\n"+
+			`<label for="field-Plan">Plan</label>`+"\r\n"+
+			`<select id="field-Plan" name="Plan"><option></option>`+"\r\n"+
+			`<option value="a">A &amp; B</option>`+"\r\n"+
+			`<option value="b">b</option>`+"\r\n"+
+			"</select>\r\n"+
+			"</div>\r\n"+
+			`<div class="field">`+"\r\n"+
+			`<label><input id="field-Terms" name="Terms" type="checkbox"`+
+			` value="true">`+"\r\n"+
+			"Terms</label>\r\n"+
+			"</div>\r\n"+
+			`<div class="field">`+"\r\n"+
+			`<label for="field-Code">Code</label>`+"\r\n"+
+			`<textarea id="field-Code" name="Code">&lt;b&gt;</textarea>`+
+			"\r\n"+
+			"</div>\r\n"+
+			`<div class="field">`+"\r\n"+
+			`<label for="field-Pass">Pass</label>`+"\r\n"+
+			`<input id="field-Pass" name="Pass" type="password">`+"\r\n"+
+			"</div>\r\n"+
+			`<input type="hidden" name="ID" value="0">`+"\r\n"+
+			`<button type="submit">Save</button>`+"\r\n"+
+			"</form>\r\n")
+	//
+	// after binding, the inputs show the submitted values and errors
+	form.Bind(url.Values{"Name": {"Alexander"}, "Age": {"x"}, "Plan": {"b"},
+		"Terms": {"true"}})
+	page := ParseHTML(form.Form().String())
+	zr.TEqual(t, page.FindFirst("#field-Name").Attr("value"), "Alexander")
+	zr.TEqual(t, page.FindFirst("#field-Age").Attr("value"), "x")
+	zr.TTrue(t, page.FindFirst("#field-Terms").HasAttr("checked"))
+	zr.TTrue(t, page.Find("option")[2].HasAttr("selected"))
+	var errors []string
+	for _, div := range page.Find("div.field.error") {
+		input := div.FindFirst(".error")
+		zr.TEqual(t, input.Attr("aria-invalid"), "true")
+		span := div.FindFirst("span.error")
+		errors = append(errors, span.Children()[0].Data())
+	}
+	zr.TEqual(t, strings.Join(errors, ", "),
+		"<Name> must have at most 5 characters, Age must be a number")
+} //                                                  Test_form_StructForm_Form_
+
+// go test --run Test_form_StructForm_Result_
+func Test_form_StructForm_Result_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *StructForm) Result() *ValidationResult
+	//
+	// 'validate' tags are checked after 'web' tags
+	var model struct {
+		Email   string `web:"required" validate:"email"`
+		Pass    string `web:"type=password; label=Password"`
+		Confirm string `web:"type=password" validate:"eqfield=Pass"`
+		Age     int    `web:"min=18"`
+	}
+	form := NewStructForm(&model)
+	zr.TTrue(t, form.Result().OK())
+	zr.TTrue(t, !form.Bind(url.Values{
+		"Email": {"x"}, "Pass": {"a"}, "Confirm": {"b"}, "Age": {"x"},
+	}))
+	zr.TEqual(t, form.Result().Errors, []ValidationError{
+		{"Email", "email", "Email must be a valid email address"},
+		{"Confirm", "eqfield", "Confirm must be the same as Password"},
+		{"Age", "number", "Age must be a number"},
+	})
+	zr.TTrue(t, form.Bind(url.Values{
+		"Email": {"a@example.com"}, "Pass": {"a"}, "Confirm": {"a"},
+	}))
+	zr.TTrue(t, form.Result().OK())
+} //                                                Test_form_StructForm_Result_
+
+// go test --run Test_form_StructForm_SetError_
+func Test_form_StructForm_SetError_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *StructForm) SetError(name, message string)
+	//
+	var model struct{ Email string }
+	form := NewStructForm(&model)
+	zr.TTrue(t, form.Validate())
+	form.SetError("Email", "Email is already registered")
+	zr.TTrue(t, !form.Validate())
+	zr.TEqual(t, form.FieldError("Email"), "Email is already registered")
+	zr.TTrue(t, strings.Contains(form.Form().String(),
+		`<span class="error">Email is already registered</span>`))
+	form.SetError("Email", "")
+	zr.TTrue(t, form.Validate())
+} //                                              Test_form_StructForm_SetError_
+
+// go test --run Test_form_StructForm_SetLocale_
+func Test_form_StructForm_SetLocale_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *StructForm) SetLocale(locale string)
+	//
+	RegisterValidationMessages("xx", map[string]string{
+		"required":  "{field} fehlt",
+		"range.min": "{field}: mindestens {0}",
+	})
+	var model struct {
+		Name string `web:"required"`
+		Age  int    `web:"min=18"`
+		Mail string `validate:"email"`
+	}
+	form := NewStructForm(&model)
+	form.SetLocale("xx")
+	form.Bind(url.Values{"Age": {"1"}, "Mail": {"x"}})
+	zr.TEqual(t, form.Errors(), map[string]string{
+		"Name": "Name fehlt",
+		"Age":  "Age: mindestens 18",
+		"Mail": "Mail must be a valid email address",
+	})
+} //                                             Test_form_StructForm_SetLocale_
+
+// end
@@ -0,0 +1,119 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                   zr-web/[health.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	Health serves the liveness and readiness endpoints that load
+//	balancers and orchestrators probe:
+//
+//	var health web.Health
+//
+//	health.AddReadyCheck("database", db.Ping)
+//	http.HandleFunc("/healthz", health.Live)
+//	http.HandleFunc("/readyz", health.Ready)
+//
+//	Live() runs the checks added with AddCheck(). Ready() runs those
+//	checks and the ones added with AddReadyCheck(). Both reply 200
+//	(OK) when all checks pass and 503 (Service Unavailable) when any
+//	check fails, with one line per check in the reply's body.
+
+//  Health struct
+//
+// # Methods (ob *Health)
+//   ) AddCheck(name string, fn func() error)
+//   ) AddReadyCheck(name string, fn func() error)
+//   ) Live(w http.ResponseWriter, req *http.Request)
+//   ) Ready(w http.ResponseWriter, req *http.Request)
+//
+// # Support (File Scope)
+//   (ob *Health) serve(w http.ResponseWriter, ready bool)
+//   healthCheck struct
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+// Health holds the check functions of the health endpoints.
+// The zero value is ready to use and replies 200 (OK).
+type Health struct {
+	checks      []healthCheck
+	readyChecks []healthCheck
+	mutex       sync.RWMutex
+} //                                                                      Health
+
+// healthCheck is a named check function
+type healthCheck struct {
+	name string
+	fn   func() error
+} //                                                                 healthCheck
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Health)
+
+// AddCheck adds a liveness check, which fails when the process is
+// broken and should be restarted. It is also a readiness check.
+func (ob *Health) AddCheck(name string, fn func() error) {
+	ob.mutex.Lock()
+	ob.checks = append(ob.checks, healthCheck{name: name, fn: fn})
+	ob.mutex.Unlock()
+} //                                                                    AddCheck
+
+// AddReadyCheck adds a readiness check, which fails when the process
+// can't serve requests for now, e.g. while a database is unreachable.
+func (ob *Health) AddReadyCheck(name string, fn func() error) {
+	ob.mutex.Lock()
+	ob.readyChecks = append(ob.readyChecks, healthCheck{name: name, fn: fn})
+	ob.mutex.Unlock()
+} //                                                               AddReadyCheck
+
+// Live is the handler of the liveness endpoint, usually '/healthz'.
+func (ob *Health) Live(w http.ResponseWriter, req *http.Request) {
+	ob.serve(w, false)
+} //                                                                        Live
+
+// Ready is the handler of the readiness endpoint, usually '/readyz'.
+func (ob *Health) Ready(w http.ResponseWriter, req *http.Request) {
+	ob.serve(w, true)
+} //                                                                       Ready
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// serve runs the liveness checks, and also the readiness checks
+// if 'ready' is true, and writes the results to 'w'.
+func (ob *Health) serve(w http.ResponseWriter, ready bool) {
+	ob.mutex.RLock()
+	checks := append([]healthCheck{}, ob.checks...)
+	if ready {
+		checks = append(checks, ob.readyChecks...)
+	}
+	ob.mutex.RUnlock()
+	//
+	var buf bytes.Buffer
+	status := http.StatusOK
+	for _, check := range checks {
+		err := check.fn()
+		if err != nil {
+			status = http.StatusServiceUnavailable
+			buf.WriteString("fail " + check.name + ": " + err.Error() + "\n")
+			continue
+		}
+		buf.WriteString("ok " + check.name + "\n")
+	}
+	if status == http.StatusOK {
+		buf.WriteString("ok\n")
+	} else {
+		buf.WriteString("fail\n")
+	}
+	header := w.Header()
+	header.Set("Content-Type", "text/plain; charset=utf-8")
+	header.Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+} //                                                                       serve
+
+// end
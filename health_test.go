@@ -0,0 +1,97 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                              zr-web/[health_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Methods (ob *Health)
+//   Test_hlth_Health_Live_
+//   Test_hlth_Health_Ready_
+
+//  to test all items in health.go use:
+//      go test --run Test_hlth_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Health)
+
+// go test --run Test_hlth_Health_Live_
+func Test_hlth_Health_Live_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Health) Live(w http.ResponseWriter, req *http.Request)
+	//
+	serve := func(health *Health) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		health.Live(rec, httptest.NewRequest("GET", "/healthz", nil))
+		return rec
+	}
+	// the zero value is healthy
+	{
+		var health Health
+		rec := serve(&health)
+		zr.TEqual(t, rec.Code, http.StatusOK)
+		zr.TEqual(t, rec.Body.String(), "ok\n")
+		zr.TEqual(t, rec.Header().Get("Cache-Control"), "no-store")
+	}
+	// readiness checks don't affect liveness
+	{
+		var health Health
+		health.AddCheck("disk", func() error { return nil })
+		health.AddReadyCheck("database", func() error {
+			return errors.New("unreachable")
+		})
+		rec := serve(&health)
+		zr.TEqual(t, rec.Code, http.StatusOK)
+		zr.TEqual(t, rec.Body.String(), "ok disk\nok\n")
+	}
+	// a failing liveness check
+	{
+		var health Health
+		health.AddCheck("disk", func() error { return errors.New("full") })
+		rec := serve(&health)
+		zr.TEqual(t, rec.Code, http.StatusServiceUnavailable)
+		zr.TEqual(t, rec.Body.String(), "fail disk: full\nfail\n")
+	}
+} //                                                      Test_hlth_Health_Live_
+
+// go test --run Test_hlth_Health_Ready_
+func Test_hlth_Health_Ready_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Health) Ready(w http.ResponseWriter, req *http.Request)
+	//
+	var health Health
+	var dbErr error
+	health.AddCheck("disk", func() error { return nil })
+	health.AddReadyCheck("database", func() error { return dbErr })
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		health.Ready(rec, httptest.NewRequest("GET", "/readyz", nil))
+		return rec
+	}
+	rec := serve()
+	zr.TEqual(t, rec.Code, http.StatusOK)
+	zr.TEqual(t, rec.Body.String(), "ok disk\nok database\nok\n")
+	//
+	dbErr = errors.New("unreachable")
+	rec = serve()
+	zr.TEqual(t, rec.Code, http.StatusServiceUnavailable)
+	zr.TEqual(t, rec.Body.String(),
+		"ok disk\nfail database: unreachable\nfail\n")
+	zr.TEqual(t, rec.Header().Get("Content-Type"),
+		"text/plain; charset=utf-8")
+} //                                                     Test_hlth_Health_Ready_
+
+// end
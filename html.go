@@ -5,9 +5,14 @@
 
 package web
 
+//	The helpers for the other HTML5 elements, e.g. Section() and Table(),
+//	are in elements.go, which is generated from a table in elements_gen.go.
+
 // # Global Settings
 //   UseNthChild() bool
 //   SetUseNthChild(val bool)
+//   StrictAttributes() bool
+//   SetStrictAttributes(val bool)
 //
 // # Functions
 // # Top-Level Container Elements
@@ -18,6 +23,7 @@ package web
 // # General Wrappers
 //
 // # Functions
+//   IsVoidElement(elementName string) bool
 //   SetClass(add bool, input string, classes ...string) string
 //
 // # Top-Level Container Elements
@@ -48,16 +54,33 @@ package web
 //   Ul(content ...interface{}) *Buffer
 //
 // # HTML Attributes
+//   Attrs map type
+//   (ob Attrs) Attributes() Attributes
+//   Alt(text string) Attribute
+//   Aria(name, value string) Attribute
 //   Attr(name, val string) Attribute
+//   BoolAttr(name string, on bool) Attribute
 //   Class(classList ...string) Attribute
 //   Content(locale string) Attribute
+//   Data(name, value string) Attribute
+//   For(id string) Attribute
 //   HREF(href string) Attribute
 //   ID(locale string) Attribute
 //   Lang(locale string) Attribute
 //   Name(locale string) Attribute
 //   OnClick(jsCall string) Attribute
 //   OnLoad(jsCall string) Attribute
+//   Pattern(regex string) Attribute
+//   Placeholder(text string) Attribute
+//   Rel(rel string) Attribute
+//   Role(role string) Attribute
+//   Src(url string) Attribute
+//   Style(properties ...string) Attribute
+//   TabIndex(index int) Attribute
+//   Target(target string) Attribute
+//   TitleAttr(text string) Attribute
 //   Type(locale string) Attribute
+//   Value(value string) Attribute
 //
 // # Helper Tags (non-standard tags that simplify markup)
 //   COLUMNS(cols []string, class string, useNthChild bool) *Buffer
@@ -84,9 +107,9 @@ package web
 //   Meta(attributes ...Attribute) *Buffer /*DEPRECATED*/
 
 import (
-	"bytes"
 	"fmt"
-	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/balacode/zr"
@@ -103,6 +126,18 @@ const oldBrowsers = false
 // True by default.
 var useNthChild = true
 
+// strictAttributes specifies if an attribute given to an element
+// more than once with different values is reported as an error.
+// False by default. See SetStrictAttributes().
+var strictAttributes = false
+
+// voidElements lists the elements that have no content or end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "keygen": true, "link": true,
+	"meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
 // -----------------------------------------------------------------------------
 // # Global Settings
 
@@ -116,9 +151,31 @@ func SetUseNthChild(val bool) {
 	useNthChild = val
 } //                                                              SetUseNthChild
 
+// StrictAttributes returns true if conflicting attributes are
+// reported as errors. See SetStrictAttributes().
+func StrictAttributes() bool {
+	return strictAttributes
+} //                                                            StrictAttributes
+
+// SetStrictAttributes specifies if conflicting attributes should be
+// reported. When an element is given an attribute more than once, the
+// last value is used (see Attributes.Merge). In strict mode, this is
+// also reported with zr.Error() if the values differ, except for the
+// 'class' and 'style' attributes, whose values are combined. Useful
+// while developing, to find helpers that set the same attribute.
+func SetStrictAttributes(val bool) {
+	strictAttributes = val
+} //                                                         SetStrictAttributes
+
 // -----------------------------------------------------------------------------
 // # Functions
 
+// IsVoidElement returns true if 'elementName' is a void element, such as
+// <br> or <img>, which has no content and must not have an end tag.
+func IsVoidElement(elementName string) bool {
+	return voidElements[lowerASCII(elementName)]
+} //                                                               IsVoidElement
+
 // SetClass appends or removes the specified class(es) to the given string.
 // 'add' specifies if the class should be added (or removed if false).
 //  'input' is the existing class string.
@@ -161,8 +218,7 @@ func SetClass(add bool, input string, classes ...string) string {
 // Attributes: manifest, xmlns
 func HTML(content ...interface{}) []byte {
 	retBuf := NewBuffer(4096)
-	retBuf.WriteString("<!DOCTYPE html>\r\n")
-	retBuf.Write(Container("html", content...))
+	HTMLNode(content...).WriteTo(&retBuf.html)
 	return retBuf.Bytes()
 } //                                                                        HTML
 
@@ -171,14 +227,14 @@ func HTML(content ...interface{}) []byte {
 // Allowed child elements: <base> <link> <meta> <noscript> <script> <style>
 // <title> (required)
 func Head(content ...interface{}) *Buffer {
-	return Container("head", content...)
+	return HeadNode(content...).Render()
 } //                                                                        Head
 
 // Body tag: contains the visible content of a page,
 // including text, images, hyperlinks, etc.
 // Attributes: alink, background, bgcolor, link, text, vlink
 func Body(content ...interface{}) *Buffer {
-	return Container("body", content...)
+	return BodyNode(content...).Render()
 } //                                                                        Body
 
 // -----------------------------------------------------------------------------
@@ -188,158 +244,218 @@ func Body(content ...interface{}) *Buffer {
 // pages and locations in the current document.
 // Attributes: charset coords download href hreflang
 // media name rel rev shape target type
+// An HREF() in 'content' is replaced by 'href', which is
+// escaped (see HREF).
 func A(href string, content ...interface{}) *Buffer {
-	// TODO: prevent multiple href attributes
-	content = append(content, HREF(href))
-	return Container("a", content...)
+	return ANode(href, content...).Render()
 } //                                                                           A
 
 // Article defines self-contained content.
 // Attributes: Global, Event
 func Article(content ...interface{}) *Buffer {
-	if oldBrowsers {
-		return Container("div", content...)
-	}
-	return Container("article", content...)
+	return ArticleNode(content...).Render()
 } //                                                                     Article
 
 // Div tag defines an arbitrary division in the document.
 // Attributes: align (left, right, center, justify)
 func Div(content ...interface{}) *Buffer {
-	return Container("div", content...)
+	return DivNode(content...).Render()
 } //                                                                         Div
 
 // Form tag.
 func Form(content ...interface{}) *Buffer {
-	return Container("form", content...)
+	return FormNode(content...).Render()
 } //                                                                        Form
 
 // H1 tag specifies headings (level 1)
 // Attributes: align (left, center, right, justify)
 func H1(content ...interface{}) *Buffer {
-	return Container("h1", content...)
+	return H1Node(content...).Render()
 } //                                                                          H1
 
 // H2 tag specifies headings (level 2)
 // Attributes: align (left, center, right, justify)
 func H2(content ...interface{}) *Buffer {
-	return Container("h2", content...)
+	return H2Node(content...).Render()
 } //                                                                          H2
 
 // H3 tag specifies headings (level 3)
 // Attributes: align (left, center, right, justify)
 func H3(content ...interface{}) *Buffer {
-	return Container("h3", content...)
+	return H3Node(content...).Render()
 } //                                                                          H3
 
 // H4 tag specifies headings (level 4)
 // Attributes: align (left, center, right, justify)
 func H4(content ...interface{}) *Buffer {
-	return Container("h4", content...)
+	return H4Node(content...).Render()
 } //                                                                          H4
 
 // H5 tag specifies headings (level 5)
 // Attributes: align (left, center, right, justify)
 func H5(content ...interface{}) *Buffer {
-	return Container("h5", content...)
+	return H5Node(content...).Render()
 } //                                                                          H5
 
 // H6 tag specifies headings (level 6)
 // Attributes: align (left, center, right, justify)
 func H6(content ...interface{}) *Buffer {
-	return Container("h6", content...)
+	return H6Node(content...).Render()
 } //                                                                          H6
 
 // Header tag contains the headings of a section or navigational links.
 func Header(content ...interface{}) *Buffer {
-	if oldBrowsers {
-		Div(
-			Class("header"),
-			Container("div", content...),
-		)
-	}
-	return Div(
-		Class("header"),
-		Container("header", content...),
-	)
+	return HeaderNode(content...).Render()
 } //                                                                      Header
 
 // IFrame represents an <iframe> tag, i.e. inline frame.
 func IFrame(content ...interface{}) *Buffer {
-	return Container("iframe", content...)
+	return IFrameNode(content...).Render()
 } //                                                                      IFrame
 
 // Img inserts an image element.
 // For example Img("folder/filename.png") will become
-// <img src="folder/filename.png"> in the output HTML
+// <img src="folder/filename.png"> in the output HTML.
+// Like other void elements, it has no end tag.
 func Img(content ...interface{}) *Buffer {
-	for i, it := range content {
-		if s, ok := it.(string); ok {
-			content[i] = Attribute{Name: "src", Value: s}
-		}
-	}
-	return Container("img", content...)
+	return ImgNode(content...).Render()
 } //                                                                         Img
 
 // Label tag represents a label element.
 func Label(content ...interface{}) *Buffer {
-	return Container("label", content...)
+	return LabelNode(content...).Render()
 } //                                                                       Label
 
 // Li tag defines a list item in unordered (ul), ordered (ol)
 // or menu lists (menu). Attributes: global attributes and value.
 func Li(content ...interface{}) *Buffer {
-	return Container("li", content...)
+	return LiNode(content...).Render()
 } //                                                                          Li
 
 // Nav tag defines a section with navigation links.
 func Nav(content ...interface{}) *Buffer {
-	if oldBrowsers {
-		return Container("div", content...)
-	}
-	return Container("nav", content...)
+	return NavNode(content...).Render()
 } //                                                                         Nav
 
 // P tag defines a paragraph.
 // Attribute: align, global attributes
 func P(content ...interface{}) *Buffer {
-	return Container("p", content...)
+	return PNode(content...).Render()
 } //                                                                           P
 
 // Span tag groups inline elements.
 func Span(content ...interface{}) *Buffer {
-	return Container("span", content...)
+	return SpanNode(content...).Render()
 } //                                                                        Span
 
 // Title tag specifies the title or name of the document.
 // It is required to be placed within the <head> element.
 func Title(content ...interface{}) *Buffer {
-	return Container("title", content...)
+	return TitleNode(content...).Render()
 } //                                                                       Title
 
 // Ul defines an unordered list. (A list in which the ordering of items
 // is not important). The list can contain zero or more <li> elements.
 func Ul(content ...interface{}) *Buffer {
-	return Container("ul", content...)
+	return UlNode(content...).Render()
 } //                                                                          Ul
 
 // -----------------------------------------------------------------------------
 // # HTML Attributes
 
 // Attribute holds the name and value of a single HTML attribute.
+// Bare is set for boolean attributes (see BoolAttr), which are
+// written without a value, e.g. <input disabled>.
+//
+// Value is written as it is, so it must already be escaped. The
+// attribute functions below, e.g. Class(), HREF(), ID(), Name() and
+// Src(), take plain text and escape it, except Attr() and Attrs,
+// which are meant for values that are already escaped. So pass
+// unescaped values to them: HREF("/?a=1&b=2") is written as
+// href="/?a=1&amp;b=2", while "/?a=1&amp;b=2" would be escaped
+// again. Use Attr() for values you have escaped yourself.
 type Attribute struct {
 	Name  string
 	Value string
+	Bare  bool
 } //                                                                   Attribute
 
-// Attr specifies any element's attribute.
+// Attrs holds attributes by name. It can be passed to Container() and
+// the other helpers, e.g. to pass on attributes given to a function:
+//
+//	Div(Attrs{"id": "menu", "data-open": "1"}, content)
+//
+// The attributes are written sorted by name, so the output is always
+// the same. Like in Attr(), values are written as they are.
+type Attrs map[string]string
+
+// Attributes returns the attributes in 'ob' sorted by name, e.g. to
+// pass them to Element(), which only takes attributes:
+//
+//	Element("input", Attrs{"type": "text", "name": "q"}.Attributes()...)
+func (ob Attrs) Attributes() Attributes {
+	names := make([]string, 0, len(ob))
+	for name := range ob {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ret := make(Attributes, len(names))
+	for i, name := range names {
+		ret[i] = Attribute{Name: name, Value: ob[name]}
+	}
+	return ret
+} //                                                                  Attributes
+
+// htmlAttrEscaper escapes text for use in attribute values
+var htmlAttrEscaper = strings.NewReplacer(
+	"&", "&amp;", `"`, "&quot;", "<", "&lt;", ">", "&gt;",
+)
+
+// cssValueEscaper escapes characters that would end a CSS
+// property value, using CSS escapes
+var cssValueEscaper = strings.NewReplacer(
+	`\`, `\5c `, ";", `\3b `, "{", `\7b `, "}", `\7d `,
+	"\n", `\a `, "\r", `\d `,
+)
+
+// Alt attribute specifies the text shown in place of an image.
+// 'text' is plain text, which is escaped.
+func Alt(text string) Attribute {
+	return Attribute{Name: "alt", Value: htmlAttrEscaper.Replace(text)}
+} //                                                                         Alt
+
+// Aria specifies an ARIA accessibility attribute, which describes
+// an element to assistive technologies, e.g. Aria("label", "Close")
+// becomes aria-label="Close". 'value' is escaped.
+func Aria(name, value string) Attribute {
+	if !strings.HasPrefix(name, "aria-") {
+		name = "aria-" + name
+	}
+	return Attribute{Name: name, Value: htmlAttrEscaper.Replace(value)}
+} //                                                                        Aria
+
+// Attr specifies any element's attribute. Unlike the other attribute
+// functions, 'val' is written as it is, so it must not contain
+// unescaped '"', '&', '<' or '>', e.g. from user input.
 func Attr(name, val string) Attribute {
 	return Attribute{Name: name, Value: val}
 } //                                                                        Attr
 
+// BoolAttr specifies a boolean attribute, such as 'disabled', 'checked',
+// 'required' or 'multiple', which is written without a value if 'on'
+// is true, or left out if 'on' is false. E.g. Input(BoolAttr("checked",
+// isChecked)) becomes <input checked> if isChecked is true.
+func BoolAttr(name string, on bool) Attribute {
+	if !on {
+		return Attribute{}
+	}
+	return Attribute{Name: name, Bare: true}
+} //                                                                    BoolAttr
+
 // Class represents the 'class' attribute. You can specify multiple class
 // strings in which case they will be delimited by a space. E.g.
-// Class("currency", "sum") will become class="currency sum"
+// Class("currency", "sum") will become class="currency sum".
+// The classes are escaped.
 func Class(classList ...string) Attribute {
 	var class string
 	for _, s := range classList {
@@ -348,89 +464,180 @@ func Class(classList ...string) Attribute {
 		}
 		class += s
 	}
-	return Attribute{Name: "class", Value: class}
+	return Attribute{Name: "class", Value: htmlAttrEscaper.Replace(class)}
 } //                                                                       Class
 
-// Content attribute applies to <meta> tags.
+// Content attribute applies to <meta> tags. 'locale' is
+// plain text, which is escaped.
 func Content(locale string) Attribute {
-	return Attribute{Name: "content", Value: locale}
+	return Attribute{Name: "content", Value: htmlAttrEscaper.Replace(locale)}
 } //                                                                     Content
 
-// HREF attribute applies to <a> tags.
+// Data specifies a custom data attribute, which holds data for scripts,
+// e.g. Data("user-id", "12") becomes data-user-id="12". 'value' is
+// escaped.
+func Data(name, value string) Attribute {
+	if !strings.HasPrefix(name, "data-") {
+		name = "data-" + name
+	}
+	return Attribute{Name: name, Value: htmlAttrEscaper.Replace(value)}
+} //                                                                        Data
+
+// For attribute links a <label> to the control with ID 'id',
+// which is escaped.
+func For(id string) Attribute {
+	return Attribute{Name: "for", Value: htmlAttrEscaper.Replace(id)}
+} //                                                                         For
+
+// HREF attribute applies to <a> tags. 'href' is escaped, so
+// give it unescaped, e.g. "/find?q=a&page=2", not "&amp;page=2".
 func HREF(href string) Attribute {
-	return Attribute{Name: "href", Value: href}
+	return Attribute{Name: "href", Value: htmlAttrEscaper.Replace(href)}
 } //                                                                        HREF
 
-// ID attribute applies to various tags.
+// ID attribute applies to various tags. The ID is escaped.
 func ID(locale string) Attribute {
-	return Attribute{Name: "id", Value: locale}
+	return Attribute{Name: "id", Value: htmlAttrEscaper.Replace(locale)}
 } //                                                                          ID
 
-// Lang attribute applies to <html> tags.
+// Lang attribute applies to <html> tags. The locale is escaped.
 func Lang(locale string) Attribute {
-	return Attribute{Name: "lang", Value: locale}
+	return Attribute{Name: "lang", Value: htmlAttrEscaper.Replace(locale)}
 } //                                                                        Lang
 
-// Name attribute applies to various tags.
+// Name attribute applies to various tags. The name is escaped.
 func Name(locale string) Attribute {
-	return Attribute{Name: "name", Value: locale}
+	return Attribute{Name: "name", Value: htmlAttrEscaper.Replace(locale)}
 } //                                                                        Name
 
-// OnClick attribute applies to various tags.
+// OnClick attribute applies to various tags. 'jsCall' is script
+// code, which is escaped for the attribute, so write quotes and '&'
+// in it as they are in the script.
 func OnClick(jsCall string) Attribute {
 	if jsCall == "" {
 		return Attribute{}
 	}
-	return Attribute{Name: "onclick", Value: jsCall}
+	return Attribute{Name: "onclick", Value: htmlAttrEscaper.Replace(jsCall)}
 } //                                                                     OnClick
 
-// OnLoad attribute applies to various tags.
+// OnLoad attribute applies to various tags. Like OnClick(),
+// 'jsCall' is escaped.
 func OnLoad(jsCall string) Attribute {
 	if jsCall == "" {
 		return Attribute{}
 	}
-	return Attribute{Name: "onload", Value: jsCall}
+	return Attribute{Name: "onload", Value: htmlAttrEscaper.Replace(jsCall)}
 } //                                                                      OnLoad
 
-// Type attribute.
+// Pattern attribute specifies a regular expression that the value of
+// an input must match before its form can be submitted. 'regex' is
+// escaped.
+func Pattern(regex string) Attribute {
+	return Attribute{Name: "pattern", Value: htmlAttrEscaper.Replace(regex)}
+} //                                                                     Pattern
+
+// Placeholder attribute specifies a hint shown in an empty input.
+// 'text' is plain text, which is escaped.
+func Placeholder(text string) Attribute {
+	return Attribute{
+		Name:  "placeholder",
+		Value: htmlAttrEscaper.Replace(text),
+	}
+} //                                                                 Placeholder
+
+// Rel attribute specifies the relationship to a linked
+// resource, e.g. "stylesheet" or "nofollow noopener".
+// 'rel' is escaped.
+func Rel(rel string) Attribute {
+	return Attribute{Name: "rel", Value: htmlAttrEscaper.Replace(rel)}
+} //                                                                         Rel
+
+// Role attribute specifies the ARIA role of an element, e.g.
+// "button". 'role' is escaped.
+func Role(role string) Attribute {
+	return Attribute{Name: "role", Value: htmlAttrEscaper.Replace(role)}
+} //                                                                        Role
+
+// Src attribute specifies the URL of an image, script or other media.
+// Like HREF(), it escapes the URL, so give it unescaped.
+func Src(url string) Attribute {
+	return Attribute{Name: "src", Value: htmlAttrEscaper.Replace(url)}
+} //                                                                         Src
+
+// Style specifies the 'style' attribute from CSS properties and values
+// given in pairs, which are written in the same order. E.g.
+// Style("color", "red", "margin", "0 auto") becomes
+// style="color: red; margin: 0 auto". Values are escaped, so they
+// can't end the property and start another. Properties with blank
+// values are left out.
+func Style(properties ...string) Attribute {
+	if len(properties)%2 != 0 {
+		zr.Error("Style() needs pairs of properties and values, got",
+			len(properties), "strings")
+		properties = properties[:len(properties)-1]
+	}
+	var sb strings.Builder
+	for i := 0; i < len(properties); i += 2 {
+		prop := strings.TrimSpace(properties[i])
+		value := strings.TrimSpace(properties[i+1])
+		if prop == "" || value == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(cssValueEscaper.Replace(prop))
+		sb.WriteString(": ")
+		sb.WriteString(cssValueEscaper.Replace(value))
+	}
+	return Attribute{
+		Name:  "style",
+		Value: htmlAttrEscaper.Replace(sb.String()),
+	}
+} //                                                                       Style
+
+// TabIndex attribute specifies the order in which elements get the
+// focus with the Tab key. 0 makes an element focusable in document
+// order, -1 makes it focusable only from scripts.
+func TabIndex(index int) Attribute {
+	return Attribute{Name: "tabindex", Value: strconv.Itoa(index)}
+} //                                                                    TabIndex
+
+// Target attribute specifies where to open a link, e.g. "_blank".
+// 'target' is escaped.
+func Target(target string) Attribute {
+	return Attribute{Name: "target", Value: htmlAttrEscaper.Replace(target)}
+} //                                                                      Target
+
+// TitleAttr specifies the 'title' attribute, which browsers show as a
+// tooltip. It is not named Title() since Title() composes the <title>
+// element. 'text' is plain text, which is escaped.
+func TitleAttr(text string) Attribute {
+	return Attribute{Name: "title", Value: htmlAttrEscaper.Replace(text)}
+} //                                                                   TitleAttr
+
+// Type attribute, e.g. "text" or "submit". The type is escaped.
 func Type(locale string) Attribute {
-	return Attribute{Name: "type", Value: locale}
+	return Attribute{Name: "type", Value: htmlAttrEscaper.Replace(locale)}
 } //                                                                        Type
 
+// Value attribute specifies the value of an input, option or button.
+// 'value' is plain text, which is escaped.
+func Value(value string) Attribute {
+	return Attribute{Name: "value", Value: htmlAttrEscaper.Replace(value)}
+} //                                                                       Value
+
 // -----------------------------------------------------------------------------
 // # Helper Tags (non-standard tags that simplify markup)
 
 // COLUMNS creates a group of <p> tags with class c1, c2, c3, etc.
 // for every string passed in 'columns'. Used to create tabular listings
 // using CSS and <p> tags, without the need to use HTML tables.
+//
+// Deprecated: use DataTable, which writes whole tables from rows
+// of data and sets the class of each column without 'class::'.
 func COLUMNS(cols []string, class string, useNthChild bool) *Buffer {
-	var retBuf Buffer
-	ws := retBuf.WriteString
-	ws("<div>\r\n")
-	for i, col := range cols {
-		ws("<p")
-		hasClass := strings.Contains(col, "class::") || class != ""
-		if !useNthChild || hasClass {
-			ws(` class="`)
-			if class != "" {
-				ws(class)
-			}
-			if !useNthChild {
-				if class != "" {
-					ws(" ")
-				}
-				ws(fmt.Sprintf("c c%d", i+1))
-			}
-			if hasClass {
-				part := zr.GetPart(col, "class::", ";")
-				col = strings.ReplaceAll(col, "class::"+part+";", "")
-			}
-			ws(`"`)
-		}
-		ws(">", col, "</p>\r\n")
-	}
-	ws("</div>\r\n")
-	return &retBuf
+	return COLUMNSNode(cols, class, useNthChild).Render()
 } //                                                                     COLUMNS
 
 // CSS links or embeds one or more CSS (Cascading Style Sheet)
@@ -442,23 +649,12 @@ func COLUMNS(cols []string, class string, useNthChild bool) *Buffer {
 // To embed a style locally, specify the style in styles, for example,
 // CSS("body { font: normal 11pt Helvetica }")
 //
+// Embedded styles don't get a Content-Security-Policy nonce: use
+// Context.CSS() for pages served with a nonce (see SecurityHeaders).
+//
 // Should be placed within the <head> element under <html>.
 func CSS(styles ...string) *Buffer {
-	var retBuf Buffer
-	ws := retBuf.WriteString
-	for _, style := range styles {
-		style = strings.TrimSpace(style)
-		if style == "" {
-			continue
-		}
-		if zr.ContainsI(style, ".css") {
-			ws(`<link rel="stylesheet" type="text/css" href="` +
-				style + `">` + "\r\n")
-			continue
-		}
-		ws(`<style type="text/css">` + "\r\n" + style + "\r\n</style>\r\n")
-	}
-	return &retBuf
+	return CSSNode(styles...).Render()
 } //                                                                         CSS
 
 // JOIN concatenates the content of multiple buffers into a single buffer.
@@ -473,22 +669,10 @@ func JOIN(content ...*Buffer) *Buffer {
 } //                                                                        JOIN
 
 // JS links JavaScript (.js) script files or embeds JS code snippets.
+// Embedded snippets don't get a Content-Security-Policy nonce: use
+// Context.JS() for pages served with a nonce (see SecurityHeaders).
 func JS(scripts ...string) *Buffer {
-	var retBuf Buffer
-	ws := retBuf.WriteString
-	for _, js := range scripts {
-		js = strings.TrimSpace(js)
-		if js == "" {
-			continue
-		}
-		if zr.ContainsI(js, ".js") {
-			ws(`<script type="text/javascript" src="` +
-				js + `"></script>` + "\r\n")
-			continue
-		}
-		ws(`<script type="text/javascript">` + js + "</script>\r\n")
-	}
-	return &retBuf
+	return JSNode(scripts...).Render()
 } //                                                                          JS
 
 // NAV helper tag specifies a hyperlink, which links other web pages and
@@ -496,25 +680,16 @@ func JS(scripts ...string) *Buffer {
 // but uses zr.go() in JS to save the current page reference.
 // Attributes: charset coords download href hreflang
 //             media name rel rev shape target type
+// Its 'href' is always "#", and an OnClick() in 'content'
+// is replaced by the call to zr.go().
 func NAV(href string, content ...interface{}) *Buffer {
-	// TODO: prevent multiple href attributes
-	isFuncCall := strings.Contains(href, "(") && strings.Contains(href, ")")
-	if !isFuncCall {
-		href = fmt.Sprintf("zr.go('%s')", href)
-	}
-	content = append(content, Attr("onclick", href))
-	return A("#", content...)
+	return NAVNode(href, content...).Render()
 } //                                                                         NAV
 
 // TEXT helper tag is a non-standard tag that helps
 // inject literal strings into HTML content.
 func TEXT(texts ...string) *Buffer {
-	var retBuf Buffer
-	ws := retBuf.WriteString
-	for _, s := range texts {
-		ws(s)
-	}
-	return &retBuf
+	return TEXTNode(texts...).Render()
 } //                                                                        TEXT
 
 // -----------------------------------------------------------------------------
@@ -524,33 +699,30 @@ func TEXT(texts ...string) *Buffer {
 // This tag has no closing tag and is not a container.
 // Attributes: Global, Event
 func Br(attributes ...Attribute) *Buffer {
-	return Element("br", attributes...)
+	return BrNode(attributes...).Render()
 } //                                                                          Br
 
 // Hr tag inserts a thematic break (horizontal rule pre-HTML5).
 // This tag has no closing tag and is not a container.
 // Attributes: Global, Event
 func Hr(attributes ...Attribute) *Buffer {
-	return Element("hr", attributes...)
+	return HrNode(attributes...).Render()
 } //                                                                          Hr
 
 // Input tag represents an element for user input.
 // This tag has no closing tag and is not a container.
 func Input(attributes ...Attribute) *Buffer {
-	return Element("input", attributes...)
+	return InputNode(attributes...).Render()
 } //                                                                       Input
 
 // MetaCharset attribute applies to <meta> tags.
 func MetaCharset(locale string) *Buffer {
-	return Element("meta", Attr("charset", locale))
+	return MetaCharsetNode(locale).Render()
 } //                                                                 MetaCharset
 
 // MetaViewport meta tag: enables a web page to have a responsive layout.
 func MetaViewport() *Buffer {
-	return Element("meta",
-		Attr("name", "viewport"),
-		Attr("content", "width=device-width, initial-scale=1"),
-	)
+	return MetaViewportNode().Render()
 } //                                                                MetaViewport
 
 // -----------------------------------------------------------------------------
@@ -570,117 +742,16 @@ func Comment(s string) *Buffer {
 } //                                                                     Comment
 
 // Container composes an arbitrary HTML container tag.
+// It renders ContainerNode(), which accepts the same content.
+// Void elements (see IsVoidElement) get no content or end tag.
 func Container(elementName string, content ...interface{}) *Buffer {
-	var (
-		retBuf = NewBuffer(64)
-		w      = retBuf.Write
-		wb     = retBuf.WriteBytes
-		ws     = retBuf.WriteString
-	)
-	// write the opening tag and its attributes
-	ws("<" + elementName)
-	for _, val := range content {
-		switch val := val.(type) {
-		case Attribute:
-			if val.Name != "" && val.Value != "" {
-				ws(fmt.Sprintf(` %s="%s"`, val.Name, val.Value))
-			}
-		}
-	}
-	ws(">")
-	if zr.StrOneOf(elementName,
-		"article", "body", "div", "head", "header", "html", "nav", "ul") {
-		ws("\r\n")
-	}
-	// write the inner content (usually the byte buffers of child tags)
-	for i, val := range content {
-		switch val := val.(type) {
-		case Attribute:
-			{
-				// do nothing: already handled above
-			}
-		case []byte:
-			{
-				wb(val)
-			}
-		case *[]byte:
-			if val != nil {
-				wb(*val)
-			}
-		// web.Buffer
-		case Buffer:
-			{
-				w(&val)
-			}
-		case *Buffer:
-			if val != nil {
-				w(val)
-			}
-		case []Buffer:
-			for _, val := range val {
-				w(&val)
-			}
-		case []*Buffer:
-			for _, val := range val {
-				w(val)
-			}
-		// bytes.Buffer
-		case bytes.Buffer:
-			{
-				wb(val.Bytes())
-			}
-		case *bytes.Buffer:
-			if val != nil {
-				wb(val.Bytes())
-			}
-			// numbers
-		case float64, float32:
-			{
-				ws(fmt.Sprintf("%f", val))
-			}
-		case int, int8, int16, int32, int64,
-			uint, uint64, uint32, uint16, uint8:
-			{
-				ws(fmt.Sprintf("%d", val))
-			}
-		// strings
-		case string:
-			{
-				ws(val)
-			}
-		case []string:
-			for _, s := range val {
-				ws(s)
-			}
-		case fmt.Stringer:
-			{
-				ws(val.String())
-			}
-		default:
-			zr.Error("Content item", i, "of type",
-				reflect.TypeOf(val), "not handled")
-		}
-	}
-	// write closing tag
-	ws("</" + elementName + ">")
-	if elementName != "a" {
-		ws("\r\n")
-	}
-	return &retBuf
+	return ContainerNode(elementName, content...).Render()
 } //                                                                   Container
 
 // Element composes a HTML tag with optional attributes but no child tags.
+// Elements that are not void elements (see IsVoidElement) get an end tag.
 func Element(elementName string, attributes ...Attribute) *Buffer {
-	var (
-		retBuf = NewBuffer(64)
-		ws     = retBuf.WriteString
-	)
-	ws("<" + elementName)
-	for _, attr := range attributes {
-		ws(fmt.Sprintf(` %s="%s"`, attr.Name, attr.Value))
-	}
-	ws(">\r\n")
-	return &retBuf
+	return ElementNode(elementName, attributes...).Render()
 } //                                                                     Element
 
 // -----------------------------------------------------------------------------
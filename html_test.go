@@ -11,6 +11,25 @@ import (
 	"github.com/balacode/zr"
 )
 
+// # Functions
+//   Test_html_IsVoidElement_
+//   Test_html_SetClass_
+//
+// # Container Elements
+//   Test_html_Img_
+//
+// # HTML Attributes
+//   Test_html_Attrs_
+//   Test_html_Aria_
+//   Test_html_BoolAttr_
+//   Test_html_Data_
+//   Test_html_Style_
+//   Test_html_textAttributes_
+//
+// # General Wrappers
+//   Test_html_Container_
+//   Test_html_Element_
+
 //  to test all items in html.go use:
 //      go test --run Test_html_
 //
@@ -18,6 +37,22 @@ import (
 //      go test -coverprofile cover.out
 //      go tool cover -html=cover.out
 
+// -----------------------------------------------------------------------------
+// # Functions
+
+// go test --run Test_html_IsVoidElement_
+func Test_html_IsVoidElement_(t *testing.T) {
+	zr.TBegin(t)
+	// IsVoidElement(elementName string) bool
+	//
+	for _, name := range []string{"br", "hr", "img", "input", "meta", "IMG"} {
+		zr.TTrue(t, IsVoidElement(name))
+	}
+	for _, name := range []string{"", "a", "div", "p", "script", "textarea"} {
+		zr.TTrue(t, !IsVoidElement(name))
+	}
+} //                                                    Test_html_IsVoidElement_
+
 // go test --run Test_html_SetClass_
 func Test_html_SetClass_(t *testing.T) {
 	zr.TBegin(t)
@@ -59,4 +94,232 @@ func Test_html_SetClass_(t *testing.T) {
 	zr.TEqual(t, SetClass(false, "AA BB CC", "AA", "BB", "CC", "X"), (""))
 } //                                                         Test_html_SetClass_
 
+// -----------------------------------------------------------------------------
+// # Container Elements
+
+// go test --run Test_html_Img_
+func Test_html_Img_(t *testing.T) {
+	zr.TBegin(t)
+	// Img(content ...interface{}) *Buffer
+	//
+	zr.TEqual(t, Img("a.png", Class("x")).String(),
+		"<img src=\"a.png\" class=\"x\">\r\n")
+	zr.TEqual(t, P(Img("a.png"), "text").String(),
+		"<p><img src=\"a.png\">\r\ntext</p>\r\n")
+} //                                                              Test_html_Img_
+
+// -----------------------------------------------------------------------------
+// # HTML Attributes
+
+// go test --run Test_html_Attrs_
+func Test_html_Attrs_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob Attrs) Attributes() Attributes
+	//
+	zr.TEqual(t, len(Attrs{}.Attributes()), 0)
+	zr.TEqual(t, Attrs{"b": "2", "a": "1"}.Attributes(),
+		Attributes{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}})
+	//
+	// attributes can be spread into containers, sorted by name
+	zr.TEqual(t, Div(Attrs{"id": "menu", "data-open": "1"}, "x").String(),
+		"<div data-open=\"1\" id=\"menu\">\r\nx</div>\r\n")
+	zr.TEqual(t, Span(Class("a"), Attributes{ID("b")}, []Attribute{
+		Role("note")}).String(),
+		"<span class=\"a\" id=\"b\" role=\"note\"></span>\r\n")
+	zr.TEqual(t, Element("input",
+		Attrs{"type": "text", "name": "q"}.Attributes()...).String(),
+		"<input name=\"q\" type=\"text\">\r\n")
+} //                                                            Test_html_Attrs_
+
+// go test --run Test_html_Aria_
+func Test_html_Aria_(t *testing.T) {
+	zr.TBegin(t)
+	// Aria(name, value string) Attribute
+	//
+	zr.TEqual(t, Aria("label", "Close"),
+		Attribute{Name: "aria-label", Value: "Close"})
+	zr.TEqual(t, Aria("aria-hidden", "true"),
+		Attribute{Name: "aria-hidden", Value: "true"})
+	zr.TEqual(t, Aria("label", `"a" & <b>`).Value,
+		"&quot;a&quot; &amp; &lt;b&gt;")
+} //                                                             Test_html_Aria_
+
+// go test --run Test_html_BoolAttr_
+func Test_html_BoolAttr_(t *testing.T) {
+	zr.TBegin(t)
+	// BoolAttr(name string, on bool) Attribute
+	//
+	zr.TEqual(t, BoolAttr("checked", true),
+		Attribute{Name: "checked", Bare: true})
+	zr.TEqual(t, BoolAttr("checked", false), Attribute{})
+	//
+	zr.TEqual(t, Input(Type("checkbox"), BoolAttr("checked", true),
+		BoolAttr("disabled", false), BoolAttr("required", true)).String(),
+		"<input type=\"checkbox\" checked required>\r\n")
+	zr.TEqual(t, Container("select", Name("s"), BoolAttr("multiple", true),
+		Container("option", BoolAttr("selected", true), "a")).String(),
+		"<select name=\"s\" multiple><option selected>a</option>\r\n"+
+			"</select>\r\n")
+} //                                                         Test_html_BoolAttr_
+
+// go test --run Test_html_Data_
+func Test_html_Data_(t *testing.T) {
+	zr.TBegin(t)
+	// Data(name, value string) Attribute
+	//
+	zr.TEqual(t, Data("user-id", "12"),
+		Attribute{Name: "data-user-id", Value: "12"})
+	zr.TEqual(t, Data("data-x", "1"), Attribute{Name: "data-x", Value: "1"})
+	zr.TEqual(t, Span(Data("json", `{"a":"<b>"}`)).String(),
+		"<span data-json=\"{&quot;a&quot;:&quot;&lt;b&gt;&quot;}\">"+
+			"</span>\r\n")
+} //                                                             Test_html_Data_
+
+// go test --run Test_html_Style_
+func Test_html_Style_(t *testing.T) {
+	zr.TBegin(t)
+	// Style(properties ...string) Attribute
+	//
+	zr.TEqual(t, Style(), Attribute{Name: "style"})
+	zr.TEqual(t, Style("color", "red", "margin", "0 auto").Value,
+		"color: red; margin: 0 auto")
+	//
+	// properties are written in order, blank values are left out
+	zr.TEqual(t, Style("width", "1px", "color", " ", "height", "2px").Value,
+		"width: 1px; height: 2px")
+	//
+	// values can't end the property, the rule or the attribute
+	zr.TEqual(t, Style("color", "red; background: url(x)").Value,
+		`color: red\3b  background: url(x)`)
+	zr.TEqual(t, Style("color", "red}body{x:y").Value,
+		`color: red\7d body\7b x:y`)
+	zr.TEqual(t, Style("font-family", `"A" <b>`).Value,
+		"font-family: &quot;A&quot; &lt;b&gt;")
+	//
+	// an odd number of strings is an error: the last one is left out
+	zr.DisableErrors()
+	zr.TEqual(t, Style("color", "red", "margin").Value, "color: red")
+	zr.EnableErrors()
+	//
+	zr.TEqual(t, P(Style("color", "red"), "x").String(),
+		"<p style=\"color: red\">x</p>\r\n")
+} //                                                            Test_html_Style_
+
+// go test --run Test_html_textAttributes_
+func Test_html_textAttributes_(t *testing.T) {
+	zr.TBegin(t)
+	// Alt(text string) Attribute
+	// Class(classList ...string) Attribute
+	// Content(locale string) Attribute
+	// For(id string) Attribute
+	// HREF(href string) Attribute
+	// ID(locale string) Attribute
+	// Lang(locale string) Attribute
+	// Name(locale string) Attribute
+	// OnClick(jsCall string) Attribute
+	// OnLoad(jsCall string) Attribute
+	// Pattern(regex string) Attribute
+	// Placeholder(text string) Attribute
+	// Rel(rel string) Attribute
+	// Role(role string) Attribute
+	// Src(url string) Attribute
+	// TabIndex(index int) Attribute
+	// Target(target string) Attribute
+	// TitleAttr(text string) Attribute
+	// Type(locale string) Attribute
+	// Value(value string) Attribute
+	//
+	for _, it := range []struct {
+		attr   Attribute
+		expect Attribute
+	}{
+		{Alt("A & B"), Attribute{Name: "alt", Value: "A &amp; B"}},
+		{Class("a", `b"c`), Attribute{Name: "class",
+			Value: "a b&quot;c"}},
+		{Content("text/html; charset=<x>"), Attribute{Name: "content",
+			Value: "text/html; charset=&lt;x&gt;"}},
+		{For("name"), Attribute{Name: "for", Value: "name"}},
+		{For(`a"b`), Attribute{Name: "for", Value: "a&quot;b"}},
+		{HREF("/?a=1&b=2"), Attribute{Name: "href",
+			Value: "/?a=1&amp;b=2"}},
+		// values that are already escaped are escaped again
+		{HREF("/?a=1&amp;b=2"), Attribute{Name: "href",
+			Value: "/?a=1&amp;amp;b=2"}},
+		{ID(`x" y`), Attribute{Name: "id", Value: "x&quot; y"}},
+		{Lang("en&"), Attribute{Name: "lang", Value: "en&amp;"}},
+		{Name("a<b>"), Attribute{Name: "name", Value: "a&lt;b&gt;"}},
+		{OnClick(`go("a&b")`), Attribute{Name: "onclick",
+			Value: "go(&quot;a&amp;b&quot;)"}},
+		{OnLoad("init(a>b)"), Attribute{Name: "onload",
+			Value: "init(a&gt;b)"}},
+		{Pattern(`[a-z]{2}|"x"`), Attribute{Name: "pattern",
+			Value: "[a-z]{2}|&quot;x&quot;"}},
+		{Placeholder(`"x"`), Attribute{Name: "placeholder",
+			Value: "&quot;x&quot;"}},
+		{Rel("nofollow"), Attribute{Name: "rel", Value: "nofollow"}},
+		{Rel(`a" onclick="x`), Attribute{Name: "rel",
+			Value: "a&quot; onclick=&quot;x"}},
+		{Role("button"), Attribute{Name: "role", Value: "button"}},
+		{Role("<x>"), Attribute{Name: "role", Value: "&lt;x&gt;"}},
+		{Src("/a.js"), Attribute{Name: "src", Value: "/a.js"}},
+		{Src(`x" onerror="alert(1)`), Attribute{Name: "src",
+			Value: "x&quot; onerror=&quot;alert(1)"}},
+		{TabIndex(-1), Attribute{Name: "tabindex", Value: "-1"}},
+		{TabIndex(0), Attribute{Name: "tabindex", Value: "0"}},
+		{Target("_blank"), Attribute{Name: "target", Value: "_blank"}},
+		{Target(`"`), Attribute{Name: "target", Value: "&quot;"}},
+		{TitleAttr("<Help>"), Attribute{Name: "title",
+			Value: "&lt;Help&gt;"}},
+		{Type(`text" autofocus`), Attribute{Name: "type",
+			Value: "text&quot; autofocus"}},
+		{Value("a\"b"), Attribute{Name: "value", Value: "a&quot;b"}},
+	} {
+		zr.TEqual(t, it.attr, it.expect)
+	}
+	// TabIndex(0) is written, although the index is zero
+	zr.TEqual(t, Div(TabIndex(0)).String(),
+		"<div tabindex=\"0\">\r\n</div>\r\n")
+	zr.TEqual(t, Label(For("q"), TitleAttr("Search"), "Q").String(),
+		"<label for=\"q\" title=\"Search\">Q</label>\r\n")
+	//
+	// values can't break out of the attribute, but Attr() writes
+	// values as they are, for values that are already escaped
+	zr.TEqual(t, A(`/x" onclick="y`, "x").String(),
+		"<a href=\"/x&quot; onclick=&quot;y\">x</a>")
+	zr.TEqual(t, Span(Attr("title", "a &amp; b"), "x").String(),
+		"<span title=\"a &amp; b\">x</span>\r\n")
+} //                                                   Test_html_textAttributes_
+
+// -----------------------------------------------------------------------------
+// # General Wrappers
+
+// go test --run Test_html_Container_
+func Test_html_Container_(t *testing.T) {
+	zr.TBegin(t)
+	// Container(elementName string, content ...interface{}) *Buffer
+	//
+	// attributes with blank values are left out
+	zr.TEqual(t, Container("p", Attr("title", ""), OnClick(""), "x").String(),
+		"<p>x</p>\r\n")
+	//
+	// void elements get no content or end tag
+	zr.TEqual(t, Container("br", Class("x"), "ignored").String(),
+		"<br class=\"x\">\r\n")
+} //                                                        Test_html_Container_
+
+// go test --run Test_html_Element_
+func Test_html_Element_(t *testing.T) {
+	zr.TBegin(t)
+	// Element(elementName string, attributes ...Attribute) *Buffer
+	//
+	zr.TEqual(t, Element("br").String(), "<br>\r\n")
+	zr.TEqual(t, Element("input", Attr("value", ""), OnClick("")).String(),
+		"<input value=\"\">\r\n")
+	//
+	// elements that are not void get an end tag
+	zr.TEqual(t, Element("div", ID("x")).String(),
+		"<div id=\"x\">\r\n</div>\r\n")
+	zr.TEqual(t, Element("span").String(), "<span></span>\r\n")
+} //                                                          Test_html_Element_
+
 // end
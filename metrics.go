@@ -0,0 +1,342 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                  zr-web/[metrics.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	Metrics is a middleware that counts requests and measures how long
+//	they take, and a handler that serves the figures in Prometheus
+//	text format:
+//
+//	var sessions web.Sessions
+//	metrics := &web.Metrics{Sessions: &sessions}
+//	http.Handle("/", metrics.Handler(http.HandlerFunc(mainServe)))
+//	http.Handle("/metrics", metrics)
+//
+//	It serves these metrics:
+//
+//	http_requests_total            requests by route and status
+//	http_request_duration_seconds  latency histogram by route and status
+//	http_sessions_active           number of sessions in Sessions
+//	http_reply_bytes_total         bytes sent by Context.Reply()
+//	http_panics_total              panics in wrapped handlers
+//
+//	Each route and status pair adds a series, so set Route to map
+//	paths to a few route names, e.g. "/user/:id". Without Route, all
+//	requests are counted under the route "other", since a series for
+//	each path would let clients add series without limit.
+//
+//	http_reply_bytes_total is process-wide: it counts the replies of
+//	all Contexts, so every Metrics reports the same figure.
+
+//  Metrics struct
+//
+// # Methods (ob *Metrics)
+//   ) Handler(next http.Handler) http.Handler
+//   ) HandlerFunc(next http.HandlerFunc) http.HandlerFunc
+//   ) ServeHTTP(w http.ResponseWriter, req *http.Request)
+//
+// # Support (File Scope)
+//   (ob *Metrics) observe(route string, status int, seconds float64)
+//   (ob *Metrics) write(w io.Writer)
+//   metricsKey struct
+//   metricsSeries struct
+//   metricsWriter struct
+//   (ob *metricsWriter) Flush()
+//   (ob *metricsWriter) Hijack() (net.Conn, *bufio.ReadWriter, error)
+//   (ob *metricsWriter) Write(data []byte) (int, error)
+//   (ob *metricsWriter) WriteHeader(status int)
+//   metricsFloat(value float64) string
+//   metricsLabel(value string) string
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/balacode/zr"
+)
+
+// DefaultMetricsBuckets are the default upper bounds, in
+// seconds, of the buckets of the latency histogram.
+var DefaultMetricsBuckets = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10,
+}
+
+// MetricsOtherRoute is the route label of requests when Metrics.Route
+// is nil or returns a blank string.
+const MetricsOtherRoute = "other"
+
+// replyBytesTotal counts the bytes sent by Context.Reply() in the
+// whole process; it is not kept per Metrics, since Contexts don't
+// know which Metrics (if any) wraps their handler
+var replyBytesTotal uint64
+
+// Metrics holds the settings and figures of the metrics middleware.
+type Metrics struct {
+	// Sessions, if set, provides the active session count.
+	Sessions *Sessions
+
+	// Route returns the route name of a request, used as the 'route'
+	// label. Return a blank string for requests that don't match a
+	// known route. If nil, all requests get MetricsOtherRoute.
+	Route func(req *http.Request) string
+
+	// Buckets are the upper bounds of the latency histogram's buckets,
+	// in seconds and in ascending order. If nil, DefaultMetricsBuckets
+	// are used. Don't change Buckets after serving the first request.
+	Buckets []float64
+
+	series map[metricsKey]*metricsSeries
+	panics uint64
+	mutex  sync.Mutex
+} //                                                                     Metrics
+
+// metricsKey identifies the series of one route and status
+type metricsKey struct {
+	route  string
+	status int
+} //                                                                  metricsKey
+
+// metricsSeries holds the request count and latency histogram
+// of one route and status. counts[i] is the number of requests
+// that fall in bucket i, not the cumulative count.
+type metricsSeries struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+} //                                                               metricsSeries
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Metrics)
+
+// Handler wraps 'next' so that its requests are counted and timed.
+// A panic in 'next' is counted as a 500 reply and then re-raised.
+func (ob *Metrics) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mw := &metricsWriter{ResponseWriter: w}
+		start := time.Now()
+		var route string
+		if ob.Route != nil {
+			route = ob.Route(req)
+		}
+		if route == "" {
+			route = MetricsOtherRoute
+		}
+		defer func() {
+			seconds := time.Since(start).Seconds()
+			rec := recover()
+			if rec != nil && rec != http.ErrAbortHandler {
+				atomic.AddUint64(&ob.panics, 1)
+				ob.observe(route, http.StatusInternalServerError, seconds)
+				panic(rec)
+			}
+			status := mw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			ob.observe(route, status, seconds)
+			if rec != nil {
+				panic(rec)
+			}
+		}()
+		next.ServeHTTP(mw, req)
+	})
+} //                                                                     Handler
+
+// HandlerFunc is like Handler() but wraps and returns handler functions.
+func (ob *Metrics) HandlerFunc(next http.HandlerFunc) http.HandlerFunc {
+	return ob.Handler(next).ServeHTTP
+} //                                                                 HandlerFunc
+
+// ServeHTTP serves the metrics in Prometheus text format.
+func (ob *Metrics) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var buf bytes.Buffer
+	ob.write(&buf)
+	header := w.Header()
+	header.Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	header.Set("Cache-Control", "no-store")
+	w.Write(buf.Bytes())
+} //                                                                   ServeHTTP
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// observe records a request to 'route' that replied
+// with 'status' and took 'seconds' to serve.
+func (ob *Metrics) observe(route string, status int, seconds float64) {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+	buckets := ob.Buckets
+	if buckets == nil {
+		buckets = DefaultMetricsBuckets
+	}
+	if ob.series == nil {
+		ob.series = map[metricsKey]*metricsSeries{}
+	}
+	key := metricsKey{route: route, status: status}
+	series, exists := ob.series[key]
+	if !exists {
+		series = &metricsSeries{counts: make([]uint64, len(buckets))}
+		ob.series[key] = series
+	}
+	i := sort.SearchFloat64s(buckets, seconds)
+	if i < len(buckets) {
+		series.counts[i]++
+	}
+	series.count++
+	series.sum += seconds
+} //                                                                     observe
+
+// write writes all metrics to 'w' in Prometheus text format.
+func (ob *Metrics) write(w io.Writer) {
+	ob.mutex.Lock()
+	buckets := ob.Buckets
+	if buckets == nil {
+		buckets = DefaultMetricsBuckets
+	}
+	keys := make([]metricsKey, 0, len(ob.series))
+	for key := range ob.series {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		return keys[i].status < keys[j].status
+	})
+	var sb strings.Builder
+	ws := func(a ...string) {
+		for _, s := range a {
+			sb.WriteString(s)
+		}
+	}
+	labels := func(key metricsKey) string {
+		return `route="` + metricsLabel(key.route) +
+			`",status="` + strconv.Itoa(key.status) + `"`
+	}
+	ws("# HELP http_requests_total",
+		" Number of HTTP requests by route and status.\n",
+		"# TYPE http_requests_total counter\n")
+	for _, key := range keys {
+		ws("http_requests_total{", labels(key), "} ",
+			strconv.FormatUint(ob.series[key].count, 10), "\n")
+	}
+	ws("# HELP http_request_duration_seconds",
+		" Time taken to serve HTTP requests.\n",
+		"# TYPE http_request_duration_seconds histogram\n")
+	for _, key := range keys {
+		series := ob.series[key]
+		var cumulative uint64
+		for i, le := range buckets {
+			cumulative += series.counts[i]
+			ws("http_request_duration_seconds_bucket{", labels(key),
+				`,le="`, metricsFloat(le), `"} `,
+				strconv.FormatUint(cumulative, 10), "\n")
+		}
+		ws("http_request_duration_seconds_bucket{", labels(key),
+			`,le="+Inf"} `, strconv.FormatUint(series.count, 10), "\n",
+			"http_request_duration_seconds_sum{", labels(key), "} ",
+			metricsFloat(series.sum), "\n",
+			"http_request_duration_seconds_count{", labels(key), "} ",
+			strconv.FormatUint(series.count, 10), "\n")
+	}
+	ob.mutex.Unlock()
+	//
+	if ob.Sessions != nil {
+		ws("# HELP http_sessions_active Number of active sessions.\n",
+			"# TYPE http_sessions_active gauge\n",
+			"http_sessions_active ", strconv.Itoa(ob.Sessions.Len()), "\n")
+	}
+	ws("# HELP http_reply_bytes_total",
+		" Bytes sent by Context.Reply() in this process.\n",
+		"# TYPE http_reply_bytes_total counter\n",
+		"http_reply_bytes_total ",
+		strconv.FormatUint(atomic.LoadUint64(&replyBytesTotal), 10), "\n",
+		"# HELP http_panics_total Number of panics in HTTP handlers.\n",
+		"# TYPE http_panics_total counter\n",
+		"http_panics_total ",
+		strconv.FormatUint(atomic.LoadUint64(&ob.panics), 10), "\n")
+	_, err := io.WriteString(w, sb.String())
+	if err != nil {
+		zr.Error("Failed writing metrics:", err)
+	}
+} //                                                                       write
+
+// metricsWriter wraps a ResponseWriter to capture the reply's
+// status. It passes on Flush() and Hijack(), so Server-Sent
+// Events and WebSockets work through the middleware.
+type metricsWriter struct {
+	http.ResponseWriter
+	status int
+} //                                                               metricsWriter
+
+// Flush sends buffered data to the client,
+// if the wrapped ResponseWriter supports it.
+func (ob *metricsWriter) Flush() {
+	if flusher, ok := ob.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+} //                                                                       Flush
+
+// Hijack lets the caller take over the connection,
+// if the wrapped ResponseWriter supports it.
+func (ob *metricsWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := ob.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, zr.Error("ResponseWriter does not support Hijack")
+	}
+	if ob.status == 0 {
+		ob.status = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+} //                                                                      Hijack
+
+// Write writes 'data' to the reply, recording status 200 (OK)
+// if the status hasn't been written yet.
+func (ob *metricsWriter) Write(data []byte) (int, error) {
+	if ob.status == 0 {
+		ob.status = http.StatusOK
+	}
+	return ob.ResponseWriter.Write(data)
+} //                                                                       Write
+
+// WriteHeader records and writes the reply's status.
+func (ob *metricsWriter) WriteHeader(status int) {
+	if ob.status == 0 {
+		ob.status = status
+	}
+	ob.ResponseWriter.WriteHeader(status)
+} //                                                                 WriteHeader
+
+// metricsFloat formats 'value' for Prometheus text format.
+func metricsFloat(value float64) string {
+	switch {
+	case math.IsInf(value, +1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+} //                                                                metricsFloat
+
+// metricsLabel escapes 'value' for use as a label value.
+func metricsLabel(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`, `"`, `\"`, "\n", `\n`,
+	).Replace(value)
+} //                                                                metricsLabel
+
+// end
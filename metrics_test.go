@@ -0,0 +1,162 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                             zr-web/[metrics_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Methods (ob *Metrics)
+//   Test_mtrc_Metrics_Handler_
+//   Test_mtrc_Metrics_ServeHTTP_
+
+//  to test all items in metrics.go use:
+//      go test --run Test_mtrc_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Metrics)
+
+// go test --run Test_mtrc_Metrics_Handler_
+func Test_mtrc_Metrics_Handler_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Metrics) Handler(next http.Handler) http.Handler
+	//
+	metrics := &Metrics{
+		Buckets: []float64{0.1, 1},
+		Route: func(req *http.Request) string {
+			switch {
+			case strings.HasPrefix(req.URL.Path, "/user/"):
+				return "/user/:id"
+			case req.URL.Path == "/missing", req.URL.Path == "/panic":
+				return req.URL.Path
+			}
+			return "" // unknown routes are counted as "other"
+		},
+	}
+	handler := metrics.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/panic":
+			panic("boom")
+		default:
+			w.Write([]byte("ok"))
+		}
+	})
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+	serve("/user/1")
+	serve("/user/2")
+	serve("/missing")
+	serve("/x1")
+	serve("/x2")
+	//
+	// panics are counted and re-raised
+	func() {
+		defer func() {
+			zr.TEqual(t, recover(), "boom")
+		}()
+		serve("/panic")
+	}()
+	zr.TEqual(t, atomic.LoadUint64(&metrics.panics), uint64(1))
+	//
+	count := func(route string, status int) uint64 {
+		series := metrics.series[metricsKey{route: route, status: status}]
+		if series == nil {
+			return 0
+		}
+		return series.count
+	}
+	zr.TEqual(t, count("/user/:id", 200), uint64(2))
+	zr.TEqual(t, count("/missing", 404), uint64(1))
+	zr.TEqual(t, count("/panic", 500), uint64(1))
+	zr.TEqual(t, count("other", 200), uint64(2))
+	zr.TEqual(t, len(metrics.series), 4)
+	//
+	// without Route, paths don't add series
+	metrics = &Metrics{}
+	handler = metrics.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	serve("/a")
+	serve("/b")
+	zr.TEqual(t, count("other", 200), uint64(2))
+	zr.TEqual(t, len(metrics.series), 1)
+	//
+	// the wrapped writer still supports Flush() for Server-Sent Events
+	var flushed bool
+	metrics.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, flushed = w.(http.Flusher)
+	})(httptest.NewRecorder(), httptest.NewRequest("GET", "/sse", nil))
+	zr.TTrue(t, flushed)
+} //                                                  Test_mtrc_Metrics_Handler_
+
+// go test --run Test_mtrc_Metrics_ServeHTTP_
+func Test_mtrc_Metrics_ServeHTTP_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Metrics) ServeHTTP(w http.ResponseWriter, req *http.Request)
+	//
+	var sessions Sessions
+	metrics := &Metrics{Sessions: &sessions, Buckets: []float64{0.5, 1}}
+	metrics.observe("/", 200, 0.25)
+	metrics.observe("/", 200, 0.75)
+	metrics.observe("/", 200, 3)
+	metrics.observe(`/a"b`, 404, 0.5)
+	//
+	// create a session and reply through Context
+	before := atomic.LoadUint64(&replyBytesTotal)
+	ctx := NewContext(httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/", nil), &sessions)
+	ctx.Reply([]byte("hello"), "text/plain")
+	//
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	zr.TEqual(t, rec.Header().Get("Content-Type"),
+		"text/plain; version=0.0.4; charset=utf-8")
+	body := rec.Body.String()
+	expect := []string{
+		"# TYPE http_requests_total counter",
+		`http_requests_total{route="/",status="200"} 3`,
+		`http_requests_total{route="/a\"b",status="404"} 1`,
+		"# TYPE http_request_duration_seconds histogram",
+		`http_request_duration_seconds_bucket{route="/",status="200",le="0.5"} 1`,
+		`http_request_duration_seconds_bucket{route="/",status="200",le="1"} 2`,
+		`http_request_duration_seconds_bucket{route="/",status="200",le="+Inf"} 3`,
+		`http_request_duration_seconds_sum{route="/",status="200"} 4`,
+		`http_request_duration_seconds_count{route="/",status="200"} 3`,
+		`http_request_duration_seconds_bucket{route="/a\"b",status="404",le="0.5"} 1`,
+		"# TYPE http_sessions_active gauge",
+		"http_sessions_active 1",
+		"# TYPE http_panics_total counter",
+		"http_panics_total 0",
+	}
+	for _, line := range expect {
+		zr.TTrue(t, strings.Contains(body, line+"\n"))
+	}
+	// the reply counter is global, so other tests may add to it
+	var replied uint64
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "http_reply_bytes_total ") {
+			s := strings.TrimPrefix(line, "http_reply_bytes_total ")
+			replied, _ = strconv.ParseUint(s, 10, 64)
+		}
+	}
+	zr.TTrue(t, replied >= before+5)
+} //                                                Test_mtrc_Metrics_ServeHTTP_
+
+// end
@@ -7,6 +7,8 @@
 // and HTTP session management.
 package web
 
+//go:generate go run elements_gen.go
+
 import (
 	"fmt"
 
@@ -0,0 +1,776 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                     zr-web/[node.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	Node is an HTML element, or a piece of markup, that renders itself
+//	to an io.Writer in a single pass. Container() and the helpers built
+//	on it copy each child's bytes into a new Buffer, so a deeply nested
+//	page is copied once per level. A tree of nodes is only written out
+//	once, when it is rendered, and can be sent straight to the client:
+//
+//	page := web.HTMLNode(
+//		web.ContainerNode("body",
+//			web.ContainerNode("div", web.Class("main"),
+//				web.ContainerNode("p", "Hello"),
+//			),
+//		),
+//	)
+//	ctx.Reply(page, "html") // writes to the http.ResponseWriter
+//
+//	Nodes accept the same content as Container(), and the output
+//	is the same. Nodes and Buffers can be mixed: a *Buffer in a
+//	node is written when the node is rendered, not copied.
+
+//	Nodes form a tree that can be changed until it is rendered, e.g.
+//	to add a class to an element made by another function, or to
+//	insert content into it:
+//
+//	page := layout(content)
+//	page.FindFirst("#menu").AddClass("compact")
+//	page.FindFirst("body").AppendChild(
+//		web.DivNode(web.Class("footer"), web.TextNode("© ACME")),
+//	)
+//
+//	Each helper, e.g. Div(), has an equivalent that returns a node,
+//	e.g. DivNode(). Strings in content are markup, as in Container(),
+//	while TextNode() holds plain text that is escaped when rendered.
+//	A node has only one parent: adding it to another node moves it.
+//	Use Clone() to put a copy of a node in more than one place.
+
+//  Node struct
+//  NodeType type
+//  Attributes type
+//
+// # Constructors
+//   CommentNode(s string) *Node
+//   ContainerNode(elementName string, content ...interface{}) *Node
+//   ElementNode(elementName string, attributes ...Attribute) *Node
+//   FragmentNode(content ...interface{}) *Node
+//   HTMLNode(content ...interface{}) *Node
+//   RawNode(markup string) *Node
+//   TextNode(text string) *Node
+//
+// # Properties (ob *Node)
+//   ) Attr(name string) string
+//   ) Attributes() Attributes
+//   ) Children() []*Node
+//   ) Data() string
+//   ) HasAttr(name string) bool
+//   ) HasClass(class string) bool
+//   ) Parent() *Node
+//   ) Tag() string
+//   ) Type() NodeType
+//
+// # Methods (ob *Node)
+//   ) AddClass(classes ...string) *Node
+//   ) AppendChild(children ...*Node) *Node
+//   ) Clone() *Node
+//   ) Find(query string) []*Node
+//   ) FindFirst(query string) *Node
+//   ) InsertBefore(child, ref *Node) *Node
+//   ) RemoveAttr(name string) *Node
+//   ) RemoveChild(child *Node) *Node
+//   ) RemoveClass(classes ...string) *Node
+//   ) Render() *Buffer
+//   ) SetAttr(name, value string) *Node
+//   ) String() string
+//   ) Walk(fn func(node *Node) bool)
+//   ) WriteTo(w io.Writer) (int64, error)
+//
+// # Methods (ob *Attributes)
+//   ) Del(name string)
+//   ) Get(name string) string
+//   ) Has(name string) bool
+//   ) Merge(attrs ...Attribute)
+//   ) Set(name, value string)
+//
+// # Support (File Scope)
+//   (ob *Node) addContent(content []interface{})
+//   (ob *Node) adopt(child *Node)
+//   (ob *Node) write(wr *nodeWriter)
+//   mergedAttributes(attrs []Attribute) Attributes
+//   nodeWriter struct
+//   (ob *nodeWriter) writeBytes(data []byte)
+//   (ob *nodeWriter) writeString(s string)
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/balacode/zr"
+)
+
+// NodeType specifies the type of a Node.
+type NodeType int
+
+// NodeType values
+const (
+	NodeElement  NodeType = iota // element with a tag and attributes
+	NodeText                     // plain text, escaped when rendered
+	NodeComment                  // comment
+	NodeRaw                      // markup written as it is
+	NodeFragment                 // list of nodes without a tag
+)
+
+// Node is an HTML element, text, comment or piece of markup that
+// can have child nodes and renders itself to an io.Writer.
+type Node struct {
+	kind     NodeType
+	tag      string
+	void     bool // element has no content or closing tag
+	allAttrs bool // write attributes with blank values, like Element()
+	attrs    Attributes
+	parent   *Node
+	children []*Node
+	text     string  // content of a text, comment or raw node
+	data     []byte  // content of a raw node
+	buf      *Buffer // content of a raw node, read when rendered
+	parsed   bool    // made by ParseHTML(): written as it was read
+} //                                                                        Node
+
+// Attributes holds the attributes of an element in the order they are
+// written. Its methods treat it like a map: Set() replaces an existing
+// attribute's value, or adds the attribute at the end.
+type Attributes []Attribute
+
+// containerNewLines lists the containers whose
+// opening tag is followed by a line break
+var containerNewLines = map[string]bool{
+	"article": true, "body": true, "div": true, "head": true,
+	"header": true, "html": true, "nav": true, "ul": true,
+}
+
+// nodeTextEscaper escapes the content of text nodes
+var nodeTextEscaper = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;",
+)
+
+// -----------------------------------------------------------------------------
+// # Constructors
+
+// CommentNode creates a comment node, like Comment().
+func CommentNode(s string) *Node {
+	return &Node{kind: NodeComment, text: s}
+} //                                                                 CommentNode
+
+// ContainerNode composes an arbitrary HTML container element.
+// It is like Container(), but returns a node that is rendered later.
+// Void elements (see IsVoidElement) are written without their
+// content and end tag. Attributes can be given one by one, or in
+// Attributes, []Attribute and Attrs values.
+func ContainerNode(elementName string, content ...interface{}) *Node {
+	ret := &Node{
+		kind: NodeElement,
+		tag:  elementName,
+		void: IsVoidElement(elementName),
+	}
+	ret.addContent(content)
+	return ret
+} //                                                               ContainerNode
+
+// ElementNode composes an HTML element with optional attributes but no
+// content. It is like Element(), but returns a node that is rendered later.
+// Unlike ContainerNode(), it writes attributes with blank values.
+func ElementNode(elementName string, attributes ...Attribute) *Node {
+	return &Node{
+		kind:     NodeElement,
+		tag:      elementName,
+		void:     IsVoidElement(elementName),
+		allAttrs: true,
+		attrs:    mergedAttributes(attributes),
+	}
+} //                                                                 ElementNode
+
+// FragmentNode groups content without enclosing it in an element,
+// like JOIN(). Attributes in 'content' are ignored.
+func FragmentNode(content ...interface{}) *Node {
+	ret := &Node{kind: NodeFragment}
+	ret.addContent(content)
+	return ret
+} //                                                                FragmentNode
+
+// HTMLNode composes a whole HTML document, like HTML().
+func HTMLNode(content ...interface{}) *Node {
+	return FragmentNode(
+		RawNode("<!DOCTYPE html>\r\n"),
+		ContainerNode("html", content...),
+	)
+} //                                                                    HTMLNode
+
+// RawNode creates a node holding markup that is written as it is,
+// like TEXT(). The markup is not escaped.
+func RawNode(markup string) *Node {
+	return &Node{kind: NodeRaw, text: markup}
+} //                                                                     RawNode
+
+// TextNode creates a node holding plain text. When the node is
+// rendered, '&', '<' and '>' in the text are escaped.
+func TextNode(text string) *Node {
+	return &Node{kind: NodeText, text: text}
+} //                                                                    TextNode
+
+// -----------------------------------------------------------------------------
+// # Properties (ob *Node)
+
+// Attr returns the value of the named attribute,
+// or a blank string if the node doesn't have it.
+func (ob *Node) Attr(name string) string {
+	return ob.attrs.Get(name)
+} //                                                                        Attr
+
+// Attributes returns a copy of the node's attributes.
+func (ob *Node) Attributes() Attributes {
+	return append(Attributes{}, ob.attrs...)
+} //                                                                  Attributes
+
+// Children returns a copy of the list of the node's child nodes.
+func (ob *Node) Children() []*Node {
+	return append([]*Node{}, ob.children...)
+} //                                                                    Children
+
+// Data returns the content of a text, comment or raw node,
+// or a blank string for elements and fragments. Entities in
+// text read by ParseHTML() are decoded, e.g. '&amp;' to '&'.
+func (ob *Node) Data() string {
+	switch {
+	case ob.buf != nil:
+		return ob.buf.String()
+	case ob.data != nil:
+		return string(ob.data)
+	case ob.parsed && ob.kind == NodeText:
+		return html.UnescapeString(ob.text)
+	}
+	return ob.text
+} //                                                                        Data
+
+// HasAttr returns true if the node has the named attribute.
+func (ob *Node) HasAttr(name string) bool {
+	return ob.attrs.Has(name)
+} //                                                                     HasAttr
+
+// HasClass returns true if the node's 'class' attribute has 'class'.
+func (ob *Node) HasClass(class string) bool {
+	for _, s := range strings.Fields(ob.attrs.Get("class")) {
+		if s == class {
+			return true
+		}
+	}
+	return false
+} //                                                                    HasClass
+
+// Parent returns the node's parent, or nil if it has none.
+func (ob *Node) Parent() *Node {
+	return ob.parent
+} //                                                                      Parent
+
+// Tag returns the element name of an element node, e.g. "div".
+func (ob *Node) Tag() string {
+	return ob.tag
+} //                                                                         Tag
+
+// Type returns the type of the node.
+func (ob *Node) Type() NodeType {
+	return ob.kind
+} //                                                                        Type
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Node)
+
+// AddClass adds classes to the node's 'class' attribute, unless it
+// already has them, like SetClass(). Returns the node.
+func (ob *Node) AddClass(classes ...string) *Node {
+	class := SetClass(true, ob.attrs.Get("class"), classes...)
+	ob.attrs.Set("class", class)
+	return ob
+} //                                                                    AddClass
+
+// AppendChild adds 'children' at the end of the node's child nodes,
+// removing them from their previous parents. Returns the node.
+func (ob *Node) AppendChild(children ...*Node) *Node {
+	for _, child := range children {
+		if child != nil {
+			ob.adopt(child)
+			ob.children = append(ob.children, child)
+		}
+	}
+	return ob
+} //                                                                 AppendChild
+
+// Clone returns a deep copy of the node, without a parent.
+func (ob *Node) Clone() *Node {
+	ret := *ob
+	ret.parent = nil
+	ret.attrs = append(Attributes(nil), ob.attrs...)
+	ret.children = nil
+	for _, child := range ob.children {
+		clone := child.Clone()
+		clone.parent = &ret
+		ret.children = append(ret.children, clone)
+	}
+	return &ret
+} //                                                                       Clone
+
+// Find returns the elements under the node that match 'query', in
+// document order. 'query' is written like a CSS selector, e.g.
+// "li.active", "div#menu > a[href]" or "h1, h2" (see query.go).
+// A query that is not valid matches nothing; use Select() to
+// get the error.
+func (ob *Node) Find(query string) []*Node {
+	ret, _ := ob.Select(query)
+	return ret
+} //                                                                        Find
+
+// FindFirst returns the first element under the node that
+// matches 'query' (see Find), or nil if there is none.
+func (ob *Node) FindFirst(query string) *Node {
+	var ret *Node
+	q, err := parseNodeQuery(query)
+	if err != nil {
+		return nil
+	}
+	for _, child := range ob.children {
+		child.Walk(func(node *Node) bool {
+			if ret == nil && node.matches(q) {
+				ret = node
+			}
+			return ret == nil
+		})
+		if ret != nil {
+			break
+		}
+	}
+	return ret
+} //                                                                   FindFirst
+
+// InsertBefore inserts 'child' before child node 'ref', or at the end
+// if 'ref' is nil or not a child of the node. Returns the node.
+func (ob *Node) InsertBefore(child, ref *Node) *Node {
+	if child == nil || child == ref {
+		return ob
+	}
+	ob.adopt(child)
+	for i, it := range ob.children {
+		if it == ref {
+			ob.children = append(ob.children[:i],
+				append([]*Node{child}, ob.children[i:]...)...)
+			return ob
+		}
+	}
+	ob.children = append(ob.children, child)
+	return ob
+} //                                                                InsertBefore
+
+// RemoveAttr removes the named attribute. Returns the node.
+func (ob *Node) RemoveAttr(name string) *Node {
+	ob.attrs.Del(name)
+	return ob
+} //                                                                  RemoveAttr
+
+// RemoveChild removes 'child' from the node's child nodes.
+// Returns the node.
+func (ob *Node) RemoveChild(child *Node) *Node {
+	for i, it := range ob.children {
+		if it == child {
+			ob.children = append(ob.children[:i], ob.children[i+1:]...)
+			child.parent = nil
+			break
+		}
+	}
+	return ob
+} //                                                                 RemoveChild
+
+// RemoveClass removes classes from the node's 'class' attribute,
+// like SetClass(). Returns the node.
+func (ob *Node) RemoveClass(classes ...string) *Node {
+	class := SetClass(false, ob.attrs.Get("class"), classes...)
+	if class == "" {
+		ob.attrs.Del("class")
+		return ob
+	}
+	ob.attrs.Set("class", class)
+	return ob
+} //                                                                 RemoveClass
+
+// Render renders the node into a new Buffer.
+func (ob *Node) Render() *Buffer {
+	ret := NewBuffer(64)
+	ob.WriteTo(&ret.html)
+	return &ret
+} //                                                                      Render
+
+// SetAttr sets the value of the named attribute. Like in Attr(),
+// 'value' is written as it is. Returns the node.
+func (ob *Node) SetAttr(name, value string) *Node {
+	ob.attrs.Set(name, value)
+	return ob
+} //                                                                     SetAttr
+
+// String renders the node to a string and
+// implements the fmt.Stringer interface.
+func (ob *Node) String() string {
+	var sb bytes.Buffer
+	ob.WriteTo(&sb)
+	return sb.String()
+} //                                                                      String
+
+// Walk calls 'fn' for the node and each node under it, in document
+// order. If 'fn' returns false, Walk skips the node's children.
+func (ob *Node) Walk(fn func(node *Node) bool) {
+	if !fn(ob) {
+		return
+	}
+	for _, child := range ob.children {
+		child.Walk(fn)
+	}
+} //                                                                        Walk
+
+// WriteTo renders the node to 'w' and implements the io.WriterTo
+// interface. It returns the number of bytes written and the
+// first write error, after which it stops writing.
+func (ob *Node) WriteTo(w io.Writer) (int64, error) {
+	wr := nodeWriter{w: w}
+	ob.write(&wr)
+	return wr.n, wr.err
+} //                                                                     WriteTo
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Attributes)
+
+// Del removes all attributes with the specified name.
+func (ob *Attributes) Del(name string) {
+	list := (*ob)[:0]
+	for _, attr := range *ob {
+		if attr.Name != name {
+			list = append(list, attr)
+		}
+	}
+	*ob = list
+} //                                                                         Del
+
+// Get returns the value of the first attribute with
+// the specified name, or a blank string if none.
+func (ob Attributes) Get(name string) string {
+	for _, attr := range ob {
+		if attr.Name == name {
+			return attr.Value
+		}
+	}
+	return ""
+} //                                                                         Get
+
+// Has returns true if there is an attribute with the specified name.
+func (ob Attributes) Has(name string) bool {
+	for _, attr := range ob {
+		if attr.Name == name {
+			return true
+		}
+	}
+	return false
+} //                                                                         Has
+
+// Merge adds 'attrs' to the attributes, merging attributes that have
+// the same name: a later value replaces an earlier one, keeping its
+// position, except that 'class' values are combined as by SetClass(),
+// and 'style' values are joined with "; ". Attributes without a name
+// are skipped. Conflicting values are reported in strict mode, see
+// SetStrictAttributes(). The element helpers merge their attributes
+// this way, so e.g. Div(Class("a"), Class("b")) gives class="a b".
+func (ob *Attributes) Merge(attrs ...Attribute) {
+	for _, attr := range attrs {
+		if attr.Name == "" {
+			continue
+		}
+		i := 0
+		for i < len(*ob) && (*ob)[i].Name != attr.Name {
+			i++
+		}
+		if i == len(*ob) {
+			*ob = append(*ob, attr)
+			continue
+		}
+		old := &(*ob)[i]
+		switch attr.Name {
+		case "class":
+			old.Value = SetClass(true, old.Value,
+				strings.Fields(attr.Value)...)
+		case "style":
+			value := strings.TrimRight(strings.TrimSpace(old.Value), ";")
+			add := strings.TrimSpace(attr.Value)
+			if value != "" && add != "" {
+				value += "; "
+			}
+			old.Value = value + add
+		default:
+			if strictAttributes &&
+				(old.Value != attr.Value || old.Bare != attr.Bare) {
+				zr.Error("Attribute", attr.Name, "given twice:",
+					old.Value, "and", attr.Value)
+			}
+			old.Value = attr.Value
+		}
+		old.Bare = attr.Bare && old.Value == ""
+	}
+} //                                                                       Merge
+
+// Set sets the value of the named attribute, keeping its position.
+// Other attributes with the same name are removed. A new attribute
+// is added at the end.
+func (ob *Attributes) Set(name, value string) {
+	found := false
+	list := (*ob)[:0]
+	for _, attr := range *ob {
+		if attr.Name == name {
+			if found {
+				continue
+			}
+			attr.Value, attr.Bare = value, false
+			found = true
+		}
+		list = append(list, attr)
+	}
+	if !found {
+		list = append(list, Attribute{Name: name, Value: value})
+	}
+	*ob = list
+} //                                                                         Set
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// addContent adds the attributes and child content in 'content'
+// to the node. It accepts the same types as Container().
+func (ob *Node) addContent(content []interface{}) {
+	raw := func(s string) {
+		ob.AppendChild(&Node{kind: NodeRaw, text: s})
+	}
+	rawData := func(data []byte) {
+		ob.AppendChild(&Node{kind: NodeRaw, data: data})
+	}
+	rawBuf := func(buf *Buffer) {
+		ob.AppendChild(&Node{kind: NodeRaw, buf: buf})
+	}
+	for i, val := range content {
+		switch val := val.(type) {
+		case Attribute:
+			if ob.kind == NodeElement {
+				ob.attrs.Merge(val)
+			}
+		case Attributes:
+			if ob.kind == NodeElement {
+				ob.attrs.Merge(val...)
+			}
+		case []Attribute:
+			if ob.kind == NodeElement {
+				ob.attrs.Merge(val...)
+			}
+		case Attrs:
+			if ob.kind == NodeElement {
+				ob.attrs.Merge(val.Attributes()...)
+			}
+		case *Node:
+			ob.AppendChild(val)
+		case []*Node:
+			ob.AppendChild(val...)
+		case []byte:
+			rawData(val)
+		case *[]byte:
+			if val != nil {
+				rawData(*val)
+			}
+		// web.Buffer
+		case Buffer:
+			rawData(val.Bytes())
+		case *Buffer:
+			if val != nil {
+				rawBuf(val)
+			}
+		case []Buffer:
+			for _, val := range val {
+				rawData(val.Bytes())
+			}
+		case []*Buffer:
+			for _, val := range val {
+				if val != nil {
+					rawBuf(val)
+				}
+			}
+		// bytes.Buffer
+		case bytes.Buffer:
+			rawData(val.Bytes())
+		case *bytes.Buffer:
+			if val != nil {
+				rawData(val.Bytes())
+			}
+		// numbers
+		case float64, float32:
+			raw(fmt.Sprintf("%f", val))
+		case int, int8, int16, int32, int64,
+			uint, uint64, uint32, uint16, uint8:
+			raw(fmt.Sprintf("%d", val))
+		// strings
+		case string:
+			raw(val)
+		case []string:
+			for _, s := range val {
+				raw(s)
+			}
+		case fmt.Stringer:
+			raw(val.String())
+		default:
+			zr.Error("Content item", i, "of type",
+				reflect.TypeOf(val), "not handled")
+		}
+	}
+} //                                                                  addContent
+
+// adopt removes 'child' from its current parent and makes the node its
+// parent. The caller adds 'child' to the node's list of child nodes.
+func (ob *Node) adopt(child *Node) {
+	if child.parent != nil {
+		child.parent.RemoveChild(child)
+	}
+	child.parent = ob
+} //                                                                       adopt
+
+// write renders the node to 'wr'.
+func (ob *Node) write(wr *nodeWriter) {
+	switch ob.kind {
+	case NodeRaw:
+		switch {
+		case ob.buf != nil:
+			wr.writeBytes(ob.buf.Bytes())
+		case ob.data != nil:
+			wr.writeBytes(ob.data)
+		default:
+			wr.writeString(ob.text)
+		}
+		return
+	case NodeText:
+		if ob.parsed {
+			// the next node could turn a literal '<' or '</' at the
+			// end into a tag, e.g. '<' + 'A' once '</>' is dropped
+			// from '<</>A', so that '<' is escaped
+			text := ob.text
+			if i := strings.LastIndexByte(text, '<'); i != -1 &&
+				(i == len(text)-1 || text[i+1:] == "/") {
+				wr.writeString(text[:i])
+				wr.writeString("&lt;")
+				text = text[i+1:]
+			}
+			wr.writeString(text)
+			return
+		}
+		wr.writeString(nodeTextEscaper.Replace(ob.text))
+		return
+	case NodeComment:
+		wr.writeString("<!--")
+		wr.writeString(ob.text)
+		wr.writeString("-->")
+		if !ob.parsed {
+			wr.writeString("\r\n")
+		}
+		return
+	case NodeFragment:
+		for _, child := range ob.children {
+			child.write(wr)
+		}
+		return
+	}
+	wr.writeString("<")
+	wr.writeString(ob.tag)
+	var bare bool // true if the last attribute written was bare
+	for _, attr := range ob.attrs {
+		if attr.Name == "" ||
+			!attr.Bare && attr.Value == "" && !ob.allAttrs && !ob.parsed {
+			continue
+		}
+		if bare && strings.HasPrefix(attr.Name, "=") {
+			// a parsed name can start with '=', as in '<p a/=b>',
+			// but after a bare name it would be read as a value
+			wr.writeString(`=""`)
+		}
+		bare = attr.Bare
+		wr.writeString(" ")
+		wr.writeString(attr.Name)
+		if !attr.Bare {
+			wr.writeString(`="`)
+			wr.writeString(attr.Value)
+			wr.writeString(`"`)
+		}
+	}
+	if ob.parsed {
+		// parsed elements keep the white space they were read with
+		wr.writeString(">")
+		if ob.void {
+			return
+		}
+		for _, child := range ob.children {
+			child.write(wr)
+		}
+		wr.writeString("</")
+		wr.writeString(ob.tag)
+		wr.writeString(">")
+		return
+	}
+	if ob.void {
+		wr.writeString(">\r\n")
+		return
+	}
+	wr.writeString(">")
+	if containerNewLines[ob.tag] {
+		wr.writeString("\r\n")
+	}
+	for _, child := range ob.children {
+		child.write(wr)
+	}
+	wr.writeString("</")
+	wr.writeString(ob.tag)
+	wr.writeString(">")
+	if ob.tag != "a" {
+		wr.writeString("\r\n")
+	}
+} //                                                                       write
+
+// mergedAttributes returns a new list of 'attrs' merged by Merge().
+func mergedAttributes(attrs []Attribute) Attributes {
+	var ret Attributes
+	ret.Merge(attrs...)
+	return ret
+} //                                                            mergedAttributes
+
+// nodeWriter writes to an io.Writer, counting the bytes
+// written and stopping at the first error.
+type nodeWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+} //                                                                  nodeWriter
+
+// writeBytes writes 'data' unless an earlier write failed.
+func (ob *nodeWriter) writeBytes(data []byte) {
+	if ob.err != nil || len(data) == 0 {
+		return
+	}
+	n, err := ob.w.Write(data)
+	ob.n += int64(n)
+	ob.err = err
+} //                                                                  writeBytes
+
+// writeString writes 's' unless an earlier write failed.
+func (ob *nodeWriter) writeString(s string) {
+	if ob.err != nil || s == "" {
+		return
+	}
+	n, err := io.WriteString(ob.w, s)
+	ob.n += int64(n)
+	ob.err = err
+} //                                                                 writeString
+
+// end
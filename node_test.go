@@ -0,0 +1,470 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                zr-web/[node_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Constructors
+//   Test_node_CommentNode_
+//   Test_node_ContainerNode_
+//   Test_node_ElementNode_
+//   Test_node_FragmentNode_
+//   Test_node_HTMLNode_
+//   Test_node_RawNode_
+//   Test_node_TextNode_
+//
+// # Methods (ob *Node)
+//   Test_node_Node_AddClass_
+//   Test_node_Node_AppendChild_
+//   Test_node_Node_Clone_
+//   Test_node_Node_Find_
+//   Test_node_Node_InsertBefore_
+//   Test_node_Node_RemoveChild_
+//   Test_node_Node_SetAttr_
+//   Test_node_Node_WriteTo_
+//
+// # Methods (ob *Attributes)
+//   Test_node_Attributes_Merge_
+//   Test_node_Attributes_Merge_strict_
+//   Test_node_Attributes_Set_
+//
+// # Benchmarks
+//   Benchmark_node_Container_
+//   Benchmark_node_Node_WriteTo_
+
+//  to test all items in node.go use:
+//      go test --run Test_node_
+//
+//  to run the benchmarks use:
+//      go test --run NONE --bench _node_ --benchmem
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Constructors
+
+// go test --run Test_node_CommentNode_
+func Test_node_CommentNode_(t *testing.T) {
+	zr.TBegin(t)
+	// CommentNode(s string) *Node
+	//
+	zr.TEqual(t, CommentNode("note").String(), Comment("note").String())
+	zr.TEqual(t, CommentNode("note").Type(), NodeComment)
+	zr.TEqual(t, CommentNode("note").Data(), "note")
+} //                                                      Test_node_CommentNode_
+
+// go test --run Test_node_ContainerNode_
+func Test_node_ContainerNode_(t *testing.T) {
+	zr.TBegin(t)
+	// ContainerNode(elementName string, content ...interface{}) *Node
+	//
+	// renders exactly like Container()
+	test := func(elementName string, content ...interface{}) {
+		expect := Container(elementName, content...).String()
+		zr.TEqual(t, ContainerNode(elementName, content...).String(), expect)
+	}
+	test("p")
+	test("div")
+	test("a", HREF("/"), "home")
+	test("p", Class("a"), Attr("title", ""), "x", 1, 2.5)
+	test("div", P("a"), []byte("<b>"), JOIN(Span("c")), []string{"d", "e"})
+	test("ul", ContainerNode("li", "1"), []*Node{RawNode("2")})
+	//
+	zr.TEqual(t, ContainerNode("div", Class("x"), "y").String(),
+		"<div class=\"x\">\r\ny</div>\r\n")
+	//
+	// a *Buffer is rendered when the node is rendered, not copied
+	buf := P("a")
+	node := ContainerNode("div", buf)
+	buf.WriteString("b")
+	zr.TEqual(t, node.String(), "<div>\r\n<p>a</p>\r\nb</div>\r\n")
+} //                                                    Test_node_ContainerNode_
+
+// go test --run Test_node_ElementNode_
+func Test_node_ElementNode_(t *testing.T) {
+	zr.TBegin(t)
+	// ElementNode(elementName string, attributes ...Attribute) *Node
+	//
+	zr.TEqual(t, ElementNode("br").String(), "<br>\r\n")
+	zr.TEqual(t,
+		ElementNode("input", Type("text"), Attr("value", "")).String(),
+		Element("input", Type("text"), Attr("value", "")).String())
+} //                                                      Test_node_ElementNode_
+
+// go test --run Test_node_FragmentNode_
+func Test_node_FragmentNode_(t *testing.T) {
+	zr.TBegin(t)
+	// FragmentNode(content ...interface{}) *Node
+	//
+	zr.TEqual(t, FragmentNode().String(), "")
+	zr.TEqual(t, FragmentNode(Class("x"), "a", P("b"), RawNode("c")).String(),
+		"a<p>b</p>\r\nc")
+} //                                                     Test_node_FragmentNode_
+
+// go test --run Test_node_HTMLNode_
+func Test_node_HTMLNode_(t *testing.T) {
+	zr.TBegin(t)
+	// HTMLNode(content ...interface{}) *Node
+	//
+	zr.TEqual(t, HTMLNode(Lang("en"), Head(Title("T")), Body()).String(),
+		string(HTML(Lang("en"), Head(Title("T")), Body())))
+} //                                                         Test_node_HTMLNode_
+
+// go test --run Test_node_RawNode_
+func Test_node_RawNode_(t *testing.T) {
+	zr.TBegin(t)
+	// RawNode(markup string) *Node
+	//
+	zr.TEqual(t, RawNode("").String(), "")
+	zr.TEqual(t, RawNode("<b>a & b</b>").String(), "<b>a & b</b>")
+} //                                                          Test_node_RawNode_
+
+// go test --run Test_node_TextNode_
+func Test_node_TextNode_(t *testing.T) {
+	zr.TBegin(t)
+	// TextNode(text string) *Node
+	//
+	zr.TEqual(t, TextNode("").String(), "")
+	zr.TEqual(t, TextNode("<b>a & b</b>").String(),
+		"&lt;b&gt;a &amp; b&lt;/b&gt;")
+	zr.TEqual(t, TextNode("<b>").Data(), "<b>")
+	zr.TEqual(t, ContainerNode("p", TextNode("1 < 2")).String(),
+		"<p>1 &lt; 2</p>\r\n")
+} //                                                         Test_node_TextNode_
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Node)
+
+// failingWriter fails after writing 'limit' bytes
+type failingWriter struct {
+	limit int
+	buf   bytes.Buffer
+} //                                                               failingWriter
+
+// Write writes 'data', or fails if the limit is reached.
+func (ob *failingWriter) Write(data []byte) (int, error) {
+	if ob.buf.Len()+len(data) > ob.limit {
+		return 0, errors.New("limit reached")
+	}
+	return ob.buf.Write(data)
+} //                                                                       Write
+
+// go test --run Test_node_Node_AddClass_
+func Test_node_Node_AddClass_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Node) AddClass(classes ...string) *Node
+	// (ob *Node) RemoveClass(classes ...string) *Node
+	//
+	node := ContainerNode("p", ID("x"), "a")
+	zr.TEqual(t, node.AddClass("A", "B").String(),
+		"<p id=\"x\" class=\"A B\">a</p>\r\n")
+	zr.TEqual(t, node.AddClass("B", "C").Attr("class"), "A B C")
+	zr.TTrue(t, node.HasClass("B"))
+	zr.TEqual(t, node.RemoveClass("B").Attr("class"), "A C")
+	zr.TTrue(t, !node.HasClass("B"))
+	//
+	// removing the last class removes the attribute
+	zr.TEqual(t, node.RemoveClass("A", "C").String(),
+		"<p id=\"x\">a</p>\r\n")
+	zr.TTrue(t, !node.HasAttr("class"))
+} //                                                    Test_node_Node_AddClass_
+
+// go test --run Test_node_Node_AppendChild_
+func Test_node_Node_AppendChild_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Node) AppendChild(children ...*Node) *Node
+	//
+	ul := UlNode(LiNode("1"))
+	li := LiNode("2")
+	zr.TEqual(t, ul.AppendChild(li, nil).String(),
+		"<ul>\r\n<li>1</li>\r\n<li>2</li>\r\n</ul>\r\n")
+	zr.TTrue(t, li.Parent() == ul)
+	zr.TEqual(t, len(ul.Children()), 2)
+	//
+	// appending a node to another parent moves it
+	ol := ContainerNode("ol")
+	ol.AppendChild(li)
+	zr.TTrue(t, li.Parent() == ol)
+	zr.TEqual(t, len(ul.Children()), 1)
+	zr.TEqual(t, ol.String(), "<ol><li>2</li>\r\n</ol>\r\n")
+} //                                                 Test_node_Node_AppendChild_
+
+// go test --run Test_node_Node_Clone_
+func Test_node_Node_Clone_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Node) Clone() *Node
+	//
+	div := DivNode(Class("a"), PNode("x"))
+	clone := div.Clone()
+	zr.TEqual(t, clone.String(), div.String())
+	zr.TTrue(t, clone.Parent() == nil)
+	zr.TTrue(t, clone.Children()[0].Parent() == clone)
+	//
+	// changing the clone doesn't change the original
+	clone.AddClass("b")
+	clone.FindFirst("p").SetAttr("id", "y")
+	zr.TEqual(t, div.String(), "<div class=\"a\">\r\n<p>x</p>\r\n</div>\r\n")
+} //                                                       Test_node_Node_Clone_
+
+// go test --run Test_node_Node_Find_
+func Test_node_Node_Find_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Node) Find(query string) []*Node
+	// (ob *Node) FindFirst(query string) *Node
+	//
+	page := DivNode(ID("main"),
+		UlNode(ID("menu"),
+			LiNode(Class("item", "active"), "1"),
+			LiNode(Class("item"), "2"),
+		),
+		PNode(Class("item"), "3"),
+	)
+	texts := func(nodes []*Node) string {
+		var ret []string
+		for _, node := range nodes {
+			ret = append(ret, node.Children()[0].Data())
+		}
+		return strings.Join(ret, ",")
+	}
+	zr.TEqual(t, texts(page.Find("li")), "1,2")
+	zr.TEqual(t, texts(page.Find(".item")), "1,2,3")
+	zr.TEqual(t, texts(page.Find("li.active")), "1")
+	zr.TEqual(t, texts(page.Find(".item.active")), "1")
+	zr.TEqual(t, texts(page.Find("p.item")), "3")
+	zr.TEqual(t, len(page.Find("#menu")), 1)
+	zr.TEqual(t, len(page.Find("ul#menu.x")), 0)
+	zr.TEqual(t, len(page.Find("")), 0)
+	//
+	// the node itself is not included
+	zr.TEqual(t, len(page.Find("#main")), 0)
+	//
+	zr.TEqual(t, page.FindFirst("#menu").Tag(), "ul")
+	zr.TEqual(t, page.FindFirst(".item").Attr("class"), "item active")
+	zr.TTrue(t, page.FindFirst("table") == nil)
+	//
+	// queries are written like CSS selectors (see query.go)
+	zr.TEqual(t, texts(page.Find("#main > .item")), "3")
+	zr.TEqual(t, texts(page.Find("ul li, p")), "1,2,3")
+	zr.TEqual(t, texts(page.Find("[class='item']")), "2,3")
+	zr.TEqual(t, len(page.Find("ul >")), 0)
+	zr.TTrue(t, page.FindFirst("li[") == nil)
+} //                                                        Test_node_Node_Find_
+
+// go test --run Test_node_Node_InsertBefore_
+func Test_node_Node_InsertBefore_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Node) InsertBefore(child, ref *Node) *Node
+	//
+	a, b, c := RawNode("a"), RawNode("b"), RawNode("c")
+	node := FragmentNode(a, c)
+	zr.TEqual(t, node.InsertBefore(b, c).String(), "abc")
+	zr.TEqual(t, node.InsertBefore(c, a).String(), "cab")
+	zr.TEqual(t, node.InsertBefore(RawNode("d"), nil).String(), "cabd")
+	zr.TTrue(t, b.Parent() == node)
+} //                                                Test_node_Node_InsertBefore_
+
+// go test --run Test_node_Node_RemoveChild_
+func Test_node_Node_RemoveChild_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Node) RemoveChild(child *Node) *Node
+	//
+	p := PNode("x")
+	div := DivNode(p, SpanNode("y"))
+	zr.TEqual(t, div.RemoveChild(p).String(),
+		"<div>\r\n<span>y</span>\r\n</div>\r\n")
+	zr.TTrue(t, p.Parent() == nil)
+	zr.TEqual(t, div.RemoveChild(p).String(),
+		"<div>\r\n<span>y</span>\r\n</div>\r\n")
+} //                                                 Test_node_Node_RemoveChild_
+
+// go test --run Test_node_Node_SetAttr_
+func Test_node_Node_SetAttr_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Node) SetAttr(name, value string) *Node
+	// (ob *Node) RemoveAttr(name string) *Node
+	//
+	node := ANode("/a", "x")
+	zr.TEqual(t, node.SetAttr("target", "_blank").SetAttr("href", "/b").
+		String(), "<a href=\"/b\" target=\"_blank\">x</a>")
+	zr.TEqual(t, node.Attr("target"), "_blank")
+	zr.TTrue(t, node.HasAttr("href"))
+	zr.TEqual(t, node.RemoveAttr("href").String(),
+		"<a target=\"_blank\">x</a>")
+	zr.TTrue(t, !node.HasAttr("href"))
+	zr.TEqual(t, node.Attr("href"), "")
+} //                                                     Test_node_Node_SetAttr_
+
+// go test --run Test_node_Node_WriteTo_
+func Test_node_Node_WriteTo_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Node) WriteTo(w io.Writer) (int64, error)
+	//
+	node := ContainerNode("div", ContainerNode("p", "a"), RawNode("b"))
+	var buf bytes.Buffer
+	n, err := node.WriteTo(&buf)
+	zr.TEqual(t, err, nil)
+	zr.TEqual(t, n, int64(buf.Len()))
+	zr.TEqual(t, buf.String(), "<div>\r\n<p>a</p>\r\nb</div>\r\n")
+	zr.TEqual(t, node.Render().String(), buf.String())
+	//
+	// writing stops at the first error
+	fw := &failingWriter{limit: 10}
+	n, err = node.WriteTo(fw)
+	zr.TEqual(t, err.Error(), "limit reached")
+	zr.TEqual(t, n, int64(fw.buf.Len()))
+	zr.TEqual(t, fw.buf.String(), "<div>\r\n<p>")
+} //                                                     Test_node_Node_WriteTo_
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Attributes)
+
+// go test --run Test_node_Attributes_Merge_
+func Test_node_Attributes_Merge_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Attributes) Merge(attrs ...Attribute)
+	//
+	var attrs Attributes
+	attrs.Merge(ID("a"), Attribute{}, Class("x"), Style("color", "red"))
+	attrs.Merge(ID("b"), Class("y x"), Style("margin", "0"), Class("z"))
+	zr.TEqual(t, attrs, Attributes{
+		ID("b"), Class("x y z"), Attr("style", "color: red; margin: 0"),
+	})
+	//
+	// bare attributes are replaced like the others
+	attrs = Attributes{BoolAttr("checked", true)}
+	attrs.Merge(Attr("checked", "checked"))
+	zr.TEqual(t, attrs, Attributes{Attr("checked", "checked")})
+	attrs.Merge(BoolAttr("checked", true))
+	zr.TEqual(t, attrs, Attributes{BoolAttr("checked", true)})
+	//
+	// helpers merge their attributes
+	test := func(got *Buffer, expect string) {
+		zr.TEqual(t, got.String(), expect)
+	}
+	test(Div(Class("a"), Class("b", "a"), "x"),
+		"<div class=\"a b\">\r\nx</div>\r\n")
+	test(P(ID("a"), Style("color", "red"), Style("margin", "0"), ID("b")),
+		"<p id=\"b\" style=\"color: red; margin: 0\"></p>\r\n")
+	test(Span(Attr("style", "color: red;"), Style("margin", "0")),
+		"<span style=\"color: red; margin: 0\"></span>\r\n")
+	test(Element("input", Name("a"), Type("text"), Name("b")),
+		"<input name=\"b\" type=\"text\">\r\n")
+	test(A("/b", HREF("/a"), Class("x")),
+		"<a href=\"/b\" class=\"x\"></a>")
+	test(NAV("/b", HREF("/a"), OnClick("x()")),
+		"<a href=\"#\" onclick=\"zr.go('/b')\"></a>")
+	zr.TEqual(t, DivNode(Class("a"), Class("b")).Attr("class"), "a b")
+} //                                                 Test_node_Attributes_Merge_
+
+// go test --run Test_node_Attributes_Merge_strict_
+func Test_node_Attributes_Merge_strict_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Attributes) Merge(attrs ...Attribute)
+	//
+	// conflicts are only reported in strict mode
+	zr.TTrue(t, !StrictAttributes())
+	SetStrictAttributes(true)
+	defer SetStrictAttributes(false)
+	zr.TTrue(t, StrictAttributes())
+	zr.DisableErrors()
+	defer zr.EnableErrors()
+	//
+	test := func(expectErrors int, attrs ...Attribute) {
+		count := zr.GetErrorCount()
+		Div(attrs)
+		zr.TEqual(t, zr.GetErrorCount()-count, expectErrors)
+	}
+	test(0, ID("a"), Class("b"))
+	test(0, ID("a"), ID("a"))
+	test(0, Class("a"), Class("b"), Style("a", "1"), Style("b", "2"))
+	test(1, ID("a"), ID("b"))
+	test(2, Name("a"), Name("b"), Name("c"))
+	test(1, BoolAttr("checked", true), Attr("checked", ""))
+	//
+	// A() reports an HREF() in its content that differs from 'href'
+	count := zr.GetErrorCount()
+	A("/b", HREF("/a"))
+	A("/b", HREF("/b"))
+	zr.TEqual(t, zr.GetErrorCount()-count, 1)
+} //                                          Test_node_Attributes_Merge_strict_
+
+// go test --run Test_node_Attributes_Set_
+func Test_node_Attributes_Set_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Attributes) Set(name, value string)
+	//
+	attrs := Attributes{Attr("a", "1"), Attr("b", "2"), Attr("a", "3")}
+	attrs.Set("a", "4")
+	zr.TEqual(t, attrs, Attributes{Attr("a", "4"), Attr("b", "2")})
+	attrs.Set("c", "5")
+	zr.TEqual(t, attrs,
+		Attributes{Attr("a", "4"), Attr("b", "2"), Attr("c", "5")})
+	attrs.Del("b")
+	zr.TEqual(t, attrs, Attributes{Attr("a", "4"), Attr("c", "5")})
+	zr.TTrue(t, attrs.Has("c") && !attrs.Has("b"))
+	zr.TEqual(t, attrs.Get("c"), "5")
+} //                                                   Test_node_Attributes_Set_
+
+// -----------------------------------------------------------------------------
+// # Benchmarks
+
+// benchmarkDepth is the nesting depth of the benchmark pages
+const benchmarkDepth = 20
+
+// benchmarkBuffers builds a page of nested elements with Container()
+func benchmarkBuffers(depth int) *Buffer {
+	if depth == 0 {
+		return P(Class("leaf"), "Lorem ipsum dolor sit amet")
+	}
+	return Div(Class("level"),
+		benchmarkBuffers(depth-1),
+		Span("text"),
+		benchmarkBuffers(0),
+	)
+} //                                                            benchmarkBuffers
+
+// benchmarkNodes builds the same page as benchmarkBuffers() with nodes
+func benchmarkNodes(depth int) *Node {
+	if depth == 0 {
+		return ContainerNode("p", Class("leaf"), "Lorem ipsum dolor sit amet")
+	}
+	return ContainerNode("div", Class("level"),
+		benchmarkNodes(depth-1),
+		ContainerNode("span", "text"),
+		benchmarkNodes(0),
+	)
+} //                                                              benchmarkNodes
+
+// go test --run NONE --bench Benchmark_node_Container_ --benchmem
+func Benchmark_node_Container_(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		benchmarkBuffers(benchmarkDepth).WriteTo(ioutil.Discard)
+	}
+} //                                                   Benchmark_node_Container_
+
+// go test --run NONE --bench Benchmark_node_Node_WriteTo_ --benchmem
+func Benchmark_node_Node_WriteTo_(b *testing.B) {
+	if benchmarkNodes(benchmarkDepth).String() !=
+		benchmarkBuffers(benchmarkDepth).String() {
+		b.Fatal("outputs differ")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkNodes(benchmarkDepth).WriteTo(ioutil.Discard)
+	}
+} //                                                Benchmark_node_Node_WriteTo_
+
+// end
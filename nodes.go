@@ -0,0 +1,333 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                    zr-web/[nodes.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	The functions in this file return nodes, which form a tree that can
+//	be changed before it is rendered. Each one is the equivalent of the
+//	helper in html.go without the 'Node' suffix, which renders it: e.g.
+//	Div(...) returns DivNode(...).Render(). The equivalents of HTML(),
+//	JOIN(), Comment(), Container() and Element() are in node.go:
+//	HTMLNode(), FragmentNode(), CommentNode(), ContainerNode() and
+//	ElementNode().
+
+// # Top-Level Container Elements
+//   BodyNode(content ...interface{}) *Node
+//   HeadNode(content ...interface{}) *Node
+//
+// # Container Elements
+//   ANode(href string, content ...interface{}) *Node
+//   ArticleNode(content ...interface{}) *Node
+//   DivNode(content ...interface{}) *Node
+//   FormNode(content ...interface{}) *Node
+//   H1Node(content ...interface{}) *Node
+//   H2Node(content ...interface{}) *Node
+//   H3Node(content ...interface{}) *Node
+//   H4Node(content ...interface{}) *Node
+//   H5Node(content ...interface{}) *Node
+//   H6Node(content ...interface{}) *Node
+//   HeaderNode(content ...interface{}) *Node
+//   IFrameNode(content ...interface{}) *Node
+//   ImgNode(content ...interface{}) *Node
+//   LabelNode(content ...interface{}) *Node
+//   LiNode(content ...interface{}) *Node
+//   NavNode(content ...interface{}) *Node
+//   PNode(content ...interface{}) *Node
+//   SpanNode(content ...interface{}) *Node
+//   TitleNode(content ...interface{}) *Node
+//   UlNode(content ...interface{}) *Node
+//
+// # Helper Tags (non-standard tags that simplify markup)
+//   COLUMNSNode(cols []string, class string, useNthChild bool) *Node
+//   CSSNode(styles ...string) *Node
+//   JSNode(scripts ...string) *Node
+//   NAVNode(href string, content ...interface{}) *Node
+//   TEXTNode(texts ...string) *Node
+//
+// # Non-Container Elements
+//   BrNode(attributes ...Attribute) *Node
+//   HrNode(attributes ...Attribute) *Node
+//   InputNode(attributes ...Attribute) *Node
+//   MetaCharsetNode(locale string) *Node
+//   MetaViewportNode() *Node
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Top-Level Container Elements
+
+// BodyNode returns a <body> element node. See Body().
+func BodyNode(content ...interface{}) *Node {
+	return ContainerNode("body", content...)
+} //                                                                    BodyNode
+
+// HeadNode returns a <head> element node. See Head().
+func HeadNode(content ...interface{}) *Node {
+	return ContainerNode("head", content...)
+} //                                                                    HeadNode
+
+// -----------------------------------------------------------------------------
+// # Container Elements
+
+// ANode returns an <a> hyperlink element node. See A().
+func ANode(href string, content ...interface{}) *Node {
+	// 'href' comes last, so it replaces any 'href' in 'content'
+	content = append(content, HREF(href))
+	return ContainerNode("a", content...)
+} //                                                                       ANode
+
+// ArticleNode returns an <article> element node. See Article().
+func ArticleNode(content ...interface{}) *Node {
+	if oldBrowsers {
+		return ContainerNode("div", content...)
+	}
+	return ContainerNode("article", content...)
+} //                                                                 ArticleNode
+
+// DivNode returns a <div> element node. See Div().
+func DivNode(content ...interface{}) *Node {
+	return ContainerNode("div", content...)
+} //                                                                     DivNode
+
+// FormNode returns a <form> element node. See Form().
+func FormNode(content ...interface{}) *Node {
+	return ContainerNode("form", content...)
+} //                                                                    FormNode
+
+// H1Node returns an <h1> heading element node. See H1().
+func H1Node(content ...interface{}) *Node {
+	return ContainerNode("h1", content...)
+} //                                                                      H1Node
+
+// H2Node returns an <h2> heading element node. See H2().
+func H2Node(content ...interface{}) *Node {
+	return ContainerNode("h2", content...)
+} //                                                                      H2Node
+
+// H3Node returns an <h3> heading element node. See H3().
+func H3Node(content ...interface{}) *Node {
+	return ContainerNode("h3", content...)
+} //                                                                      H3Node
+
+// H4Node returns an <h4> heading element node. See H4().
+func H4Node(content ...interface{}) *Node {
+	return ContainerNode("h4", content...)
+} //                                                                      H4Node
+
+// H5Node returns an <h5> heading element node. See H5().
+func H5Node(content ...interface{}) *Node {
+	return ContainerNode("h5", content...)
+} //                                                                      H5Node
+
+// H6Node returns an <h6> heading element node. See H6().
+func H6Node(content ...interface{}) *Node {
+	return ContainerNode("h6", content...)
+} //                                                                      H6Node
+
+// HeaderNode returns a <header> element node wrapped
+// in a <div class="header"> node. See Header().
+func HeaderNode(content ...interface{}) *Node {
+	if oldBrowsers {
+		return DivNode(
+			Class("header"),
+			ContainerNode("div", content...),
+		)
+	}
+	return DivNode(
+		Class("header"),
+		ContainerNode("header", content...),
+	)
+} //                                                                  HeaderNode
+
+// IFrameNode returns an <iframe> element node. See IFrame().
+func IFrameNode(content ...interface{}) *Node {
+	return ContainerNode("iframe", content...)
+} //                                                                  IFrameNode
+
+// ImgNode returns an <img> element node. Strings in 'content'
+// become its 'src' attribute. See Img().
+func ImgNode(content ...interface{}) *Node {
+	for i, it := range content {
+		if s, ok := it.(string); ok {
+			content[i] = Attribute{Name: "src", Value: s}
+		}
+	}
+	return ContainerNode("img", content...)
+} //                                                                     ImgNode
+
+// LabelNode returns a <label> element node. See Label().
+func LabelNode(content ...interface{}) *Node {
+	return ContainerNode("label", content...)
+} //                                                                   LabelNode
+
+// LiNode returns an <li> list item element node. See Li().
+func LiNode(content ...interface{}) *Node {
+	return ContainerNode("li", content...)
+} //                                                                      LiNode
+
+// NavNode returns a <nav> element node. See Nav().
+func NavNode(content ...interface{}) *Node {
+	if oldBrowsers {
+		return ContainerNode("div", content...)
+	}
+	return ContainerNode("nav", content...)
+} //                                                                     NavNode
+
+// PNode returns a <p> paragraph element node. See P().
+func PNode(content ...interface{}) *Node {
+	return ContainerNode("p", content...)
+} //                                                                       PNode
+
+// SpanNode returns a <span> element node. See Span().
+func SpanNode(content ...interface{}) *Node {
+	return ContainerNode("span", content...)
+} //                                                                    SpanNode
+
+// TitleNode returns a <title> element node. See Title().
+func TitleNode(content ...interface{}) *Node {
+	return ContainerNode("title", content...)
+} //                                                                   TitleNode
+
+// UlNode returns a <ul> unordered list element node. See Ul().
+func UlNode(content ...interface{}) *Node {
+	return ContainerNode("ul", content...)
+} //                                                                      UlNode
+
+// -----------------------------------------------------------------------------
+// # Helper Tags (non-standard tags that simplify markup)
+
+// COLUMNSNode returns a <div> node holding a <p> node for every
+// string in 'cols'. See COLUMNS().
+//
+// Deprecated: use DataTable.Node().
+func COLUMNSNode(cols []string, class string, useNthChild bool) *Node {
+	ret := DivNode()
+	for i, col := range cols {
+		var attrs []interface{}
+		hasClass := strings.Contains(col, "class::") || class != ""
+		if !useNthChild || hasClass {
+			value := class
+			if !useNthChild {
+				if class != "" {
+					value += " "
+				}
+				value += fmt.Sprintf("c c%d", i+1)
+			}
+			if hasClass {
+				part := zr.GetPart(col, "class::", ";")
+				col = strings.ReplaceAll(col, "class::"+part+";", "")
+			}
+			attrs = append(attrs, Class(value))
+		}
+		ret.AppendChild(PNode(append(attrs, col)...))
+	}
+	return ret
+} //                                                                 COLUMNSNode
+
+// CSSNode returns a fragment node holding a <link> node for every
+// CSS file and a <style> node for every style in 'styles'. See CSS().
+func CSSNode(styles ...string) *Node {
+	ret := FragmentNode()
+	for _, style := range styles {
+		style = strings.TrimSpace(style)
+		if style == "" {
+			continue
+		}
+		if zr.ContainsI(style, ".css") {
+			ret.AppendChild(ElementNode("link",
+				Attr("rel", "stylesheet"),
+				Type("text/css"),
+				HREF(style),
+			))
+			continue
+		}
+		ret.AppendChild(ContainerNode("style",
+			Type("text/css"),
+			"\r\n"+style+"\r\n",
+		))
+	}
+	return ret
+} //                                                                     CSSNode
+
+// JSNode returns a fragment node holding a <script> node
+// for every file or snippet in 'scripts'. See JS().
+func JSNode(scripts ...string) *Node {
+	ret := FragmentNode()
+	for _, js := range scripts {
+		js = strings.TrimSpace(js)
+		if js == "" {
+			continue
+		}
+		if zr.ContainsI(js, ".js") {
+			ret.AppendChild(ContainerNode("script",
+				Type("text/javascript"),
+				Src(js),
+			))
+			continue
+		}
+		ret.AppendChild(ContainerNode("script",
+			Type("text/javascript"),
+			js,
+		))
+	}
+	return ret
+} //                                                                      JSNode
+
+// NAVNode returns an <a> node that navigates with zr.go(). See NAV().
+func NAVNode(href string, content ...interface{}) *Node {
+	isFuncCall := strings.Contains(href, "(") && strings.Contains(href, ")")
+	if !isFuncCall {
+		href = fmt.Sprintf("zr.go('%s')", href)
+	}
+	// ANode() replaces any 'href' in 'content' with "#"
+	content = append(content, OnClick(href))
+	return ANode("#", content...)
+} //                                                                     NAVNode
+
+// TEXTNode returns a node holding 'texts' as markup. See TEXT().
+// Use TextNode() for plain text that should be escaped.
+func TEXTNode(texts ...string) *Node {
+	return RawNode(strings.Join(texts, ""))
+} //                                                                    TEXTNode
+
+// -----------------------------------------------------------------------------
+// # Non-Container Elements
+
+// BrNode returns a <br> line break element node. See Br().
+func BrNode(attributes ...Attribute) *Node {
+	return ElementNode("br", attributes...)
+} //                                                                      BrNode
+
+// HrNode returns an <hr> thematic break element node. See Hr().
+func HrNode(attributes ...Attribute) *Node {
+	return ElementNode("hr", attributes...)
+} //                                                                      HrNode
+
+// InputNode returns an <input> element node. See Input().
+func InputNode(attributes ...Attribute) *Node {
+	return ElementNode("input", attributes...)
+} //                                                                   InputNode
+
+// MetaCharsetNode returns a <meta charset> element node.
+// See MetaCharset().
+func MetaCharsetNode(locale string) *Node {
+	return ElementNode("meta", Attr("charset", locale))
+} //                                                             MetaCharsetNode
+
+// MetaViewportNode returns a <meta name="viewport"> element
+// node. See MetaViewport().
+func MetaViewportNode() *Node {
+	return ElementNode("meta",
+		Attr("name", "viewport"),
+		Attr("content", "width=device-width, initial-scale=1"),
+	)
+} //                                                            MetaViewportNode
+
+// end
@@ -0,0 +1,91 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                               zr-web/[nodes_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Container Elements
+//   Test_nods_HeaderNode_
+//
+// # Helper Tags (non-standard tags that simplify markup)
+//   Test_nods_COLUMNSNode_
+//   Test_nods_CSSNode_
+//   Test_nods_JSNode_
+
+//  to test all items in nodes.go use:
+//      go test --run Test_nods_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Container Elements
+
+// go test --run Test_nods_HeaderNode_
+func Test_nods_HeaderNode_(t *testing.T) {
+	zr.TBegin(t)
+	// HeaderNode(content ...interface{}) *Node
+	//
+	node := HeaderNode("title")
+	zr.TEqual(t, node.String(), Header("title").String())
+	node.FindFirst("header").AddClass("top")
+	zr.TEqual(t, node.String(), "<div class=\"header\">\r\n"+
+		"<header class=\"top\">\r\ntitle</header>\r\n</div>\r\n")
+} //                                                       Test_nods_HeaderNode_
+
+// -----------------------------------------------------------------------------
+// # Helper Tags (non-standard tags that simplify markup)
+
+// go test --run Test_nods_COLUMNSNode_
+func Test_nods_COLUMNSNode_(t *testing.T) {
+	zr.TBegin(t)
+	// COLUMNSNode(cols []string, class string, useNthChild bool) *Node
+	//
+	zr.TEqual(t, COLUMNSNode([]string{"a", "b"}, "k", true).String(),
+		"<div>\r\n<p class=\"k\">a</p>\r\n<p class=\"k\">b</p>\r\n</div>\r\n")
+	zr.TEqual(t, COLUMNSNode([]string{"a", "b"}, "", false).String(),
+		"<div>\r\n<p class=\"c c1\">a</p>\r\n<p class=\"c c2\">b</p>\r\n"+
+			"</div>\r\n")
+	//
+	node := COLUMNSNode([]string{"a", "b", "c"}, "", true)
+	zr.TEqual(t, len(node.Find("p")), 3)
+	node.Find("p")[1].AddClass("sum")
+	zr.TEqual(t, node.String(), "<div>\r\n<p>a</p>\r\n"+
+		"<p class=\"sum\">b</p>\r\n<p>c</p>\r\n</div>\r\n")
+} //                                                      Test_nods_COLUMNSNode_
+
+// go test --run Test_nods_CSSNode_
+func Test_nods_CSSNode_(t *testing.T) {
+	zr.TBegin(t)
+	// CSSNode(styles ...string) *Node
+	//
+	node := CSSNode("site.css", " ", "body { margin: 0 }")
+	zr.TEqual(t, node.String(),
+		"<link rel=\"stylesheet\" type=\"text/css\" href=\"site.css\">\r\n"+
+			"<style type=\"text/css\">\r\nbody { margin: 0 }\r\n</style>\r\n")
+	zr.TEqual(t, node.FindFirst("link").Attr("href"), "site.css")
+	zr.TEqual(t, CSSNode("p {}").String(), CSS("p {}").String())
+} //                                                          Test_nods_CSSNode_
+
+// go test --run Test_nods_JSNode_
+func Test_nods_JSNode_(t *testing.T) {
+	zr.TBegin(t)
+	// JSNode(scripts ...string) *Node
+	//
+	node := JSNode("app.js", "", "init()")
+	zr.TEqual(t, node.String(),
+		"<script type=\"text/javascript\" src=\"app.js\"></script>\r\n"+
+			"<script type=\"text/javascript\">init()</script>\r\n")
+	zr.TEqual(t, len(node.Find("script")), 2)
+	zr.TEqual(t, JSNode("init()").String(), JS("init()").String())
+} //                                                           Test_nods_JSNode_
+
+// end
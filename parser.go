@@ -0,0 +1,392 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                   zr-web/[parser.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	ParseHTML reads HTML markup, e.g. a fragment from an editor or a
+//	legacy template, into the same tree of nodes that ContainerNode()
+//	and the other node helpers build, so it can be queried, changed
+//	and combined with generated content:
+//
+//	body := web.ParseHTML(legacyTemplate)
+//	body.FindFirst("#content").AppendChild(web.PNode("Hello"))
+//	ctx.Reply(web.HTMLNode(web.HeadNode(), web.BodyNode(body)), "html")
+//
+//	Like a browser, the parser accepts any input: missing end tags are
+//	implied, stray end tags are dropped and a '<' that doesn't start a
+//	tag is read as text. It knows void elements (e.g. <br>), which have
+//	no content, and reads the content of <script>, <style>, <textarea>
+//	and <title> as text up to the element's end tag.
+//
+//	Parsed nodes are rendered the way they were read, so rendering
+//	the tree gives back the markup, with these changes: tag and
+//	attribute names are written in lowercase, attribute values in
+//	double quotes (attributes without a value stay bare), implied
+//	end tags are written, stray end tags and repeated attributes
+//	are dropped, and '/>' is written as '>'.
+//	Entities in text are kept; Node.Data() returns the decoded text.
+
+// # Functions
+//   ParseHTML(markup string) *Node
+//
+// # Support (File Scope)
+//   htmlParser struct
+//   (ob *htmlParser) add(node *Node)
+//   (ob *htmlParser) closeImplied(tag string)
+//   (ob *htmlParser) parse()
+//   (ob *htmlParser) parseAttrs() Attributes
+//   (ob *htmlParser) parseEndTag()
+//   (ob *htmlParser) parseRawText(el *Node)
+//   (ob *htmlParser) parseStartTag()
+//   (ob *htmlParser) readName() string
+//   (ob *htmlParser) skipSpace()
+//   indexEndTag(s, tag string) int
+//   isHTMLSpace(ch byte) bool
+//   isLetter(ch byte) bool
+//   lowerASCII(s string) string
+
+import (
+	"strings"
+)
+
+// htmlRawTextElements lists elements whose content is read as
+// text up to their end tag. The content of <script> and <style>
+// is not text, so it is read as a raw node instead.
+var htmlRawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// htmlImpliedEnds specifies which open elements are closed by the start
+// tag of another element, e.g. an open <li> is closed by the next <li>
+var htmlImpliedEnds = map[string]map[string]bool{
+	"dd":       {"dd": true, "dt": true},
+	"dt":       {"dd": true, "dt": true},
+	"li":       {"li": true},
+	"optgroup": {"optgroup": true},
+	"option":   {"optgroup": true, "option": true},
+	"p": {
+		"address": true, "article": true, "aside": true,
+		"blockquote": true, "details": true, "div": true, "dl": true,
+		"fieldset": true, "figcaption": true, "figure": true,
+		"footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+		"h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+		"main": true, "menu": true, "nav": true, "ol": true, "p": true,
+		"pre": true, "section": true, "table": true, "ul": true,
+	},
+	"tbody": {"tbody": true, "tfoot": true},
+	"td":    {"td": true, "th": true, "tr": true},
+	"th":    {"td": true, "th": true, "tr": true},
+	"thead": {"tbody": true, "tfoot": true},
+	"tr":    {"tr": true},
+}
+
+// -----------------------------------------------------------------------------
+// # Functions
+
+// ParseHTML parses HTML 'markup' and returns a fragment node holding
+// the nodes read from it. It never fails: markup that is not valid
+// is read the way browsers read it.
+func ParseHTML(markup string) *Node {
+	ob := htmlParser{src: markup, root: FragmentNode()}
+	ob.parse()
+	return ob.root
+} //                                                                   ParseHTML
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// htmlParser holds the state of ParseHTML()
+type htmlParser struct {
+	src  string
+	pos  int
+	root *Node
+	open []*Node // elements whose end tag has not been read yet
+} //                                                                  htmlParser
+
+// add appends 'node' to the innermost open element.
+func (ob *htmlParser) add(node *Node) {
+	if n := len(ob.open); n > 0 {
+		ob.open[n-1].AppendChild(node)
+		return
+	}
+	ob.root.AppendChild(node)
+} //                                                                         add
+
+// closeImplied closes the open elements that the start tag of
+// element 'tag' ends, e.g. an open <p> before a <div>.
+func (ob *htmlParser) closeImplied(tag string) {
+	for n := len(ob.open); n > 0; n = len(ob.open) {
+		if !htmlImpliedEnds[ob.open[n-1].tag][tag] {
+			break
+		}
+		ob.open = ob.open[:n-1]
+	}
+} //                                                                closeImplied
+
+// parse reads the whole input into the root node.
+func (ob *htmlParser) parse() {
+	text := 0 // start of the text not added yet
+	addText := func(end int) {
+		if end > text {
+			ob.add(&Node{kind: NodeText, text: ob.src[text:end], parsed: true})
+		}
+	}
+	for {
+		i := strings.IndexByte(ob.src[ob.pos:], '<')
+		if i == -1 {
+			break
+		}
+		at := ob.pos + i
+		rest := ob.src[at:]
+		ob.pos = at + 1
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			addText(at)
+			content := rest[4:]
+			end := strings.Index(content, "-->")
+			switch {
+			case strings.HasPrefix(content, ">"): // '<!-->' is empty
+				end, ob.pos = 0, at+5
+			case strings.HasPrefix(content, "->"): // and so is '<!--->'
+				end, ob.pos = 0, at+6
+			case end == -1:
+				end, ob.pos = len(content), len(ob.src)
+			default:
+				ob.pos = at + 4 + end + 3
+			}
+			ob.add(&Node{kind: NodeComment, text: content[:end], parsed: true})
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			// doctype, CDATA section or processing instruction
+			addText(at)
+			end := strings.IndexByte(rest, '>')
+			markup := rest
+			if end == -1 {
+				markup += ">"
+				ob.pos = len(ob.src)
+			} else {
+				markup = rest[:end+1]
+				ob.pos = at + end + 1
+			}
+			ob.add(&Node{kind: NodeRaw, text: markup, parsed: true})
+		case strings.HasPrefix(rest, "</>"):
+			addText(at)
+			ob.pos = at + 3
+		case len(rest) > 2 && rest[1] == '/' && isLetter(rest[2]):
+			addText(at)
+			ob.pos = at + 2
+			ob.parseEndTag()
+		case len(rest) > 1 && isLetter(rest[1]):
+			addText(at)
+			ob.parseStartTag()
+		default:
+			continue // '<' is part of the text
+		}
+		text = ob.pos
+	}
+	addText(len(ob.src))
+} //                                                                       parse
+
+// parseAttrs reads the attributes of a start tag up to and including
+// its closing '>'. Only the first of repeated attributes is kept.
+func (ob *htmlParser) parseAttrs() Attributes {
+	var ret Attributes
+	src := ob.src
+	peek := func() int { // returns -1 at the end of the input
+		if ob.pos < len(src) {
+			return int(src[ob.pos])
+		}
+		return -1
+	}
+	isNameEnd := func(ch int) bool {
+		return ch == -1 || ch == '/' || ch == '>' || ch == '=' ||
+			isHTMLSpace(byte(ch))
+	}
+	for {
+		ob.skipSpace()
+		switch peek() {
+		case -1:
+			return ret
+		case '/':
+			ob.pos++
+			continue
+		case '>':
+			ob.pos++
+			return ret
+		}
+		// a name can start with '=', but can't contain it after that
+		start := ob.pos
+		ob.pos++
+		for !isNameEnd(peek()) {
+			ob.pos++
+		}
+		name := lowerASCII(src[start:ob.pos])
+		value, bare := "", true
+		ob.skipSpace()
+		if peek() == '=' {
+			bare = false
+			ob.pos++
+			ob.skipSpace()
+			switch quote := peek(); quote {
+			case '"', '\'':
+				ob.pos++
+				end := strings.IndexByte(src[ob.pos:], byte(quote))
+				if end == -1 {
+					value = src[ob.pos:]
+					ob.pos = len(src)
+					break
+				}
+				value = src[ob.pos : ob.pos+end]
+				ob.pos += end + 1
+			default:
+				start := ob.pos
+				for ch := peek(); ch != -1 && ch != '>' &&
+					!isHTMLSpace(byte(ch)); ch = peek() {
+					ob.pos++
+				}
+				value = src[start:ob.pos]
+			}
+		}
+		if !ret.Has(name) {
+			// values are written in double quotes
+			value = strings.ReplaceAll(value, `"`, "&quot;")
+			ret = append(ret, Attribute{Name: name, Value: value, Bare: bare})
+		}
+	}
+} //                                                                  parseAttrs
+
+// parseEndTag reads an end tag after its '</' and closes the
+// innermost open element with the same name, along with the
+// elements inside it. End tags of unopened elements are dropped.
+func (ob *htmlParser) parseEndTag() {
+	tag := ob.readName()
+	end := strings.IndexByte(ob.src[ob.pos:], '>')
+	if end == -1 {
+		ob.pos = len(ob.src)
+	} else {
+		ob.pos += end + 1
+	}
+	for i := len(ob.open) - 1; i >= 0; i-- {
+		if ob.open[i].tag == tag {
+			ob.open = ob.open[:i]
+			return
+		}
+	}
+} //                                                                 parseEndTag
+
+// parseRawText reads the content of a raw text element
+// (see htmlRawTextElements) up to and including its end tag.
+func (ob *htmlParser) parseRawText(el *Node) {
+	rest := ob.src[ob.pos:]
+	end := indexEndTag(rest, el.tag)
+	if end == -1 {
+		end = len(rest)
+		ob.pos = len(ob.src)
+	} else {
+		ob.pos += end
+		ob.pos += strings.IndexByte(ob.src[ob.pos:], '>') + 1
+	}
+	if end > 0 {
+		kind := NodeText
+		if el.tag == "script" || el.tag == "style" {
+			kind = NodeRaw
+		}
+		el.AppendChild(&Node{kind: kind, text: rest[:end], parsed: true})
+	}
+} //                                                                parseRawText
+
+// parseStartTag reads a start tag after its '<' and opens the element.
+func (ob *htmlParser) parseStartTag() {
+	tag := ob.readName()
+	el := &Node{
+		kind:   NodeElement,
+		tag:    tag,
+		void:   IsVoidElement(tag),
+		attrs:  ob.parseAttrs(),
+		parsed: true,
+	}
+	ob.closeImplied(tag)
+	ob.add(el)
+	switch {
+	case el.void:
+		return
+	case htmlRawTextElements[tag]:
+		ob.parseRawText(el)
+		return
+	}
+	ob.open = append(ob.open, el)
+} //                                                               parseStartTag
+
+// readName reads a tag name and returns it in lowercase.
+func (ob *htmlParser) readName() string {
+	start := ob.pos
+	for ob.pos < len(ob.src) {
+		ch := ob.src[ob.pos]
+		if isHTMLSpace(ch) || ch == '/' || ch == '>' {
+			break
+		}
+		ob.pos++
+	}
+	return lowerASCII(ob.src[start:ob.pos])
+} //                                                                    readName
+
+// skipSpace skips white space.
+func (ob *htmlParser) skipSpace() {
+	for ob.pos < len(ob.src) && isHTMLSpace(ob.src[ob.pos]) {
+		ob.pos++
+	}
+} //                                                                   skipSpace
+
+// indexEndTag returns the index of the first end tag of
+// element 'tag' in 's', ignoring case, or -1 if there is none.
+// The tag name must be followed by white space, '/', '>' or
+// the end of 's', so '</scripts>' doesn't end a <script>.
+func indexEndTag(s, tag string) int {
+	for i := 0; ; {
+		n := strings.Index(s[i:], "</")
+		if n == -1 {
+			return -1
+		}
+		i += n
+		name := s[i+2:]
+		if len(name) >= len(tag) && lowerASCII(name[:len(tag)]) == tag {
+			if len(name) == len(tag) {
+				return i
+			}
+			if ch := name[len(tag)]; isHTMLSpace(ch) || ch == '/' || ch == '>' {
+				return i
+			}
+		}
+		i += 2
+	}
+} //                                                                 indexEndTag
+
+// isHTMLSpace returns true if 'ch' is an HTML white space character.
+func isHTMLSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\f' || ch == '\r'
+} //                                                                 isHTMLSpace
+
+// isLetter returns true if 'ch' is an ASCII letter.
+func isLetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+} //                                                                    isLetter
+
+// lowerASCII changes ASCII letters in 's' to lowercase,
+// leaving other characters as they are.
+func lowerASCII(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 'A' && s[i] <= 'Z' {
+			b := []byte(s)
+			for j := i; j < len(b); j++ {
+				if b[j] >= 'A' && b[j] <= 'Z' {
+					b[j] += 'a' - 'A'
+				}
+			}
+			return string(b)
+		}
+	}
+	return s
+} //                                                                  lowerASCII
+
+// end
@@ -0,0 +1,55 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                         zr-web/[parser_fuzz_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+//go:build go1.18
+// +build go1.18
+
+package web
+
+// # Fuzz Tests
+//   Fuzz_pars_ParseHTML_
+
+//  fuzzing needs Go 1.18 or later, so this file is built separately.
+//  To fuzz the parser use:
+//      go test --run NONE --fuzz Fuzz_pars_ParseHTML_
+//
+//  Without '--fuzz', 'go test' runs the seeds below as a test.
+
+import (
+	"testing"
+)
+
+// go test --run NONE --fuzz Fuzz_pars_ParseHTML_
+func Fuzz_pars_ParseHTML_(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"text & more",
+		`<!DOCTYPE html><html lang="en"><head><title>T</title></head>` +
+			`<body><p class=a>x<br/>y</p></body></html>`,
+		`<ul><li>a<li>b</ul><dl><dt>a<dd>b</dl>`,
+		`<table><tr><td>a<td>b<tr><td>c</table>`,
+		`<DIV ID=main Class='a "b"' =x y= z=>`,
+		`<script>if (a<b) { x("</p>") }</SCRIPT><style>p{}`,
+		`<!-- a --><!-->x<!--b`,
+		`<?xml x?><![CDATA[y]]><!x`,
+		`1 < 2 </ 3 </> </span>`,
+		`<textarea>&lt;b&gt;</textarea`,
+		`<a b='"' c=d"e f>`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, markup string) {
+		// rendering normalizes the markup once: reading the
+		// output again must give the same tree and output
+		once := ParseHTML(markup).String()
+		twice := ParseHTML(once).String()
+		if twice != once {
+			t.Errorf("output changed when read again:\n"+
+				"input:  %q\nonce:   %q\ntwice:  %q", markup, once, twice)
+		}
+	})
+} //                                                        Fuzz_pars_ParseHTML_
+
+// end
@@ -0,0 +1,223 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                              zr-web/[parser_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Functions
+//   Test_pars_ParseHTML_
+//   Test_pars_ParseHTML_entities_
+//   Test_pars_ParseHTML_normalize_
+//   Test_pars_ParseHTML_roundTrip_
+//   Test_pars_ParseHTML_tree_
+
+//  to test all items in parser.go use:
+//      go test --run Test_pars_
+//
+//  to fuzz the parser (requires Go 1.18 or later) use:
+//      go test --run NONE --fuzz Fuzz_pars_ParseHTML_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// parserTestDump describes the tree under 'node' on one line, e.g.
+// 'div[id=a](p(#"x"))', to check the structure the parser builds.
+func parserTestDump(node *Node) string {
+	var parts []string
+	for _, child := range node.Children() {
+		switch child.Type() {
+		case NodeText:
+			parts = append(parts, `#"`+child.Data()+`"`)
+		case NodeComment:
+			parts = append(parts, `!"`+child.Data()+`"`)
+		case NodeRaw:
+			parts = append(parts, `^"`+child.Data()+`"`)
+		default:
+			s := child.Tag()
+			for _, attr := range child.Attributes() {
+				s += "[" + attr.Name + "=" + attr.Value + "]"
+			}
+			if len(child.Children()) > 0 {
+				s += "(" + parserTestDump(child) + ")"
+			}
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+} //                                                              parserTestDump
+
+// go test --run Test_pars_ParseHTML_
+func Test_pars_ParseHTML_(t *testing.T) {
+	zr.TBegin(t)
+	// ParseHTML(markup string) *Node
+	//
+	zr.TEqual(t, ParseHTML("").String(), "")
+	zr.TEqual(t, ParseHTML("").Type(), NodeFragment)
+	zr.TEqual(t, ParseHTML("plain text").String(), "plain text")
+	//
+	// parsed nodes can be changed and mixed with generated nodes
+	page := ParseHTML(`<div id="main"><p class="a">Hi</p></div>`)
+	page.FindFirst("p").AddClass("b")
+	page.FindFirst("#main").AppendChild(PNode("Bye"))
+	zr.TEqual(t, page.String(), `<div id="main"><p class="a b">Hi</p>`+
+		"<p>Bye</p>\r\n</div>")
+} //                                                        Test_pars_ParseHTML_
+
+// go test --run Test_pars_ParseHTML_entities_
+func Test_pars_ParseHTML_entities_(t *testing.T) {
+	zr.TBegin(t)
+	// ParseHTML(markup string) *Node
+	//
+	// entities are kept in the markup and decoded by Data()
+	node := ParseHTML(`<p title="a &amp; b">Tom &amp; Jerry&nbsp;&lt;3</p>`)
+	zr.TEqual(t, node.String(),
+		`<p title="a &amp; b">Tom &amp; Jerry&nbsp;&lt;3</p>`)
+	zr.TEqual(t, node.FindFirst("p").Attr("title"), "a &amp; b")
+	zr.TEqual(t, node.FindFirst("p").Children()[0].Data(),
+		"Tom & Jerry\u00a0<3")
+	//
+	// <script> content is not text, so it is not decoded
+	node = ParseHTML(`<script>a &amp;&amp; b</script>`)
+	zr.TEqual(t, node.FindFirst("script").Children()[0].Data(),
+		"a &amp;&amp; b")
+	//
+	// <textarea> and <title> content is text
+	node = ParseHTML(`<textarea>&lt;b&gt;</textarea>`)
+	zr.TEqual(t, node.FindFirst("textarea").Children()[0].Data(), "<b>")
+} //                                               Test_pars_ParseHTML_entities_
+
+// go test --run Test_pars_ParseHTML_normalize_
+func Test_pars_ParseHTML_normalize_(t *testing.T) {
+	zr.TBegin(t)
+	// ParseHTML(markup string) *Node
+	//
+	test := func(input, expect string) {
+		zr.TEqual(t, ParseHTML(input).String(), expect)
+	}
+	// names in lowercase, values in double quotes
+	test(`<DIV ID=main Class='a "b"'>x</DIV>`,
+		`<div id="main" class="a &quot;b&quot;">x</div>`)
+	test(`<input type=checkbox checked disabled="">`,
+		`<input type="checkbox" checked disabled="">`)
+	//
+	// repeated attributes: the first is kept
+	test(`<p id="a" ID="b">x</p>`, `<p id="a">x</p>`)
+	//
+	// void elements have no content or end tag
+	test(`<br/><img src="a.png" /><hr></hr>x`, `<br><img src="a.png"><hr>x`)
+	//
+	// implied and missing end tags are written
+	test(`<ul><li>a<li>b</ul>`, `<ul><li>a</li><li>b</li></ul>`)
+	test(`<p>a<div>b</div>`, `<p>a</p><div>b</div>`)
+	test(`<p>a<p>b`, `<p>a</p><p>b</p>`)
+	test(`<dl><dt>a<dd>b<dt>c</dl>`, `<dl><dt>a</dt><dd>b</dd><dt>c</dt></dl>`)
+	test(`<table><tr><td>a<td>b<tr><td>c</table>`,
+		`<table><tr><td>a</td><td>b</td></tr><tr><td>c</td></tr></table>`)
+	test(`<div><span>a</div>b`, `<div><span>a</span></div>b`)
+	//
+	// stray end tags are dropped
+	test(`a</span>b</>c`, `abc`)
+	//
+	// a '<' that doesn't start a tag is text
+	test(`1 < 2 <3 </ 4`, `1 < 2 <3 </ 4`)
+	//
+	// comments, doctypes and processing instructions
+	test(`<!DOCTYPE html><!-- a --><?xml x?>`,
+		`<!DOCTYPE html><!-- a --><?xml x?>`)
+	test(`<!-->x<!--->y`, `<!---->x<!---->y`)
+	test(`<p><!-- a`, `<p><!-- a--></p>`)
+	test(`<!doctype`, `<!doctype>`)
+	//
+	// raw text elements end at their end tag, ignoring case
+	test(`<script>if (a<b) { x("</p>") }</SCRIPT>x`,
+		`<script>if (a<b) { x("</p>") }</script>x`)
+	test(`<style>p { }`, `<style>p { }</style>`)
+	test(`<script>a</scripts>b</script >`, `<script>a</scripts>b</script>`)
+	test(`<title><b>T</b></title>`, `<title><b>T</b></title>`)
+} //                                              Test_pars_ParseHTML_normalize_
+
+// go test --run Test_pars_ParseHTML_roundTrip_
+func Test_pars_ParseHTML_roundTrip_(t *testing.T) {
+	zr.TBegin(t)
+	// ParseHTML(markup string) *Node
+	//
+	// rendering parsed markup made by the helpers gives back the markup
+	test := func(markup string) {
+		zr.TEqual(t, ParseHTML(markup).String(), markup)
+	}
+	test(string(HTML(Lang("en"),
+		Head(
+			MetaCharset("utf-8"),
+			MetaViewport(),
+			Title("Tom &amp; Jerry"),
+			CSS("site.css", "body { margin: 0 }"),
+			JS("app.js", `if (a < b && c > d) { alert("</p>") }`),
+		),
+		Body(
+			Header(H1("Title")),
+			Nav(Ul(Li(A("/", "Home")), Li(NAV("/about", "About")))),
+			Div(Class("main"),
+				Comment(" content "),
+				P(Class("intro"), "a & b"),
+				Form(Label("Name"), Input(Type("text"), Attr("value", ""))),
+				Br(),
+				COLUMNS([]string{"a", "b"}, "", false),
+			),
+		),
+	)))
+	test("<p>\r\n  indented\r\n\t<b>text</b>\r\n</p>\r\n")
+	//
+	// the normalized output is read back the same way
+	for _, markup := range []string{
+		`<P CLASS=a>x<P>y`,
+		`<ul><li><p>a<li>b</ul>`,
+		`<a b='"' c=d"e f>`,
+		`<div =x y= z=>`,
+		`<p a/=b>`,
+		`<</>A`,
+		`<</span>A`,
+		`</</a>A`,
+		`<p a =b/=c="d">`,
+		`<script>x</scrip`,
+		`<!--a--`,
+		`<textarea></textarea`,
+	} {
+		once := ParseHTML(markup).String()
+		zr.TEqual(t, ParseHTML(once).String(), once)
+	}
+} //                                              Test_pars_ParseHTML_roundTrip_
+
+// go test --run Test_pars_ParseHTML_tree_
+func Test_pars_ParseHTML_tree_(t *testing.T) {
+	zr.TBegin(t)
+	// ParseHTML(markup string) *Node
+	//
+	test := func(input, expect string) {
+		zr.TEqual(t, parserTestDump(ParseHTML(input)), expect)
+	}
+	test(`<div id=a><p>x</p></div>`, `div[id=a](p(#"x"))`)
+	test(`<p>a<br>b</p>`, `p(#"a" br #"b")`)
+	test(`<img src=x alt>`, `img[src=x][alt=]`)
+	zr.TTrue(t, ParseHTML(`<img alt>`).FindFirst("img").Attributes()[0].Bare)
+	test(`<!DOCTYPE html><html></html>`, `^"<!DOCTYPE html>" html`)
+	test(`a<!-- b -->c`, `#"a" !" b " #"c"`)
+	test(`<script>a<b>c</script>`, `script(^"a<b>c")`)
+	test(`<ul><li>a<li>b</ul>`, `ul(li(#"a") li(#"b"))`)
+	//
+	// parents are set
+	node := ParseHTML(`<div><p>x</p></div>`)
+	p := node.FindFirst("p")
+	zr.TTrue(t, p.Parent() == node.FindFirst("div"))
+	zr.TTrue(t, p.Parent().Parent() == node)
+} //                                                   Test_pars_ParseHTML_tree_
+
+// end
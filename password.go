@@ -0,0 +1,245 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                 zr-web/[password.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	Passwords are hashed with PBKDF2 (RFC 8018) using only the standard
+//	library. The result is encoded with its parameters, for example:
+//
+//	$pbkdf2-sha256$i=600000,l=32$<salt>$<hash>
+//
+//	where <salt> and <hash> are unpadded base64. Since the parameters
+//	are stored with each hash, the cost can be raised later: check
+//	NeedsRehash() after a successful login and store a new hash.
+//
+//	if !web.VerifyPassword(password, user.PasswordHash) {
+//		return errWrongPassword
+//	}
+//	if web.NeedsRehash(user.PasswordHash) {
+//		user.PasswordHash, _ = web.HashPassword(password)
+//		saveUser(user)
+//	}
+
+// # Global Settings
+//   PasswordIterations() int
+//   SetPasswordIterations(iterations int)
+//
+// # Functions
+//   HashPassword(password string) (string, error)
+//   NeedsRehash(encoded string) bool
+//   VerifyPassword(password, encoded string) bool
+//
+// # Support (File Scope)
+//   passwordHash struct
+//   isPasswordHash(s string) bool
+//   parsePasswordHash(encoded string) (passwordHash, error)
+//   pbkdf2(newHash func() hash.Hash, password, salt []byte,
+//       iterations, keyLen int) []byte
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/balacode/zr"
+)
+
+// DefaultPasswordIterations is the default number of PBKDF2-HMAC-SHA256
+// iterations, as recommended by OWASP for 2023.
+const DefaultPasswordIterations = 600000
+
+// Parameters of new password hashes.
+const (
+	passwordAlgorithm = "pbkdf2-sha256"
+	passwordKeyLen    = 32
+	passwordSaltLen   = 16
+)
+
+// passwordIterations holds the number of iterations for new hashes
+var passwordIterations int64 = DefaultPasswordIterations
+
+// passwordAlgorithms maps supported algorithm names to hash functions
+var passwordAlgorithms = map[string]func() hash.Hash{
+	"pbkdf2-sha256": sha256.New,
+	"pbkdf2-sha512": sha512.New,
+}
+
+// passwordHash holds the parts of an encoded password hash.
+type passwordHash struct {
+	algorithm  string
+	iterations int
+	salt       []byte
+	key        []byte
+} //                                                                passwordHash
+
+// -----------------------------------------------------------------------------
+// # Global Settings
+
+// PasswordIterations returns the number of PBKDF2
+// iterations used by HashPassword().
+func PasswordIterations() int {
+	return int(atomic.LoadInt64(&passwordIterations))
+} //                                                          PasswordIterations
+
+// SetPasswordIterations sets the number of PBKDF2 iterations used by
+// HashPassword(). Raise it as hardware gets faster; existing hashes
+// with fewer iterations will then be reported by NeedsRehash().
+// Values below 1000 are raised to 1000.
+func SetPasswordIterations(iterations int) {
+	if iterations < 1000 {
+		iterations = 1000
+	}
+	atomic.StoreInt64(&passwordIterations, int64(iterations))
+} //                                                       SetPasswordIterations
+
+// -----------------------------------------------------------------------------
+// # Functions
+
+// HashPassword hashes 'password' with a new random salt
+// and returns the encoded hash for storing.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", zr.Error("Can't generate salt:", err)
+	}
+	iterations := PasswordIterations()
+	key := pbkdf2(passwordAlgorithms[passwordAlgorithm],
+		[]byte(password), salt, iterations, passwordKeyLen)
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("$%s$i=%d,l=%d$%s$%s",
+		passwordAlgorithm, iterations, len(key),
+		enc.EncodeToString(salt), enc.EncodeToString(key),
+	), nil
+} //                                                                HashPassword
+
+// NeedsRehash returns true if 'encoded' was made with an algorithm
+// or parameters weaker than the current ones, or isn't a valid hash.
+// Rehash the password when the user next logs in successfully.
+func NeedsRehash(encoded string) bool {
+	ph, err := parsePasswordHash(encoded)
+	if err != nil {
+		return true
+	}
+	return ph.algorithm != passwordAlgorithm ||
+		ph.iterations < PasswordIterations() ||
+		len(ph.key) < passwordKeyLen ||
+		len(ph.salt) < passwordSaltLen
+} //                                                                 NeedsRehash
+
+// VerifyPassword returns true if 'password' matches the encoded hash.
+// The comparison is done in constant time. Returns false if
+// 'encoded' is not a valid password hash.
+func VerifyPassword(password, encoded string) bool {
+	ph, err := parsePasswordHash(encoded)
+	if err != nil {
+		return false
+	}
+	key := pbkdf2(passwordAlgorithms[ph.algorithm],
+		[]byte(password), ph.salt, ph.iterations, len(ph.key))
+	return subtle.ConstantTimeCompare(key, ph.key) == 1
+} //                                                              VerifyPassword
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// isPasswordHash returns true if 's' looks like
+// a hash encoded by HashPassword().
+func isPasswordHash(s string) bool {
+	return strings.HasPrefix(s, "$pbkdf2-")
+} //                                                              isPasswordHash
+
+// parsePasswordHash splits an encoded hash into its parts. Legacy and
+// invalid hashes are expected when users log in, so errors are only
+// returned, not logged.
+func parsePasswordHash(encoded string) (passwordHash, error) {
+	var ret passwordHash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 || parts[0] != "" {
+		return ret, errors.New("invalid password hash format")
+	}
+	ret.algorithm = parts[1]
+	if passwordAlgorithms[ret.algorithm] == nil {
+		return ret, errors.New("invalid password hash algorithm")
+	}
+	keyLen := -1
+	for _, param := range strings.Split(parts[2], ",") {
+		i := strings.Index(param, "=")
+		if i == -1 {
+			return ret, errors.New("invalid password hash parameters")
+		}
+		n, err := strconv.Atoi(param[i+1:])
+		if err != nil || n < 1 {
+			return ret, errors.New("invalid password hash parameters")
+		}
+		switch param[:i] {
+		case "i":
+			ret.iterations = n
+		case "l":
+			keyLen = n
+		}
+	}
+	var err error
+	enc := base64.RawStdEncoding
+	if ret.salt, err = enc.DecodeString(parts[3]); err != nil {
+		return ret, errors.New("invalid password hash salt")
+	}
+	if ret.key, err = enc.DecodeString(parts[4]); err != nil {
+		return ret, errors.New("invalid password hash key")
+	}
+	if ret.iterations < 1 || len(ret.key) == 0 ||
+		(keyLen != -1 && keyLen != len(ret.key)) {
+		return ret, errors.New("invalid password hash parameters")
+	}
+	return ret, nil
+} //                                                           parsePasswordHash
+
+// pbkdf2 derives a key of 'keyLen' bytes from a password
+// as specified in RFC 8018, using HMAC with 'newHash'.
+func pbkdf2(newHash func() hash.Hash, password, salt []byte,
+	iterations, keyLen int) []byte {
+	prf := hmac.New(newHash, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+	var (
+		ret = make([]byte, 0, numBlocks*hashLen)
+		buf [4]byte
+		u   = make([]byte, 0, hashLen)
+		t   = make([]byte, hashLen)
+	)
+	for block := 1; block <= numBlocks; block++ {
+		// U1 = PRF(password, salt || INT(block))
+		prf.Reset()
+		prf.Write(salt)
+		buf[0] = byte(block >> 24)
+		buf[1] = byte(block >> 16)
+		buf[2] = byte(block >> 8)
+		buf[3] = byte(block)
+		prf.Write(buf[:4])
+		u = prf.Sum(u[:0])
+		copy(t, u)
+		// Un = PRF(password, Un-1), T = U1 ^ U2 ^ ... ^ Un
+		for n := 2; n <= iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range t {
+				t[i] ^= u[i]
+			}
+		}
+		ret = append(ret, t...)
+	}
+	return ret[:keyLen]
+} //                                                                      pbkdf2
+
+// end
@@ -0,0 +1,153 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                            zr-web/[password_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Functions
+//   Test_pswd_HashPassword_
+//   Test_pswd_NeedsRehash_
+//   Test_pswd_VerifyPassword_
+//
+// # Support (File Scope)
+//   Test_pswd_pbkdf2_
+
+//  to test all items in password.go use:
+//      go test --run Test_pswd_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"strings"
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Functions
+
+// go test --run Test_pswd_HashPassword_
+func Test_pswd_HashPassword_(t *testing.T) {
+	zr.TBegin(t)
+	// HashPassword(password string) (string, error)
+	//
+	defer SetPasswordIterations(DefaultPasswordIterations)
+	SetPasswordIterations(1000)
+	//
+	h1, err := HashPassword("correct horse")
+	zr.TEqual(t, err, nil)
+	zr.TTrue(t, strings.HasPrefix(h1, "$pbkdf2-sha256$i=1000,l=32$"))
+	zr.TEqual(t, len(strings.Split(h1, "$")), 5)
+	//
+	// every hash gets a new salt
+	h2, _ := HashPassword("correct horse")
+	zr.TTrue(t, h1 != h2)
+} //                                                     Test_pswd_HashPassword_
+
+// go test --run Test_pswd_NeedsRehash_
+func Test_pswd_NeedsRehash_(t *testing.T) {
+	zr.TBegin(t)
+	// NeedsRehash(encoded string) bool
+	//
+	defer SetPasswordIterations(DefaultPasswordIterations)
+	SetPasswordIterations(1000)
+	h, _ := HashPassword("pw")
+	zr.TFalse(t, NeedsRehash(h))
+	//
+	SetPasswordIterations(2000)
+	zr.TTrue(t, NeedsRehash(h))
+	zr.TTrue(t, NeedsRehash(""))
+	zr.TTrue(t, NeedsRehash("plain text"))
+	zr.TTrue(t, NeedsRehash("$pbkdf2-sha512$i=5000,l=64$"+
+		"c2FsdHNhbHRzYWx0c2FsdA$"+strings.Repeat("A", 86)))
+} //                                                      Test_pswd_NeedsRehash_
+
+// go test --run Test_pswd_VerifyPassword_
+func Test_pswd_VerifyPassword_(t *testing.T) {
+	zr.TBegin(t)
+	// VerifyPassword(password, encoded string) bool
+	//
+	defer SetPasswordIterations(DefaultPasswordIterations)
+	SetPasswordIterations(1000)
+	h, _ := HashPassword("correct horse")
+	zr.TTrue(t, VerifyPassword("correct horse", h))
+	zr.TFalse(t, VerifyPassword("correct horse ", h))
+	zr.TFalse(t, VerifyPassword("", h))
+	//
+	// known vector: PBKDF2-HMAC-SHA256, "passwd", "salt", 1 iteration
+	// (RFC 7914, section 11)
+	const known = "$pbkdf2-sha256$i=1,l=64$c2FsdA$" +
+		"VawEblbjCJ/sFpHCJUS2BflBhSFt3gRl5oudV8INrLxJypzM8Xm2RZkWZLOdd+8x" +
+		"fHG4RbHjC9UJESBB06GXgw"
+	zr.TTrue(t, VerifyPassword("passwd", known))
+	zr.TFalse(t, VerifyPassword("passwd2", known))
+	//
+	// malformed hashes, which are not logged as errors
+	count := zr.GetErrorCount()
+	for _, s := range []string{
+		"",
+		"passwd",
+		"$pbkdf2-md5$i=1,l=64$c2FsdA$AAAA",
+		"$pbkdf2-sha256$i=0$c2FsdA$AAAA",
+		"$pbkdf2-sha256$i=1,l=3$c2FsdA$AAAA",
+		"$pbkdf2-sha256$i=x$c2FsdA$AAAA",
+		"$pbkdf2-sha256$i=1$!!!$AAAA",
+	} {
+		zr.TFalse(t, VerifyPassword("passwd", s))
+		zr.TTrue(t, NeedsRehash(s))
+	}
+	zr.TEqual(t, zr.GetErrorCount(), count)
+	//
+	// BasicAuth accepts password hashes
+	auth := &BasicAuth{Users: map[string]string{"ann": known}}
+	zr.TTrue(t, auth.check("ann", "passwd"))
+	zr.TFalse(t, auth.check("ann", "secret"))
+	zr.TFalse(t, auth.check("bob", "passwd"))
+} //                                                   Test_pswd_VerifyPassword_
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// go test --run Test_pswd_pbkdf2_
+func Test_pswd_pbkdf2_(t *testing.T) {
+	zr.TBegin(t)
+	// pbkdf2(newHash func() hash.Hash, password, salt []byte,
+	//     iterations, keyLen int) []byte
+	//
+	test := func(newHash func() hash.Hash, password, salt string,
+		iterations, keyLen int, expect string) {
+		key := pbkdf2(newHash, []byte(password), []byte(salt),
+			iterations, keyLen)
+		zr.TEqual(t, hex.EncodeToString(key), expect)
+	}
+	// RFC 6070
+	test(sha1.New, "password", "salt", 4096, 20,
+		"4b007901b765489abead49d926f721d065a429c1")
+	test(sha1.New, "passwordPASSWORDpassword",
+		"saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, 25,
+		"3d2eec4fe41c849b80c8d83662c0e44a8b291a964cf2f07038")
+	//
+	// RFC 7914
+	test(sha256.New, "passwd", "salt", 1, 64,
+		"55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"+
+			"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783")
+	test(sha256.New, "Password", "NaCl", 80000, 64,
+		"4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56"+
+			"a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d")
+	//
+	// SHA-512
+	test(sha512.New, "password", "salt", 1, 64,
+		"867f70cf1ade02cff3752599a3a53dc4af34c7a669815ae5d513554e1c8cf252"+
+			"c02d470a285a0501bad999bfe943c08f050235d7d68b1da55e63f73b60a57fce")
+} //                                                           Test_pswd_pbkdf2_
+
+// end
@@ -0,0 +1,295 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                    zr-web/[proxy.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Global Settings
+//   TrustedProxies() []string
+//   SetTrustedProxies(cidrs ...string) error
+//
+// # Support (File Scope)
+//   forwardedElement struct
+//   isTrustedProxy(ip string) bool
+//   lastHeaderValue(req *http.Request, name string) string
+//   parseForwarded(header string) []forwardedElement
+//   parseForwardedIP(s string) string
+//   remoteIP(req *http.Request) string
+//   requestClientIP(req *http.Request) string
+//   requestForwarded(req *http.Request) (elems []forwardedElement, ok bool)
+//   requestForwardedHop(req *http.Request) (fwd forwardedElement, ok bool)
+//   requestHost(req *http.Request) string
+//   requestScheme(req *http.Request) string
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/balacode/zr"
+)
+
+// trustedProxies holds the networks of reverse proxies (e.g. nginx)
+// whose 'Forwarded' and 'X-Forwarded-*' headers are believed.
+// Requests from any other address have these headers ignored,
+// since clients can send them to spoof their IP or scheme.
+// Empty by default, i.e. no proxy is trusted.
+var trustedProxies []*net.IPNet
+
+// trustedProxiesMutex guards trustedProxies
+var trustedProxiesMutex sync.RWMutex
+
+// -----------------------------------------------------------------------------
+// # Global Settings
+
+// TrustedProxies returns the list of trusted proxy networks in CIDR
+// notation, as set by SetTrustedProxies().
+func TrustedProxies() []string {
+	trustedProxiesMutex.RLock()
+	defer trustedProxiesMutex.RUnlock()
+	ret := make([]string, len(trustedProxies))
+	for i, network := range trustedProxies {
+		ret[i] = network.String()
+	}
+	return ret
+} //                                                              TrustedProxies
+
+// SetTrustedProxies specifies the reverse proxies whose forwarding
+// headers are used by Context's ClientIP(), Scheme() and Host().
+// Each item is a network in CIDR notation, e.g. "10.0.0.0/8",
+// or a single IP address, e.g. "127.0.0.1" or "::1".
+// Call it without arguments to stop trusting all proxies.
+// If any item is invalid, returns an error and leaves
+// the current setting unchanged.
+func SetTrustedProxies(cidrs ...string) error {
+	list := make([]*net.IPNet, 0, len(cidrs))
+	for _, s := range cidrs {
+		s = strings.TrimSpace(s)
+		if !strings.Contains(s, "/") {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return zr.Error(zr.EInvalidArg, "^cidrs", ":^", s)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			list = append(list, &net.IPNet{
+				IP:   ip,
+				Mask: net.CIDRMask(bits, bits),
+			})
+			continue
+		}
+		_, network, err := net.ParseCIDR(s)
+		if err != nil {
+			return zr.Error(zr.EInvalidArg, "^cidrs", ":^", s)
+		}
+		list = append(list, network)
+	}
+	trustedProxiesMutex.Lock()
+	trustedProxies = list
+	trustedProxiesMutex.Unlock()
+	return nil
+} //                                                           SetTrustedProxies
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// forwardedElement holds the parameters of one proxy hop
+// listed in a 'Forwarded' header (RFC 7239).
+type forwardedElement struct {
+	forIP string
+	host  string
+	proto string
+} //                                                            forwardedElement
+
+// isTrustedProxy returns true if 'ip' belongs
+// to one of the trusted proxy networks.
+func isTrustedProxy(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	trustedProxiesMutex.RLock()
+	defer trustedProxiesMutex.RUnlock()
+	for _, network := range trustedProxies {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+} //                                                              isTrustedProxy
+
+// lastHeaderValue returns the last value in the comma-separated
+// list of header 'name', i.e. the value added by the nearest proxy.
+func lastHeaderValue(req *http.Request, name string) string {
+	values := req.Header.Values(name)
+	if len(values) == 0 {
+		return ""
+	}
+	ret := values[len(values)-1]
+	if i := strings.LastIndex(ret, ","); i != -1 {
+		ret = ret[i+1:]
+	}
+	return strings.TrimSpace(ret)
+} //                                                             lastHeaderValue
+
+// parseForwarded parses the value of a 'Forwarded' header
+// into a list of elements, one for each proxy hop.
+func parseForwarded(header string) []forwardedElement {
+	var ret []forwardedElement
+	for _, elem := range strings.Split(header, ",") {
+		var fwd forwardedElement
+		for _, pair := range strings.Split(elem, ";") {
+			i := strings.Index(pair, "=")
+			if i == -1 {
+				continue
+			}
+			key := strings.ToLower(strings.TrimSpace(pair[:i]))
+			val := strings.Trim(strings.TrimSpace(pair[i+1:]), `"`)
+			switch key {
+			case "for":
+				fwd.forIP = parseForwardedIP(val)
+			case "host":
+				fwd.host = val
+			case "proto":
+				fwd.proto = strings.ToLower(val)
+			}
+		}
+		ret = append(ret, fwd)
+	}
+	return ret
+} //                                                              parseForwarded
+
+// parseForwardedIP extracts the IP address from a forwarded node
+// such as "192.0.2.60", "192.0.2.60:4711" or "[2001:db8::17]:4711".
+// Returns a blank string for obfuscated or unknown nodes.
+func parseForwardedIP(s string) string {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.Trim(s, "[]")
+	if net.ParseIP(s) == nil {
+		return ""
+	}
+	return s
+} //                                                            parseForwardedIP
+
+// remoteIP returns the IP address of the
+// immediate peer that sent the request.
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+} //                                                                    remoteIP
+
+// requestClientIP returns the IP address of the client that made the
+// request. When the request comes from a trusted proxy, the forwarded
+// addresses are checked from the nearest hop backwards, and the first
+// address that is not a trusted proxy is returned.
+func requestClientIP(req *http.Request) string {
+	ip := remoteIP(req)
+	if !isTrustedProxy(ip) {
+		return ip
+	}
+	var chain []string
+	if elems, ok := requestForwarded(req); ok {
+		for _, fwd := range elems {
+			chain = append(chain, fwd.forIP)
+		}
+	} else {
+		for _, header := range req.Header.Values("X-Forwarded-For") {
+			for _, s := range strings.Split(header, ",") {
+				chain = append(chain, parseForwardedIP(s))
+			}
+		}
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		if chain[i] == "" {
+			break // can't look past an obfuscated or invalid hop
+		}
+		ip = chain[i]
+		if !isTrustedProxy(ip) {
+			break
+		}
+	}
+	return ip
+} //                                                             requestClientIP
+
+// requestForwarded returns the parsed 'Forwarded' header of the request.
+// 'ok' is false if the request doesn't have a 'Forwarded' header.
+func requestForwarded(req *http.Request) (elems []forwardedElement, ok bool) {
+	values := req.Header.Values("Forwarded")
+	if len(values) == 0 {
+		return nil, false
+	}
+	return parseForwarded(strings.Join(values, ",")), true
+} //                                                            requestForwarded
+
+// requestForwardedHop returns the element of the 'Forwarded' header
+// added by the outermost trusted proxy, i.e. the proxy that received
+// the request from the client. Elements are checked from the nearest
+// hop backwards, like requestClientIP(), since the elements before
+// those added by trusted proxies can be forged by the client.
+// 'ok' is false if the request doesn't have a 'Forwarded' header.
+func requestForwardedHop(req *http.Request) (fwd forwardedElement, ok bool) {
+	elems, ok := requestForwarded(req)
+	if !ok || len(elems) == 0 {
+		return forwardedElement{}, ok
+	}
+	i := len(elems) - 1
+	for i > 0 && isTrustedProxy(elems[i].forIP) {
+		i-- // the previous element was added by this trusted proxy
+	}
+	return elems[i], true
+} //                                                         requestForwardedHop
+
+// requestHost returns the host (and port, if any) that the client
+// requested, taking forwarding headers of trusted proxies into account.
+// Only the values added by trusted proxies are used: see
+// requestForwardedHop() and lastHeaderValue().
+func requestHost(req *http.Request) string {
+	if !isTrustedProxy(remoteIP(req)) {
+		return req.Host
+	}
+	var host string
+	if fwd, ok := requestForwardedHop(req); ok {
+		host = fwd.host
+	} else {
+		host = lastHeaderValue(req, "X-Forwarded-Host")
+	}
+	if host == "" {
+		return req.Host
+	}
+	return host
+} //                                                                 requestHost
+
+// requestScheme returns "https" or "http" depending on how the client
+// connected, taking forwarding headers of trusted proxies into account.
+// Only the values added by trusted proxies are used, as in requestHost().
+func requestScheme(req *http.Request) string {
+	ret := "http"
+	if req.TLS != nil {
+		ret = "https"
+	}
+	if !isTrustedProxy(remoteIP(req)) {
+		return ret
+	}
+	var proto string
+	if fwd, ok := requestForwardedHop(req); ok {
+		proto = fwd.proto
+	} else {
+		proto = strings.ToLower(lastHeaderValue(req, "X-Forwarded-Proto"))
+	}
+	if proto == "http" || proto == "https" {
+		ret = proto
+	}
+	return ret
+} //                                                               requestScheme
+
+// end
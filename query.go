@@ -0,0 +1,234 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                    zr-web/[query.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	Find(), FindFirst() and Select() find elements in a tree of nodes
+//	with queries written like CSS selectors:
+//
+//	tag  #id  .class  [attr]  [attr=value]  [attr="value"]
+//	a b  (descendant)  a > b  (child)  a, b  (either)  *  (any)
+//
+//	Simple selectors can be combined, e.g. 'input.wide[type=text]'.
+//	Attribute values are compared after decoding entities, so
+//	[title="a & b"] matches title="a &amp; b". Like in browsers,
+//	the ancestors in a query can be outside the node searched,
+//	e.g. the <p> elements of a <div> match "body p".
+
+// # Methods (ob *Node)
+//   ) Select(query string) ([]*Node, error)
+//
+// # Support (File Scope)
+//   nodeQuery type
+//   nodeQueryPart struct
+//   (ob *Node) matches(query nodeQuery) bool
+//   (ob *Node) matchesPath(path []nodeQueryPart) bool
+//   (ob *nodeQueryPart) match(node *Node) bool
+//   parseNodeQuery(query string) (nodeQuery, error)
+
+import (
+	"errors"
+	"html"
+	"strings"
+)
+
+// nodeQuery is a parsed query used by Find(), FindFirst() and
+// Select(): the lists of compound selectors that were separated
+// by commas. A node matches the query if it matches any list.
+type nodeQuery [][]nodeQueryPart
+
+// nodeQueryPart is one compound selector, e.g. 'div.note[title]',
+// and the combinator that links it to the part before it.
+type nodeQueryPart struct {
+	child    bool // '>' combinator (otherwise descendant)
+	tag      string
+	id       string
+	classes  []string
+	attrs    [][2]string // name and value pairs
+	hasValue []bool      // false if only the attribute's presence is tested
+} //                                                               nodeQueryPart
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Node)
+
+// Select returns the elements under the node that match 'query', in
+// document order, like Find(), but returns an error if the query is
+// not valid, e.g. "div >" or "p[title". See the top of query.go.
+func (ob *Node) Select(query string) ([]*Node, error) {
+	q, err := parseNodeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*Node
+	for _, child := range ob.children {
+		child.Walk(func(node *Node) bool {
+			if node.matches(q) {
+				ret = append(ret, node)
+			}
+			return true
+		})
+	}
+	return ret, nil
+} //                                                                      Select
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// matches returns true if the node is an element that matches 'query'.
+func (ob *Node) matches(query nodeQuery) bool {
+	if ob.kind != NodeElement {
+		return false
+	}
+	for _, path := range query {
+		if ob.matchesPath(path) {
+			return true
+		}
+	}
+	return false
+} //                                                                     matches
+
+// matchesPath returns true if the node matches the last part
+// of 'path' and its ancestors match the preceding parts.
+func (ob *Node) matchesPath(path []nodeQueryPart) bool {
+	last := len(path) - 1
+	if ob.kind != NodeElement || !path[last].match(ob) {
+		return false
+	}
+	if last == 0 {
+		return true
+	}
+	for anc := ob.parent; anc != nil; anc = anc.parent {
+		if anc.matchesPath(path[:last]) {
+			return true
+		}
+		if path[last].child {
+			break
+		}
+	}
+	return false
+} //                                                                 matchesPath
+
+// match returns true if element 'node' matches the compound selector.
+func (ob *nodeQueryPart) match(node *Node) bool {
+	if ob.tag != "" && node.tag != ob.tag {
+		return false
+	}
+	if ob.id != "" && html.UnescapeString(node.attrs.Get("id")) != ob.id {
+		return false
+	}
+	for _, class := range ob.classes {
+		if !node.HasClass(class) {
+			return false
+		}
+	}
+	for i, attr := range ob.attrs {
+		if !node.attrs.Has(attr[0]) {
+			return false
+		}
+		if ob.hasValue[i] &&
+			html.UnescapeString(node.attrs.Get(attr[0])) != attr[1] {
+			return false
+		}
+	}
+	return true
+} //                                                                       match
+
+// parseNodeQuery parses a query like "div#main > p.note, a[href]"
+// into lists of compound selectors.
+func parseNodeQuery(query string) (nodeQuery, error) {
+	var ret nodeQuery
+	var path []nodeQueryPart
+	var part *nodeQueryPart
+	child := false
+	s := strings.TrimSpace(query)
+	if s == "" {
+		return nil, errors.New("blank query")
+	}
+	ident := func(i int) (string, int) {
+		start := i
+		for i < len(s) && strings.IndexByte(" \t\n>,.#[", s[i]) == -1 {
+			i++
+		}
+		return s[start:i], i
+	}
+	begin := func() {
+		if part == nil {
+			path = append(path, nodeQueryPart{child: child})
+			part = &path[len(path)-1]
+			child = false
+		}
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			part = nil
+			i++
+		case c == '>':
+			if part == nil && len(path) == 0 || child {
+				return nil, errors.New("misplaced '>' in " + query)
+			}
+			part, child = nil, true
+			i++
+		case c == ',':
+			if len(path) == 0 || child {
+				return nil, errors.New("misplaced ',' in " + query)
+			}
+			ret = append(ret, path)
+			path, part = nil, nil
+			i++
+		case c == '#' || c == '.':
+			begin()
+			var name string
+			name, i = ident(i + 1)
+			if name == "" {
+				return nil, errors.New("missing name in " + query)
+			}
+			if c == '#' {
+				part.id = name
+			} else {
+				part.classes = append(part.classes, name)
+			}
+		case c == '[':
+			begin()
+			end := strings.IndexByte(s[i:], ']')
+			if end == -1 {
+				return nil, errors.New("missing ']' in " + query)
+			}
+			inner := s[i+1 : i+end]
+			name, value, hasValue := inner, "", false
+			if eq := strings.IndexByte(inner, '='); eq != -1 {
+				name, value, hasValue = inner[:eq], inner[eq+1:], true
+				if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') &&
+					value[len(value)-1] == value[0] {
+					value = value[1 : len(value)-1]
+				}
+			}
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				return nil, errors.New("missing attribute in " + query)
+			}
+			part.attrs = append(part.attrs, [2]string{name, value})
+			part.hasValue = append(part.hasValue, hasValue)
+			i += end + 1
+		default:
+			if part != nil {
+				return nil, errors.New("misplaced tag in " + query)
+			}
+			begin()
+			var name string
+			name, i = ident(i)
+			if name != "*" {
+				part.tag = strings.ToLower(name)
+			}
+		}
+	}
+	if len(path) == 0 || child {
+		return nil, errors.New("incomplete query " + query)
+	}
+	return append(ret, path), nil
+} //                                                              parseNodeQuery
+
+// end
@@ -0,0 +1,112 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                               zr-web/[query_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Methods (ob *Node)
+//   Test_qury_Node_Select_
+//
+// # Support (File Scope)
+//   Test_qury_parseNodeQuery_
+
+//  to test all items in query.go use:
+//      go test --run Test_qury_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Node)
+
+// go test --run Test_qury_Node_Select_
+func Test_qury_Node_Select_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Node) Select(query string) ([]*Node, error)
+	//
+	doc := ParseHTML(`
+	<div id="main" class="page wide">
+		<form id="login" method="post">
+			<input name="user" type="text" class="field">
+			<input name="pass" type="password" class="field">
+			<button type="submit" title="a &amp; b">Log in</button>
+		</form>
+		<p class="note">A <b>bold</b> note</p>
+	</div>
+	<p class="note">Outside</p>`)
+	test := func(query string, expect ...string) {
+		found, err := doc.Select(query)
+		zr.TEqual(t, err, nil)
+		var got []string
+		for _, node := range found {
+			got = append(got, node.Tag()+":"+node.Attr("name"))
+		}
+		zr.TEqual(t, strings.Join(got, "|"), strings.Join(expect, "|"))
+	}
+	test("input", "input:user", "input:pass")
+	test("#login input[type=password]", "input:pass")
+	test(`input[name="user"]`, "input:user")
+	test("form > .field", "input:user", "input:pass")
+	test("div > input")
+	test("div.page.wide p.note", "p:")
+	test("div.page.narrow p")
+	test("p.note", "p:", "p:")
+	test("button, b", "button:", "b:")
+	test("[method]", "form:")
+	test("*#main > form > button", "button:")
+	test(`[title="a & b"]`, "button:")
+	//
+	// ancestors can be outside the node searched
+	form := doc.FindFirst("form")
+	found, err := form.Select("div input")
+	zr.TEqual(t, err, nil)
+	zr.TEqual(t, len(found), 2)
+	//
+	// queries that are not valid give an error
+	found, err = doc.Select("p >")
+	zr.TTrue(t, err != nil)
+	zr.TEqual(t, len(found), 0)
+} //                                                      Test_qury_Node_Select_
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// go test --run Test_qury_parseNodeQuery_
+func Test_qury_parseNodeQuery_(t *testing.T) {
+	zr.TBegin(t)
+	// parseNodeQuery(query string) (nodeQuery, error)
+	//
+	paths, err := parseNodeQuery("div#main > p.a.b[title], a[href='/x']")
+	zr.TEqual(t, err, nil)
+	zr.TEqual(t, len(paths), 2)
+	if len(paths) == 2 {
+		zr.TEqual(t, len(paths[0]), 2)
+		zr.TEqual(t, paths[0][0].tag, "div")
+		zr.TEqual(t, paths[0][0].id, "main")
+		zr.TEqual(t, paths[0][1].child, true)
+		zr.TEqual(t, paths[0][1].tag, "p")
+		zr.TEqual(t, strings.Join(paths[0][1].classes, " "), "a b")
+		zr.TEqual(t, paths[0][1].attrs[0][0], "title")
+		zr.TEqual(t, paths[0][1].hasValue[0], false)
+		zr.TEqual(t, paths[1][0].attrs[0][1], "/x")
+		zr.TEqual(t, paths[1][0].hasValue[0], true)
+	}
+	for _, bad := range []string{
+		"", "  ", "> p", "p >", "p > > a", "p,", ", p", "p.", "#",
+		"p[title", "[]", "p div.x span.",
+	} {
+		_, err := parseNodeQuery(bad)
+		zr.TTrue(t, err != nil)
+	}
+} //                                                   Test_qury_parseNodeQuery_
+
+// end
@@ -0,0 +1,299 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                zr-web/[ratelimit.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	RateLimiter limits how often each client can make requests, using
+//	a token bucket per client. It is useful to slow down brute-force
+//	attacks on login forms:
+//
+//	limiter := &web.RateLimiter{
+//		Limit:  60,
+//		Period: time.Minute,
+//		Routes: map[string]web.RateLimit{
+//			"/login": {Limit: 5, Period: time.Minute},
+//		},
+//	}
+//	http.Handle("/", limiter.Handler(http.HandlerFunc(mainServe)))
+
+//  MemoryRateLimitStore struct
+//  RateLimit struct
+//  RateLimitResult struct
+//  RateLimitStore interface
+//  RateLimiter struct
+//
+// # Key Functions
+//   RateLimitByIP(req *http.Request) string
+//   RateLimitBySession(sessions *Sessions) func(req *http.Request) string
+//
+// # Constructor
+//   NewMemoryRateLimitStore() *MemoryRateLimitStore
+//
+// # Methods (ob *MemoryRateLimitStore)
+//   ) Len() int
+//   ) Take(key string, limit RateLimit) RateLimitResult
+//
+// # Methods (ob *RateLimiter)
+//   ) Allow(req *http.Request) RateLimitResult
+//   ) Handler(next http.Handler) http.Handler
+//   ) HandlerFunc(next http.HandlerFunc) http.HandlerFunc
+//
+// # Support (File Scope)
+//   (ob *MemoryRateLimitStore) evict(now time.Time)
+//   (ob *RateLimiter) setHeaders(header http.Header, result RateLimitResult)
+//   ceilSeconds(d time.Duration) string
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit specifies how many requests a client can make per period.
+// Up to 'Limit' requests can be made in a burst, after which the client
+// regains one request every Period/Limit.
+type RateLimit struct {
+	Limit  int
+	Period time.Duration
+} //                                                                   RateLimit
+
+// RateLimitResult is the outcome of taking a token from a bucket.
+type RateLimitResult struct {
+	Allowed    bool          // true if the request can proceed
+	Limit      int           // size of the bucket
+	Remaining  int           // requests that can still be made now
+	Reset      time.Duration // time until the bucket is full again
+	RetryAfter time.Duration // time until the next request is allowed
+} //                                                             RateLimitResult
+
+// RateLimitStore holds the token buckets of a RateLimiter.
+// The in-memory store can be replaced with a shared store
+// (e.g. in a database or cache) when running several servers.
+type RateLimitStore interface {
+	// Take takes one token from the bucket
+	// identified by 'key' and reports the result.
+	Take(key string, limit RateLimit) RateLimitResult
+} //                                                              RateLimitStore
+
+// RateLimiter is a middleware that replies with HTTP status
+// 429 (Too Many Requests) to clients that exceed their limit.
+type RateLimiter struct {
+	// Limit and Period specify the default limit for all routes.
+	Limit  int
+	Period time.Duration
+
+	// Routes specifies limits for individual URL paths, e.g. "/login",
+	// which override the default limit. Each route has its own buckets.
+	Routes map[string]RateLimit
+
+	// Key returns the key that identifies a client's bucket.
+	// Use RateLimitByIP (the default), RateLimitBySession(),
+	// or a custom function. Return a blank string to skip
+	// limiting a request.
+	Key func(req *http.Request) string
+
+	// Store holds the buckets.
+	// If not set, a MemoryRateLimitStore is created.
+	Store RateLimitStore
+
+	storeOnce sync.Once
+} //                                                                 RateLimiter
+
+// MemoryRateLimitStore keeps token buckets in memory. Buckets that
+// have refilled completely are evicted, since they are no different
+// from new buckets, so memory use is bound by the number of
+// recently active clients.
+type MemoryRateLimitStore struct {
+	buckets   map[string]*rateBucket
+	lastEvict time.Time
+	now       func() time.Time // replaced by tests
+	mutex     sync.Mutex
+} //                                                        MemoryRateLimitStore
+
+// rateBucket is a single token bucket.
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // when the bucket will be full again
+} //                                                                  rateBucket
+
+// rateLimitEvictInterval specifies how often
+// MemoryRateLimitStore looks for full buckets to evict.
+const rateLimitEvictInterval = time.Minute
+
+// -----------------------------------------------------------------------------
+// # Key Functions
+
+// RateLimitByIP returns the client's IP address as the rate limiting key.
+// See SetTrustedProxies() if the server runs behind a reverse proxy.
+func RateLimitByIP(req *http.Request) string {
+	return requestClientIP(req)
+} //                                                               RateLimitByIP
+
+// RateLimitBySession returns a key function that uses the client's
+// session ID as the rate limiting key. Only the IDs of sessions stored
+// in 'sessions' are used, since a client could get a new bucket for
+// every request by sending a new random cookie. Sessions.GetByCookie()
+// only stores IDs that the server issued. Requests without a known
+// session are limited by IP address.
+func RateLimitBySession(sessions *Sessions) func(req *http.Request) string {
+	return func(req *http.Request) string {
+		cookie, err := req.Cookie(sessionCookieName)
+		if err == nil && sessions != nil && sessions.Get(cookie.Value) != nil {
+			return "session:" + cookie.Value
+		}
+		return RateLimitByIP(req)
+	}
+} //                                                          RateLimitBySession
+
+// -----------------------------------------------------------------------------
+// # Constructor
+
+// NewMemoryRateLimitStore creates a new, empty MemoryRateLimitStore.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*rateBucket),
+		now:     time.Now,
+	}
+} //                                                     NewMemoryRateLimitStore
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *MemoryRateLimitStore)
+
+// Len returns the number of buckets in the store.
+func (ob *MemoryRateLimitStore) Len() int {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+	return len(ob.buckets)
+} //                                                                         Len
+
+// Take takes one token from the bucket identified by 'key'
+// and implements the RateLimitStore interface.
+func (ob *MemoryRateLimitStore) Take(key string, limit RateLimit,
+) RateLimitResult {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+	now := ob.now()
+	if now.Sub(ob.lastEvict) >= rateLimitEvictInterval {
+		ob.evict(now)
+	}
+	max := float64(limit.Limit)
+	if limit.Limit <= 0 || limit.Period <= 0 {
+		return RateLimitResult{Allowed: true, Limit: limit.Limit}
+	}
+	perToken := limit.Period / time.Duration(limit.Limit)
+	bucket, exists := ob.buckets[key]
+	if !exists {
+		bucket = &rateBucket{tokens: max, last: now}
+		ob.buckets[key] = bucket
+	}
+	// refill the bucket for the time passed since it was last used
+	elapsed := now.Sub(bucket.last)
+	if elapsed > 0 {
+		bucket.tokens = math.Min(max,
+			bucket.tokens+float64(elapsed)/float64(perToken))
+		bucket.last = now
+	}
+	result := RateLimitResult{Limit: limit.Limit}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - bucket.tokens) *
+			float64(perToken))
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = time.Duration((max - bucket.tokens) * float64(perToken))
+	bucket.full = now.Add(result.Reset)
+	return result
+} //                                                                        Take
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *RateLimiter)
+
+// Allow takes a token for the request from the appropriate bucket
+// and returns the result, without writing a reply. Use it to
+// apply rate limits within a handler, e.g. only to failed
+// login attempts.
+func (ob *RateLimiter) Allow(req *http.Request) RateLimitResult {
+	ob.storeOnce.Do(func() {
+		if ob.Store == nil {
+			ob.Store = NewMemoryRateLimitStore()
+		}
+	})
+	keyFunc := ob.Key
+	if keyFunc == nil {
+		keyFunc = RateLimitByIP
+	}
+	key := keyFunc(req)
+	limit := RateLimit{Limit: ob.Limit, Period: ob.Period}
+	route, routed := ob.Routes[req.URL.Path]
+	if routed {
+		limit = route
+	}
+	if key == "" || limit.Limit <= 0 || limit.Period <= 0 {
+		return RateLimitResult{Allowed: true, Limit: limit.Limit}
+	}
+	if routed {
+		key = req.URL.Path + " " + key
+	}
+	return ob.Store.Take(key, limit)
+} //                                                                       Allow
+
+// Handler wraps 'next' in the rate limiter. Allowed requests are passed
+// on to 'next' with 'RateLimit-*' headers. Other requests get HTTP
+// status 429 (Too Many Requests) and a 'Retry-After' header.
+func (ob *RateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		result := ob.Allow(req)
+		ob.setHeaders(w.Header(), result)
+		if !result.Allowed {
+			w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests),
+				http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+} //                                                                     Handler
+
+// HandlerFunc is like Handler(), but wraps a handler function such
+// as one passed to http.HandleFunc(), for limiting single routes.
+func (ob *RateLimiter) HandlerFunc(next http.HandlerFunc) http.HandlerFunc {
+	return ob.Handler(next).ServeHTTP
+} //                                                                 HandlerFunc
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// evict removes buckets that have refilled completely.
+func (ob *MemoryRateLimitStore) evict(now time.Time) {
+	for key, bucket := range ob.buckets {
+		if !now.Before(bucket.full) {
+			delete(ob.buckets, key)
+		}
+	}
+	ob.lastEvict = now
+} //                                                                       evict
+
+// setHeaders sets the 'RateLimit-*' headers for a limited request.
+func (ob *RateLimiter) setHeaders(header http.Header, result RateLimitResult) {
+	if result.Limit <= 0 {
+		return
+	}
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+} //                                                                  setHeaders
+
+// ceilSeconds formats a duration as a whole number
+// of seconds, rounded up, for use in HTTP headers.
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+} //                                                                 ceilSeconds
+
+// end
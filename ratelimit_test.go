@@ -0,0 +1,199 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                           zr-web/[ratelimit_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Key Functions
+//   Test_rtlm_RateLimitBySession_
+//
+// # Methods (ob *MemoryRateLimitStore)
+//   Test_rtlm_MemoryRateLimitStore_Take_
+//
+// # Methods (ob *RateLimiter)
+//   Test_rtlm_RateLimiter_Handler_
+
+//  to test all items in ratelimit.go use:
+//      go test --run Test_rtlm_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Key Functions
+
+// go test --run Test_rtlm_RateLimitBySession_
+func Test_rtlm_RateLimitBySession_(t *testing.T) {
+	zr.TBegin(t)
+	// RateLimitBySession(sessions *Sessions) func(req *http.Request) string
+	//
+	var sessions Sessions
+	ses := sessions.New()
+	key := RateLimitBySession(&sessions)
+	//
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "1.2.3.4:5678"
+	zr.TEqual(t, key(req), "1.2.3.4")
+	req.AddCookie(&http.Cookie{Name: "app_session_id", Value: ses.ID()})
+	zr.TEqual(t, key(req), "session:"+ses.ID())
+	//
+	// unknown session IDs are limited by IP address
+	req = httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "1.2.3.4:5678"
+	req.AddCookie(&http.Cookie{Name: "app_session_id", Value: "abc"})
+	zr.TEqual(t, key(req), "1.2.3.4")
+	//
+	// so clients sending a new cookie with each request are still limited
+	limiter := &RateLimiter{
+		Limit:  2,
+		Period: time.Minute,
+		Key:    key,
+	}
+	var codes []int
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("POST", "/login", nil)
+		req.RemoteAddr = "1.2.3.4:5678"
+		req.AddCookie(&http.Cookie{
+			Name:  "app_session_id",
+			Value: newSessionID(),
+		})
+		rec := httptest.NewRecorder()
+		limiter.HandlerFunc(func(http.ResponseWriter, *http.Request) {})(
+			rec, req)
+		codes = append(codes, rec.Code)
+	}
+	zr.TEqual(t, codes, []int{200, 200, http.StatusTooManyRequests})
+	//
+	// made-up cookie IDs are not stored by pages that use sessions, so
+	// visiting a page first doesn't give a rotated cookie its own bucket
+	page := func(w http.ResponseWriter, req *http.Request) {
+		sessions.GetByCookie(w, req)
+	}
+	codes = nil
+	for i := 0; i < 3; i++ {
+		id := newSessionID()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(&http.Cookie{Name: "app_session_id", Value: id})
+		rec := httptest.NewRecorder()
+		page(rec, req)
+		zr.TTrue(t, sessions.Get(id) == nil)
+		zr.TFalse(t, strings.Contains(rec.Header().Get("Set-Cookie"), id))
+		//
+		req = httptest.NewRequest("POST", "/login", nil)
+		req.RemoteAddr = "5.6.7.8:5678"
+		req.AddCookie(&http.Cookie{Name: "app_session_id", Value: id})
+		rec = httptest.NewRecorder()
+		limiter.HandlerFunc(page)(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	zr.TEqual(t, codes, []int{200, 200, http.StatusTooManyRequests})
+} //                                               Test_rtlm_RateLimitBySession_
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *MemoryRateLimitStore)
+
+// go test --run Test_rtlm_MemoryRateLimitStore_Take_
+func Test_rtlm_MemoryRateLimitStore_Take_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *MemoryRateLimitStore) Take(key string, limit RateLimit,
+	//     ) RateLimitResult
+	//
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	limit := RateLimit{Limit: 3, Period: 3 * time.Second}
+	//
+	// burst up to the limit
+	for i := 2; i >= 0; i-- {
+		result := store.Take("a", limit)
+		zr.TTrue(t, result.Allowed)
+		zr.TEqual(t, result.Remaining, i)
+	}
+	result := store.Take("a", limit)
+	zr.TFalse(t, result.Allowed)
+	zr.TEqual(t, result.RetryAfter, time.Second)
+	zr.TEqual(t, result.Reset, 3*time.Second)
+	//
+	// other keys have their own bucket
+	zr.TTrue(t, store.Take("b", limit).Allowed)
+	//
+	// one token is regained every Period/Limit
+	now = now.Add(time.Second)
+	zr.TTrue(t, store.Take("a", limit).Allowed)
+	zr.TFalse(t, store.Take("a", limit).Allowed)
+	//
+	// full buckets are evicted
+	zr.TEqual(t, store.Len(), 2)
+	now = now.Add(rateLimitEvictInterval)
+	store.Take("c", limit)
+	zr.TEqual(t, store.Len(), 1)
+} //                                        Test_rtlm_MemoryRateLimitStore_Take_
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *RateLimiter)
+
+// go test --run Test_rtlm_RateLimiter_Handler_
+func Test_rtlm_RateLimiter_Handler_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *RateLimiter) Handler(next http.Handler) http.Handler
+	//
+	limiter := &RateLimiter{
+		Limit:  2,
+		Period: time.Hour,
+		Routes: map[string]RateLimit{
+			"/login": {Limit: 1, Period: time.Minute},
+		},
+	}
+	handler := limiter.Handler(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte("ok"))
+		},
+	))
+	get := func(path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	rec := get("/", "1.1.1.1")
+	zr.TEqual(t, rec.Code, http.StatusOK)
+	zr.TEqual(t, rec.Header().Get("RateLimit-Limit"), "2")
+	zr.TEqual(t, rec.Header().Get("RateLimit-Remaining"), "1")
+	zr.TEqual(t, rec.Header().Get("RateLimit-Reset"), "1800")
+	zr.TEqual(t, get("/", "1.1.1.1").Code, http.StatusOK)
+	//
+	rec = get("/", "1.1.1.1")
+	zr.TEqual(t, rec.Code, http.StatusTooManyRequests)
+	zr.TEqual(t, rec.Header().Get("Retry-After"), "1800")
+	zr.TEqual(t, rec.Header().Get("RateLimit-Remaining"), "0")
+	//
+	// other clients aren't affected
+	zr.TEqual(t, get("/", "2.2.2.2").Code, http.StatusOK)
+	//
+	// routes have their own limits and buckets
+	zr.TEqual(t, get("/login", "1.1.1.1").Code, http.StatusOK)
+	rec = get("/login", "1.1.1.1")
+	zr.TEqual(t, rec.Code, http.StatusTooManyRequests)
+	zr.TEqual(t, rec.Header().Get("Retry-After"), "60")
+	//
+	// a blank key skips limiting, also on routes
+	limiter.Key = func(*http.Request) string { return "" }
+	for i := 0; i < 3; i++ {
+		zr.TEqual(t, get("/login", "3.3.3.3").Code, http.StatusOK)
+	}
+} //                                              Test_rtlm_RateLimiter_Handler_
+
+// end
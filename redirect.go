@@ -0,0 +1,115 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                 zr-web/[redirect.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Global Settings
+//   RedirectHosts() []string
+//   SetRedirectHosts(hosts ...string)
+//
+// # Support (File Scope)
+//   hostName(host string) string
+//   isSafeRedirect(target, host string) bool
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// redirectHosts holds the names of hosts, other than the host of the
+// current request, to which SafeRedirect() and RedirectBack() may send
+// clients. A name starting with "*." matches all its subdomains.
+// Empty by default, i.e. only same-host redirects are allowed.
+var redirectHosts []string
+
+// redirectHostsMutex guards redirectHosts
+var redirectHostsMutex sync.RWMutex
+
+// -----------------------------------------------------------------------------
+// # Global Settings
+
+// RedirectHosts returns the list of allowed
+// redirect hosts set by SetRedirectHosts().
+func RedirectHosts() []string {
+	redirectHostsMutex.RLock()
+	defer redirectHostsMutex.RUnlock()
+	return append([]string{}, redirectHosts...)
+} //                                                               RedirectHosts
+
+// SetRedirectHosts specifies the external hosts that Context's
+// SafeRedirect() and RedirectBack() are allowed to redirect to,
+// e.g. "accounts.example.com" or "*.example.com" for all subdomains.
+// Call it without arguments to allow only same-host redirects.
+func SetRedirectHosts(hosts ...string) {
+	list := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host != "" {
+			list = append(list, host)
+		}
+	}
+	redirectHostsMutex.Lock()
+	redirectHosts = list
+	redirectHostsMutex.Unlock()
+} //                                                            SetRedirectHosts
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// hostName returns the lowercase host name
+// from 'host' without the port number.
+func hostName(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+} //                                                                    hostName
+
+// isSafeRedirect returns true if 'target' is a relative URL, or an
+// absolute http/https URL pointing to 'host' or one of the allowed
+// redirect hosts. 'host' is the host of the current request.
+func isSafeRedirect(target, host string) bool {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return false
+	}
+	// browsers treat '\' like '/', so "/\evil.com" would leave the site
+	for _, r := range target {
+		if r == '\\' || r < ' ' || r == 0x7F {
+			return false
+		}
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	if u.Host == "" {
+		// scheme without host (e.g. "http:evil.com") isn't a relative URL
+		return u.Scheme == "" && u.Opaque == ""
+	}
+	name := hostName(u.Host)
+	if name == hostName(host) {
+		return true
+	}
+	redirectHostsMutex.RLock()
+	defer redirectHostsMutex.RUnlock()
+	for _, allowed := range redirectHosts {
+		if name == allowed {
+			return true
+		}
+		if strings.HasPrefix(allowed, "*.") &&
+			strings.HasSuffix(name, allowed[1:]) {
+			return true
+		}
+	}
+	return false
+} //                                                              isSafeRedirect
+
+// end
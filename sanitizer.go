@@ -0,0 +1,318 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                zr-web/[sanitizer.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	Sanitizer cleans HTML from users, e.g. a formatted comment, so that
+//	it can be shown in a page. Only the elements and attributes allowed
+//	by its policy are kept, and the result is a Buffer that is embedded
+//	as it is:
+//
+//	var comments = web.NewSanitizer()
+//
+//	web.Article(Class("comment"), comments.Sanitize(comment.Body))
+//
+//	The markup is read with ParseHTML() and written again from the
+//	parsed tree, with all text and attribute values escaped, so
+//	unclosed tags or other broken markup can't leak out of the
+//	sanitized content. Elements that are not allowed are removed,
+//	but their content is kept. Comments, processing instructions,
+//	'on*' event handler attributes and elements that run code or
+//	hold content that is not text (e.g. <script>, <style>, <iframe>,
+//	<svg>) are always removed, along with their content.
+
+//  Sanitizer struct
+//
+// # Constructor
+//   NewSanitizer() *Sanitizer
+//
+// # Methods (ob *Sanitizer)
+//   ) Sanitize(markup string) *Buffer
+//   ) SanitizeNode(node *Node) *Node
+//
+// # Support (File Scope)
+//   (ob *Sanitizer) isAllowedAttr(tag, name string) bool
+//   (ob *Sanitizer) isAllowedURL(url string) bool
+//   (ob *Sanitizer) sanitizeAttrs(el *Node) Attributes
+//   (ob *Sanitizer) sanitizeChildren(node, parent *Node)
+//   (ob *Sanitizer) sanitizeStyle(style string) string
+//   isSafeCSSValue(value string) bool
+
+import (
+	"html"
+	"strings"
+)
+
+// Sanitizer holds the policy of an HTML sanitizer.
+type Sanitizer struct {
+	// Elements lists the allowed elements, each with the attributes
+	// allowed on it, e.g. "a": {"href", "title"}.
+	Elements map[string][]string
+
+	// GlobalAttributes lists attributes allowed on all allowed elements.
+	GlobalAttributes []string
+
+	// URLSchemes lists the schemes allowed in attributes that hold
+	// URLs, e.g. 'href' and 'src'. Relative URLs are always allowed.
+	// Attributes with other URLs are removed.
+	URLSchemes []string
+
+	// LinkRel, if set, replaces the 'rel' attribute of links (<a>
+	// elements with a 'href'), e.g. "nofollow noopener" tells search
+	// engines not to follow links posted by users and prevents
+	// linked pages from accessing the page that opened them.
+	LinkRel string
+
+	// StyleProperties lists the CSS properties allowed in 'style'
+	// attributes, e.g. "color" and "text-align". If it is empty,
+	// 'style' attributes are removed. Values with CSS functions
+	// other than rgb(), rgba(), hsl(), hsla() and calc() are
+	// removed, so styles can't load URLs.
+	StyleProperties []string
+} //                                                                   Sanitizer
+
+// sanitizerDropElements lists elements that are always
+// removed, along with their content
+var sanitizerDropElements = map[string]bool{
+	"applet": true, "base": true, "embed": true, "frame": true,
+	"frameset": true, "head": true, "iframe": true, "link": true,
+	"math": true, "meta": true, "noembed": true, "noframes": true,
+	"noscript": true, "object": true, "plaintext": true, "script": true,
+	"select": true, "style": true, "svg": true, "template": true,
+	"textarea": true, "title": true, "xmp": true,
+}
+
+// sanitizerURLAttrs lists attributes that hold URLs
+var sanitizerURLAttrs = map[string]bool{
+	"action": true, "background": true, "cite": true, "formaction": true,
+	"href": true, "longdesc": true, "poster": true, "src": true,
+}
+
+// -----------------------------------------------------------------------------
+// # Constructor
+
+// NewSanitizer creates a Sanitizer with a policy for formatted user
+// comments: paragraphs, line breaks, headings, lists, quotes, code,
+// text formatting, images and links to http, https and mailto URLs,
+// which get rel="nofollow noopener". Styles are removed.
+func NewSanitizer() *Sanitizer {
+	return &Sanitizer{
+		Elements: map[string][]string{
+			"a": {"href"}, "b": nil, "blockquote": {"cite"}, "br": nil,
+			"code": nil, "del": nil, "em": nil, "h1": nil, "h2": nil,
+			"h3": nil, "h4": nil, "h5": nil, "h6": nil, "hr": nil,
+			"i": nil, "img": {"src", "alt", "width", "height"},
+			"ins": nil, "li": nil, "ol": {"start"}, "p": nil, "pre": nil,
+			"q": {"cite"}, "s": nil, "small": nil, "span": nil,
+			"strong": nil, "sub": nil, "sup": nil, "u": nil, "ul": nil,
+		},
+		GlobalAttributes: []string{"title", "lang", "dir"},
+		URLSchemes:       []string{"http", "https", "mailto"},
+		LinkRel:          "nofollow noopener",
+	}
+} //                                                                NewSanitizer
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Sanitizer)
+
+// Sanitize cleans HTML 'markup' by the policy and returns
+// a Buffer that can be embedded in pages without escaping.
+func (ob *Sanitizer) Sanitize(markup string) *Buffer {
+	return ob.SanitizeNode(ParseHTML(markup)).Render()
+} //                                                                    Sanitize
+
+// SanitizeNode returns a clean copy of the nodes under 'node'
+// (but not 'node' itself) in a fragment node. 'node' is
+// not changed. See Sanitize().
+func (ob *Sanitizer) SanitizeNode(node *Node) *Node {
+	ret := FragmentNode()
+	if node != nil {
+		ob.sanitizeChildren(node, ret)
+	}
+	return ret
+} //                                                                SanitizeNode
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// isAllowedAttr returns true if attribute 'name'
+// is allowed on element 'tag' by the policy.
+func (ob *Sanitizer) isAllowedAttr(tag, name string) bool {
+	if strings.HasPrefix(name, "on") {
+		return false // event handlers are never allowed
+	}
+	for _, it := range ob.Elements[tag] {
+		if it == name {
+			return true
+		}
+	}
+	for _, it := range ob.GlobalAttributes {
+		if it == name {
+			return true
+		}
+	}
+	return false
+} //                                                               isAllowedAttr
+
+// isAllowedURL returns true if 'url' is relative
+// or uses a scheme listed in URLSchemes.
+func (ob *Sanitizer) isAllowedURL(url string) bool {
+	// browsers ignore control characters and white space in schemes,
+	// e.g. 'java\tscript:', so they are removed before checking
+	url = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, url)
+	end := strings.IndexAny(url, ":/?#")
+	if end == -1 || url[end] != ':' {
+		return true
+	}
+	scheme := strings.ToLower(url[:end])
+	for _, it := range ob.URLSchemes {
+		if strings.ToLower(it) == scheme {
+			return true
+		}
+	}
+	return false
+} //                                                                isAllowedURL
+
+// sanitizeAttrs returns the allowed attributes of element 'el',
+// with entities decoded and values escaped again.
+func (ob *Sanitizer) sanitizeAttrs(el *Node) Attributes {
+	var ret Attributes
+	for _, attr := range el.attrs {
+		name := strings.ToLower(attr.Name)
+		value := html.UnescapeString(attr.Value)
+		switch {
+		case name == "style":
+			value = ob.sanitizeStyle(value)
+			if value == "" {
+				continue
+			}
+		case !ob.isAllowedAttr(el.tag, name):
+			continue
+		case sanitizerURLAttrs[name] && !ob.isAllowedURL(value):
+			continue
+		case name == "srcset":
+			// candidates are URLs followed by an optional size
+			for _, it := range strings.Split(value, ",") {
+				if !ob.isAllowedURL(strings.SplitN(
+					strings.TrimSpace(it), " ", 2)[0]) {
+					value = ""
+				}
+			}
+			if value == "" {
+				continue
+			}
+		}
+		if ret.Has(name) {
+			continue
+		}
+		value = htmlAttrEscaper.Replace(value)
+		ret = append(ret, Attribute{
+			Name:  name,
+			Value: value,
+			Bare:  attr.Bare && value == "",
+		})
+	}
+	if ob.LinkRel != "" && el.tag == "a" && ret.Has("href") {
+		ret.Set("rel", htmlAttrEscaper.Replace(ob.LinkRel))
+	}
+	return ret
+} //                                                               sanitizeAttrs
+
+// sanitizeChildren appends clean copies of the child
+// nodes of 'node' to 'parent', recursively.
+func (ob *Sanitizer) sanitizeChildren(node, parent *Node) {
+	for _, child := range node.children {
+		switch child.kind {
+		case NodeText:
+			parent.AppendChild(TextNode(child.Data()))
+			continue
+		case NodeFragment:
+			ob.sanitizeChildren(child, parent)
+			continue
+		case NodeComment, NodeRaw:
+			continue
+		}
+		tag := strings.ToLower(child.tag)
+		if sanitizerDropElements[tag] {
+			continue
+		}
+		if _, ok := ob.Elements[tag]; !ok {
+			ob.sanitizeChildren(child, parent)
+			continue
+		}
+		el := &Node{
+			kind:   NodeElement,
+			tag:    tag,
+			void:   IsVoidElement(tag),
+			parsed: true,
+		}
+		el.attrs = ob.sanitizeAttrs(child)
+		if !el.void {
+			ob.sanitizeChildren(child, el)
+		}
+		parent.AppendChild(el)
+	}
+} //                                                            sanitizeChildren
+
+// sanitizeStyle returns the declarations in CSS 'style' whose
+// properties are listed in StyleProperties and whose values
+// are safe, or a blank string if there are none.
+func (ob *Sanitizer) sanitizeStyle(style string) string {
+	var ret []string
+	for _, decl := range strings.Split(style, ";") {
+		i := strings.IndexByte(decl, ':')
+		if i == -1 {
+			continue
+		}
+		prop := strings.ToLower(strings.TrimSpace(decl[:i]))
+		value := strings.TrimSpace(decl[i+1:])
+		if value == "" || !isSafeCSSValue(value) {
+			continue
+		}
+		for _, it := range ob.StyleProperties {
+			if strings.ToLower(it) == prop {
+				ret = append(ret, prop+": "+value)
+				break
+			}
+		}
+	}
+	return strings.Join(ret, "; ")
+} //                                                               sanitizeStyle
+
+// isSafeCSSValue returns true if CSS 'value' has no escapes, comments,
+// quotes or brackets, and no functions other than rgb(), rgba(),
+// hsl(), hsla() and calc(), which can't load anything.
+func isSafeCSSValue(value string) bool {
+	value = strings.ToLower(value)
+	if strings.ContainsAny(value, "\\\"'<>{}@!;`") ||
+		strings.Contains(value, "/*") {
+		return false
+	}
+	for i := strings.IndexByte(value, '('); i != -1; {
+		start := i
+		for start > 0 && (value[start-1] >= 'a' && value[start-1] <= 'z' ||
+			value[start-1] == '-') {
+			start--
+		}
+		switch value[start:i] {
+		case "rgb", "rgba", "hsl", "hsla", "calc", "":
+		default:
+			return false
+		}
+		next := strings.IndexByte(value[i+1:], '(')
+		if next == -1 {
+			break
+		}
+		i += next + 1
+	}
+	return true
+} //                                                              isSafeCSSValue
+
+// end
@@ -0,0 +1,169 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                           zr-web/[sanitizer_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Methods (ob *Sanitizer)
+//   Test_sant_Sanitizer_Sanitize_
+//   Test_sant_Sanitizer_Sanitize_attacks_
+//   Test_sant_Sanitizer_Sanitize_styles_
+//   Test_sant_Sanitizer_SanitizeNode_
+
+//  to test all items in sanitizer.go use:
+//      go test --run Test_sant_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// go test --run Test_sant_Sanitizer_Sanitize_
+func Test_sant_Sanitizer_Sanitize_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Sanitizer) Sanitize(markup string) *Buffer
+	//
+	san := NewSanitizer()
+	test := func(input, expect string) {
+		zr.TEqual(t, san.Sanitize(input).String(), expect)
+	}
+	test("", "")
+	test("plain & simple", "plain &amp; simple")
+	test("<p>Hello <b>bold</b> <i>world</i></p>",
+		"<p>Hello <b>bold</b> <i>world</i></p>")
+	test("<ul><li>a<li>b</ul>", "<ul><li>a</li><li>b</li></ul>")
+	test("line<br/>break", "line<br>break")
+	//
+	// elements that are not allowed are removed, their content is kept
+	test(`<div class="x"><font color=red>text</font></div>`, "text")
+	//
+	// attributes that are not allowed are removed
+	test(`<p id="a" class="b" title="T">x</p>`, `<p title="T">x</p>`)
+	test(`<img src="/a.png" alt="A" data-x="1">`,
+		`<img src="/a.png" alt="A">`)
+	//
+	// links get rel="nofollow noopener"
+	test(`<a href="https://example.com/?a=1&amp;b=2" rel="author">x</a>`,
+		`<a href="https://example.com/?a=1&amp;b=2"`+
+			` rel="nofollow noopener">x</a>`)
+	test(`<a href="/page">x</a>`, `<a href="/page" rel="nofollow noopener">x</a>`)
+	test(`<a name="top">x</a>`, `<a>x</a>`)
+	//
+	// entities are decoded and escaped again
+	test(`<p title='&quot;a&quot; &lt;b&gt;'>&lt;tag&gt; &copy;</p>`,
+		`<p title="&quot;a&quot; &lt;b&gt;">&lt;tag&gt; ©</p>`)
+	//
+	// the result can be embedded as it is
+	zr.TEqual(t, Div(san.Sanitize("<b>x</b>")).String(),
+		"<div>\r\n<b>x</b></div>\r\n")
+	//
+	// a custom policy
+	san = &Sanitizer{
+		Elements:   map[string][]string{"a": {"href", "target"}},
+		URLSchemes: []string{"https"},
+	}
+	test(`<p><a href="https://x" target="_blank">a</a>`+
+		`<a href="http://x">b</a></p>`,
+		`<a href="https://x" target="_blank">a</a><a>b</a>`)
+} //                                               Test_sant_Sanitizer_Sanitize_
+
+// go test --run Test_sant_Sanitizer_Sanitize_attacks_
+func Test_sant_Sanitizer_Sanitize_attacks_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Sanitizer) Sanitize(markup string) *Buffer
+	//
+	san := NewSanitizer()
+	test := func(input, expect string) {
+		zr.TEqual(t, san.Sanitize(input).String(), expect)
+	}
+	// scripts and other active content are removed with their content
+	test(`a<script>alert(1)</script>b`, "ab")
+	test(`a<SCRIPT SRC=//x.js></SCRIPT>b`, "ab")
+	test(`a<style>body{display:none}</style>b`, "ab")
+	test(`a<iframe src="//x"></iframe><object data=x></object>b`, "ab")
+	test(`<svg><script>alert(1)</script></svg>x`, "x")
+	test(`<math><mi><style><img src=x onerror=alert(1)></style></math>x`,
+		"x")
+	test(`<noscript><p title="</noscript><img src=x onerror=alert(1)>">`,
+		"")
+	test(`a<!-- <script>alert(1)</script> -->b`, "ab")
+	//
+	// event handlers are removed, even if the policy allows them
+	test(`<img src="a.png" onerror="alert(1)" ONLOAD=x>`, `<img src="a.png">`)
+	san.GlobalAttributes = append(san.GlobalAttributes, "onclick")
+	test(`<b onclick="alert(1)">x</b>`, "<b>x</b>")
+	san = NewSanitizer()
+	//
+	// URLs with schemes that are not allowed are removed
+	test(`<a href="javascript:alert(1)">x</a>`, "<a>x</a>")
+	test(`<a href="JaVaScRiPt:alert(1)">x</a>`, "<a>x</a>")
+	test(`<a href=" java&#09;script:alert(1)">x</a>`, "<a>x</a>")
+	test(`<a href="&#106;avascript:alert(1)">x</a>`, "<a>x</a>")
+	test(`<img src="data:image/svg+xml;base64,PHN2Zz4=">`, "<img>")
+	test(`<a href="mailto:a@example.com">x</a>`,
+		`<a href="mailto:a@example.com" rel="nofollow noopener">x</a>`)
+	//
+	// broken markup can't leak out of the sanitized content
+	test(`<b>unclosed`, "<b>unclosed</b>")
+	test(`<p title="x`, `<p title="x"></p>`)
+	test(`&lt;script&gt;alert(1)&lt;/script&gt;`,
+		"&lt;script&gt;alert(1)&lt;/script&gt;")
+	test(`<<b></b>script>alert(1)<</b>/script>`,
+		"&lt;<b></b>script&gt;alert(1)&lt;/script&gt;")
+	test(`<div><span>x</div></span></b></p>`, "<span>x</span>")
+	test(`<p title="&quot;><script>alert(1)</script>">x</p>`,
+		`<p title="&quot;&gt;&lt;script&gt;alert(1)&lt;/script&gt;">x</p>`)
+} //                                       Test_sant_Sanitizer_Sanitize_attacks_
+
+// go test --run Test_sant_Sanitizer_Sanitize_styles_
+func Test_sant_Sanitizer_Sanitize_styles_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Sanitizer) Sanitize(markup string) *Buffer
+	//
+	// without StyleProperties, styles are removed
+	san := NewSanitizer()
+	zr.TEqual(t, san.Sanitize(`<p style="color: red">x</p>`).String(),
+		"<p>x</p>")
+	//
+	// with StyleProperties, only allowed properties and safe values are kept
+	san.StyleProperties = []string{"color", "text-align", "width"}
+	test := func(style, expect string) {
+		zr.TEqual(t,
+			san.Sanitize(`<p style="`+style+`">x</p>`).String(), expect)
+	}
+	test("COLOR: Red; font-size: 40px; text-align:center",
+		`<p style="color: Red; text-align: center">x</p>`)
+	test("color: rgb(255, 0, 0); width: calc(100% - 2em)",
+		`<p style="color: rgb(255, 0, 0); width: calc(100% - 2em)">x</p>`)
+	test("width: expression(alert(1))", "<p>x</p>")
+	test("color: red; width: url(javascript:alert(1))",
+		`<p style="color: red">x</p>`)
+	test(`color: \75 rl(x)`, "<p>x</p>")
+	test("color: red /* x */", "<p>x</p>")
+	test("color: &quot;red&quot;", "<p>x</p>")
+	test("color", "<p>x</p>")
+} //                                        Test_sant_Sanitizer_Sanitize_styles_
+
+// go test --run Test_sant_Sanitizer_SanitizeNode_
+func Test_sant_Sanitizer_SanitizeNode_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Sanitizer) SanitizeNode(node *Node) *Node
+	//
+	san := NewSanitizer()
+	zr.TEqual(t, san.SanitizeNode(nil).String(), "")
+	//
+	// the sanitized nodes are a copy that can be changed
+	node := ParseHTML(`<p onclick="x()">a <em>b</em></p>`)
+	clean := san.SanitizeNode(node)
+	clean.FindFirst("em").AddClass("note")
+	zr.TEqual(t, clean.String(), `<p>a <em class="note">b</em></p>`)
+	zr.TEqual(t, node.String(), `<p onclick="x()">a <em>b</em></p>`)
+} //                                           Test_sant_Sanitizer_SanitizeNode_
+
+// end
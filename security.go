@@ -0,0 +1,249 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                 zr-web/[security.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	SecurityHeaders is a middleware that adds security-related headers
+//	to every reply. Its Content-Security-Policy can refer to a nonce,
+//	a random value generated for every request, with '{nonce}':
+//
+//	secure := web.NewSecurityHeaders()
+//	secure.ContentSecurityPolicy = "default-src 'self'; " +
+//		"script-src 'self' 'nonce-{nonce}'; style-src 'self' 'nonce-{nonce}'"
+//	http.Handle("/", secure.Handler(http.HandlerFunc(mainServe)))
+//
+//	Inline <script> and <style> blocks then need the request's nonce.
+//	Make them with Context's JS() and CSS(), or JSNode() and CSSNode(),
+//	which add it:
+//
+//	ctx.Reply(web.HTML(web.Head(ctx.JS("init()"), ctx.CSS("p { }"))),
+//		"html")
+//
+//	The package-level JS() and CSS() don't add a nonce, since they
+//	don't know the request. For other inline blocks, use Nonce().
+//	The nonce is only written in markup made for its own request,
+//	never substituted in the reply, so it can't be given to
+//	injected content that found its way into a page.
+
+//  SecurityHeaders struct
+//
+// # Constructor
+//   NewSecurityHeaders() *SecurityHeaders
+//
+// # Methods (ob *SecurityHeaders)
+//   ) Handler(next http.Handler) http.Handler
+//   ) HandlerFunc(next http.HandlerFunc) http.HandlerFunc
+//
+// # Methods (ctx *Context)
+//   CSS(styles ...string) *Buffer
+//   CSSNode(styles ...string) *Node
+//   JS(scripts ...string) *Buffer
+//   JSNode(scripts ...string) *Node
+//   Nonce() string
+//
+// # Support (File Scope)
+//   newCSPNonce() string
+//   setCSPNonce(node *Node, tag, nonce string) *Node
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/balacode/zr"
+)
+
+// DefaultContentSecurityPolicy is the policy set by NewSecurityHeaders().
+// It only allows resources from the site itself, and inline scripts
+// and styles that carry the request's nonce.
+const DefaultContentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'nonce-{nonce}'; " +
+	"style-src 'self' 'nonce-{nonce}'; " +
+	"object-src 'none'; " +
+	"base-uri 'self'; " +
+	"frame-ancestors 'self'"
+
+// cspNonceContextKey is the request context key holding the nonce.
+type cspNonceContextKey struct{}
+
+// SecurityHeaders holds the settings of the security headers
+// middleware. Blank or zero fields leave the header out.
+type SecurityHeaders struct {
+	// HSTSMaxAge sets 'Strict-Transport-Security', which tells browsers
+	// to use only HTTPS for the site during this time. Only sent with
+	// replies to HTTPS requests. Start with a short time (e.g. one
+	// day), since it can't be revoked once browsers have seen it.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+
+	// NoSniff sets 'X-Content-Type-Options: nosniff', which stops
+	// browsers from guessing the media type of replies.
+	NoSniff bool
+
+	// FrameOptions sets 'X-Frame-Options', e.g. "DENY" or "SAMEORIGIN".
+	FrameOptions string
+
+	// ReferrerPolicy sets 'Referrer-Policy',
+	// e.g. "strict-origin-when-cross-origin".
+	ReferrerPolicy string
+
+	// ContentSecurityPolicy sets 'Content-Security-Policy'.
+	// Each '{nonce}' in it is replaced with the request's nonce.
+	ContentSecurityPolicy string
+
+	// ReportOnly sends the policy as
+	// 'Content-Security-Policy-Report-Only', so browsers report
+	// violations without blocking anything. Use it to try
+	// out a new policy on a live site.
+	ReportOnly bool
+
+	// ReportURI, if set, is added to the policy
+	// as the URL where browsers report violations.
+	ReportURI string
+} //                                                             SecurityHeaders
+
+// -----------------------------------------------------------------------------
+// # Constructor
+
+// NewSecurityHeaders creates SecurityHeaders with recommended
+// settings: HSTS for one year, 'nosniff', 'SAMEORIGIN' frames,
+// a strict referrer policy and DefaultContentSecurityPolicy.
+func NewSecurityHeaders() *SecurityHeaders {
+	return &SecurityHeaders{
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		NoSniff:               true,
+		FrameOptions:          "SAMEORIGIN",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+		ContentSecurityPolicy: DefaultContentSecurityPolicy,
+	}
+} //                                                          NewSecurityHeaders
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *SecurityHeaders)
+
+// Handler wraps 'next' so that its replies get the security headers.
+// If the policy uses '{nonce}', a new nonce is generated for every
+// request and made available to Context created in 'next'.
+func (ob *SecurityHeaders) Handler(next http.Handler) http.Handler {
+	useNonce := strings.Contains(ob.ContentSecurityPolicy, "{nonce}")
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		header := w.Header()
+		if ob.HSTSMaxAge > 0 && requestScheme(req) == "https" {
+			hsts := "max-age=" +
+				strconv.FormatInt(int64(ob.HSTSMaxAge.Seconds()), 10)
+			if ob.HSTSIncludeSubdomains {
+				hsts += "; includeSubDomains"
+			}
+			if ob.HSTSPreload {
+				hsts += "; preload"
+			}
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		if ob.NoSniff {
+			header.Set("X-Content-Type-Options", "nosniff")
+		}
+		if ob.FrameOptions != "" {
+			header.Set("X-Frame-Options", ob.FrameOptions)
+		}
+		if ob.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", ob.ReferrerPolicy)
+		}
+		if csp := ob.ContentSecurityPolicy; csp != "" {
+			if useNonce {
+				nonce := newCSPNonce()
+				csp = strings.ReplaceAll(csp, "{nonce}", nonce)
+				req = req.WithContext(context.WithValue(req.Context(),
+					cspNonceContextKey{}, nonce))
+			}
+			if ob.ReportURI != "" {
+				csp = strings.TrimRight(csp, "; ") +
+					"; report-uri " + ob.ReportURI
+			}
+			name := "Content-Security-Policy"
+			if ob.ReportOnly {
+				name += "-Report-Only"
+			}
+			header.Set(name, csp)
+		}
+		next.ServeHTTP(w, req)
+	})
+} //                                                                     Handler
+
+// HandlerFunc is like Handler(), but wraps a handler function
+// such as one passed to http.HandleFunc().
+func (ob *SecurityHeaders) HandlerFunc(next http.HandlerFunc) http.HandlerFunc {
+	return ob.Handler(next).ServeHTTP
+} //                                                                 HandlerFunc
+
+// -----------------------------------------------------------------------------
+// # Methods (ctx *Context)
+
+// CSS is like the package-level CSS(), but embedded
+// styles get the request's nonce. See Nonce().
+func (ctx *Context) CSS(styles ...string) *Buffer {
+	return ctx.CSSNode(styles...).Render()
+} //                                                                         CSS
+
+// CSSNode is like the package-level CSSNode(), but embedded
+// styles get the request's nonce. See Nonce().
+func (ctx *Context) CSSNode(styles ...string) *Node {
+	return setCSPNonce(CSSNode(styles...), "style", ctx.Nonce())
+} //                                                                     CSSNode
+
+// JS is like the package-level JS(), but embedded
+// snippets get the request's nonce. See Nonce().
+func (ctx *Context) JS(scripts ...string) *Buffer {
+	return ctx.JSNode(scripts...).Render()
+} //                                                                          JS
+
+// JSNode is like the package-level JSNode(), but embedded
+// snippets get the request's nonce. See Nonce().
+func (ctx *Context) JSNode(scripts ...string) *Node {
+	return setCSPNonce(JSNode(scripts...), "script", ctx.Nonce())
+} //                                                                      JSNode
+
+// Nonce returns the Content-Security-Policy nonce of the current request,
+// or a blank string if the request didn't pass through SecurityHeaders
+// with a nonce. Use it in the 'nonce' attribute of inline <script>
+// and <style> elements not made by Context's JS() or CSS().
+func (ctx *Context) Nonce() string {
+	nonce, _ := ctx.req.Context().Value(cspNonceContextKey{}).(string)
+	return nonce
+} //                                                                       Nonce
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// newCSPNonce generates a new random nonce.
+func newCSPNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		zr.Error("Can't generate CSP nonce:", err)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+} //                                                                 newCSPNonce
+
+// setCSPNonce sets the 'nonce' attribute of the inline 'tag' elements
+// in 'node', i.e. those without a 'src', if 'nonce' is not blank.
+// Returns 'node'.
+func setCSPNonce(node *Node, tag, nonce string) *Node {
+	if nonce == "" {
+		return node
+	}
+	node.Walk(func(it *Node) bool {
+		if it.Type() == NodeElement && it.Tag() == tag && !it.HasAttr("src") {
+			it.SetAttr("nonce", nonce)
+		}
+		return true
+	})
+	return node
+} //                                                                 setCSPNonce
+
+// end
@@ -0,0 +1,180 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                            zr-web/[security_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Methods (ob *SecurityHeaders)
+//   Test_secu_SecurityHeaders_Handler_
+//
+// # Methods (ctx *Context)
+//   Test_secu_Context_JS_
+//   Test_secu_Context_Nonce_
+
+//  to test all items in security.go use:
+//      go test --run Test_secu_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balacode/zr"
+)
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *SecurityHeaders)
+
+// go test --run Test_secu_SecurityHeaders_Handler_
+func Test_secu_SecurityHeaders_Handler_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *SecurityHeaders) Handler(next http.Handler) http.Handler
+	//
+	noop := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	serve := func(secure *SecurityHeaders, isTLS bool) http.Header {
+		req := httptest.NewRequest("GET", "/", nil)
+		if isTLS {
+			req.TLS = &tls.ConnectionState{}
+		}
+		rec := httptest.NewRecorder()
+		secure.Handler(noop).ServeHTTP(rec, req)
+		return rec.Header()
+	}
+	// default settings
+	{
+		header := serve(NewSecurityHeaders(), true)
+		zr.TEqual(t, header.Get("Strict-Transport-Security"),
+			"max-age=31536000")
+		zr.TEqual(t, header.Get("X-Content-Type-Options"), "nosniff")
+		zr.TEqual(t, header.Get("X-Frame-Options"), "SAMEORIGIN")
+		zr.TEqual(t, header.Get("Referrer-Policy"),
+			"strict-origin-when-cross-origin")
+		csp := header.Get("Content-Security-Policy")
+		zr.TTrue(t, strings.HasPrefix(csp,
+			"default-src 'self'; script-src 'self' 'nonce-"))
+		zr.TFalse(t, strings.Contains(csp, "{nonce}"))
+	}
+	// HSTS is only sent over HTTPS
+	{
+		header := serve(NewSecurityHeaders(), false)
+		zr.TEqual(t, header.Get("Strict-Transport-Security"), "")
+	}
+	// HSTS options, report-only policy
+	{
+		secure := &SecurityHeaders{
+			HSTSMaxAge:            time.Hour,
+			HSTSIncludeSubdomains: true,
+			HSTSPreload:           true,
+			ContentSecurityPolicy: "default-src 'self';",
+			ReportOnly:            true,
+			ReportURI:             "/csp-report",
+		}
+		header := serve(secure, true)
+		zr.TEqual(t, header.Get("Strict-Transport-Security"),
+			"max-age=3600; includeSubDomains; preload")
+		zr.TEqual(t, header.Get("Content-Security-Policy"), "")
+		zr.TEqual(t, header.Get("Content-Security-Policy-Report-Only"),
+			"default-src 'self'; report-uri /csp-report")
+		zr.TEqual(t, header.Get("X-Frame-Options"), "")
+	}
+} //                                          Test_secu_SecurityHeaders_Handler_
+
+// -----------------------------------------------------------------------------
+// # Methods (ctx *Context)
+
+// go test --run Test_secu_Context_JS_
+func Test_secu_Context_JS_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) CSS(styles ...string) *Buffer
+	// (ctx *Context) CSSNode(styles ...string) *Node
+	// (ctx *Context) JS(scripts ...string) *Buffer
+	// (ctx *Context) JSNode(scripts ...string) *Node
+	//
+	var nonce string
+	secure := NewSecurityHeaders()
+	handler := secure.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := NewContext(w, r, nil)
+		nonce = ctx.Nonce()
+		ctx.Reply(HTML(Head(ctx.JS("go()", "app.js"), ctx.CSS("p{}"))),
+			"html")
+	})
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/", nil))
+	zr.TEqual(t, rec.Body.String(), "<!DOCTYPE html>\r\n"+
+		"<html>\r\n<head>\r\n"+
+		`<script type="text/javascript" nonce="`+nonce+`">go()</script>`+
+		"\r\n"+
+		`<script type="text/javascript" src="app.js"></script>`+"\r\n"+
+		`<style type="text/css" nonce="`+nonce+`">`+"\r\np{}\r\n</style>\r\n"+
+		"</head>\r\n</html>\r\n")
+	//
+	// the package-level JS() and CSS() don't get the nonce
+	handler = secure.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := NewContext(w, r, nil)
+		nonce = ctx.Nonce()
+		ctx.Reply(JOIN(JS("go()"), CSS("p{}")), "html")
+	})
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/", nil))
+	zr.TEqual(t, rec.Body.String(), ""+
+		`<script type="text/javascript">go()</script>`+"\r\n"+
+		`<style type="text/css">`+"\r\np{}\r\n</style>\r\n")
+	//
+	// outside the middleware, there is no nonce
+	ctx := NewContext(httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/", nil), nil)
+	zr.TEqual(t, ctx.JS("go()").String(), JS("go()").String())
+	zr.TEqual(t, ctx.CSSNode("p{}").String(), CSSNode("p{}").String())
+} //                                                       Test_secu_Context_JS_
+
+// go test --run Test_secu_Context_Nonce_
+func Test_secu_Context_Nonce_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) Nonce() string
+	//
+	var nonce, csp string
+	var leaked []byte
+	secure := NewSecurityHeaders()
+	handler := secure.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := NewContext(w, r, nil)
+		nonce = ctx.Nonce()
+		// markup that ended up in a reply, e.g. in a JSON string, can't
+		// be used to get the nonce of another request
+		leaked = []byte(ctx.JS("go()").String())
+		ctx.Reply(leaked, "html")
+	})
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/", nil))
+	csp = rec.Header().Get("Content-Security-Policy")
+	//
+	zr.TEqual(t, len(nonce), 24)
+	zr.TTrue(t, strings.Contains(csp, "'nonce-"+nonce+"'"))
+	//
+	// each request gets a new nonce, and replies are sent as they are
+	prev := nonce
+	handler = secure.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := NewContext(w, r, nil)
+		nonce = ctx.Nonce()
+		ctx.Reply(leaked, "html")
+	})
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/", nil))
+	zr.TTrue(t, nonce != prev)
+	zr.TEqual(t, rec.Body.String(), string(leaked))
+	zr.TFalse(t, strings.Contains(rec.Body.String(), nonce))
+	//
+	// requests that didn't pass through the middleware have no nonce
+	ctx := NewContext(httptest.NewRecorder(),
+		httptest.NewRequest("GET", "/", nil), nil)
+	zr.TEqual(t, ctx.Nonce(), "")
+} //                                                    Test_secu_Context_Nonce_
+
+// end
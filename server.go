@@ -0,0 +1,238 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                   zr-web/[server.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	Server runs an HTTP server until the process receives SIGINT or
+//	SIGTERM, then shuts down gracefully: it stops accepting new
+//	connections, waits for requests in progress to finish and
+//	calls the OnShutdown() hooks.
+//
+//	var sessions web.Sessions
+//
+//	func main() {
+//		server := &web.Server{
+//			Addr:     "localhost:888",
+//			Handler:  http.HandlerFunc(mainServe),
+//			Sessions: &sessions,
+//		}
+//		server.OnShutdown(func(ctx context.Context) {
+//			log.Println("bye")
+//		})
+//		log.Fatal(server.ListenAndServe())
+//	}
+
+//  Server struct
+//
+// # Methods (ob *Server)
+//   ) ListenAndServe() error
+//   ) NewContext(w http.ResponseWriter, req *http.Request) Context
+//   ) OnShutdown(fn func(ctx context.Context))
+//   ) OnStart(fn func(addr net.Addr))
+//   ) Serve(ln net.Listener) error
+//   ) Shutdown(ctx context.Context) error
+//
+// # Support (File Scope)
+//   (ob *Server) httpServer() *http.Server
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/balacode/zr"
+)
+
+// DefaultShutdownTimeout is the default time that Server waits
+// for requests in progress to finish when shutting down.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// notifySignals is signal.Notify, replaced by tests
+var notifySignals = signal.Notify
+
+// Server bundles an HTTP handler with the sessions it uses,
+// TLS settings and timeouts. Zero timeouts mean no timeout,
+// except ShutdownTimeout, which defaults to 30 seconds.
+// Sessions is only passed to the contexts made by NewContext().
+type Server struct {
+	Addr     string
+	Handler  http.Handler
+	Sessions *Sessions
+
+	// TLSConfig, CertFile and KeyFile enable HTTPS.
+	// CertFile and KeyFile can be blank if TLSConfig
+	// already holds the certificates.
+	TLSConfig *tls.Config
+	CertFile  string
+	KeyFile   string
+
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+
+	onStart    []func(addr net.Addr)
+	onShutdown []func(ctx context.Context)
+	srv        *http.Server
+	shutdown   sync.Once
+	done       chan struct{} // closed when shutdown is complete
+	err        error         // error returned by Shutdown()
+	mutex      sync.Mutex
+} //                                                                      Server
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Server)
+
+// ListenAndServe listens on Addr and serves requests until the process
+// receives SIGINT or SIGTERM, or Shutdown() is called. It then shuts
+// down gracefully and returns the shutdown's error, if any.
+func (ob *Server) ListenAndServe() error {
+	addr := ob.Addr
+	if addr == "" {
+		addr = ":http"
+		if ob.TLSConfig != nil || ob.CertFile != "" {
+			addr = ":https"
+		}
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return zr.Error("Can't listen on", addr, ":", err)
+	}
+	return ob.Serve(ln)
+} //                                                              ListenAndServe
+
+// NewContext creates a Context for a request using the server's Sessions.
+func (ob *Server) NewContext(w http.ResponseWriter, req *http.Request,
+) Context {
+	return NewContext(w, req, ob.Sessions)
+} //                                                                  NewContext
+
+// OnShutdown adds a function to call after the server has stopped and
+// requests in progress have finished (or ShutdownTimeout elapsed).
+// Use it to stop your own background work, flush data and close
+// databases. Sessions needs no hook: it keeps sessions in memory
+// and runs nothing in the background. Hooks are called in the
+// order they were added. They share a new context with its own
+// ShutdownTimeout, which is not ended by a slow shutdown of the
+// server, and should return when 'ctx' is done.
+func (ob *Server) OnShutdown(fn func(ctx context.Context)) {
+	ob.mutex.Lock()
+	ob.onShutdown = append(ob.onShutdown, fn)
+	ob.mutex.Unlock()
+} //                                                                  OnShutdown
+
+// OnStart adds a function to call when the server starts
+// serving. 'addr' is the address the server listens on.
+func (ob *Server) OnStart(fn func(addr net.Addr)) {
+	ob.mutex.Lock()
+	ob.onStart = append(ob.onStart, fn)
+	ob.mutex.Unlock()
+} //                                                                     OnStart
+
+// Serve is like ListenAndServe(), but accepts
+// connections on an existing listener.
+func (ob *Server) Serve(ln net.Listener) error {
+	ob.mutex.Lock()
+	if ob.srv != nil {
+		ob.mutex.Unlock()
+		return zr.Error("Server already started")
+	}
+	ob.srv = ob.httpServer()
+	ob.done = make(chan struct{})
+	onStart := append([]func(net.Addr){}, ob.onStart...)
+	ob.mutex.Unlock()
+	//
+	sigCh := make(chan os.Signal, 1)
+	notifySignals(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	//
+	serveErr := make(chan error, 1)
+	go func() {
+		if ob.TLSConfig != nil || ob.CertFile != "" {
+			serveErr <- ob.srv.ServeTLS(ln, ob.CertFile, ob.KeyFile)
+			return
+		}
+		serveErr <- ob.srv.Serve(ln)
+	}()
+	for _, fn := range onStart {
+		fn(ln.Addr())
+	}
+	select {
+	case err := <-serveErr:
+		if err != http.ErrServerClosed {
+			return err
+		}
+		// Shutdown() was called: wait until it completes
+		<-ob.done
+		return ob.err
+	case <-sigCh:
+		return ob.Shutdown(context.Background())
+	}
+} //                                                                       Serve
+
+// Shutdown stops the server gracefully: it stops accepting connections,
+// waits up to ShutdownTimeout (or until 'ctx' is done) for requests in
+// progress to finish, then calls the OnShutdown() hooks with a new
+// context that times out after ShutdownTimeout. It is safe to call
+// more than once; later calls wait for the first to finish.
+func (ob *Server) Shutdown(ctx context.Context) error {
+	ob.mutex.Lock()
+	srv, done := ob.srv, ob.done
+	ob.mutex.Unlock()
+	if srv == nil {
+		return zr.Error("Server not started")
+	}
+	ob.shutdown.Do(func() {
+		timeout := ob.ShutdownTimeout
+		if timeout <= 0 {
+			timeout = DefaultShutdownTimeout
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		ob.err = srv.Shutdown(ctx)
+		cancel()
+		ob.mutex.Lock()
+		onShutdown := append([]func(context.Context){}, ob.onShutdown...)
+		ob.mutex.Unlock()
+		//
+		// the hooks get their own time, even if the wait used it all up
+		hookCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		for _, fn := range onShutdown {
+			fn(hookCtx)
+		}
+		cancel()
+		close(done)
+	})
+	<-done
+	return ob.err
+} //                                                                    Shutdown
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// httpServer creates the underlying http.Server.
+func (ob *Server) httpServer() *http.Server {
+	handler := ob.Handler
+	if handler == nil {
+		handler = http.DefaultServeMux
+	}
+	return &http.Server{
+		Addr:              ob.Addr,
+		Handler:           handler,
+		TLSConfig:         ob.TLSConfig,
+		ReadTimeout:       ob.ReadTimeout,
+		ReadHeaderTimeout: ob.ReadHeaderTimeout,
+		WriteTimeout:      ob.WriteTimeout,
+		IdleTimeout:       ob.IdleTimeout,
+	}
+} //                                                                  httpServer
+
+// end
@@ -0,0 +1,164 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                              zr-web/[server_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Methods (ob *Server)
+//   Test_srvr_Server_Serve_
+//   Test_srvr_Server_Shutdown_
+
+//  to test all items in server.go use:
+//      go test --run Test_srvr_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"testing"
+	"time"
+
+	"github.com/balacode/zr"
+)
+
+// startTestServer starts 'server' on a local port and returns its
+// URL and a channel that receives the result of Serve().
+func startTestServer(t *testing.T, server *Server,
+) (url string, result chan error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	server.OnStart(func(addr net.Addr) { close(started) })
+	result = make(chan error, 1)
+	go func() { result <- server.Serve(ln) }()
+	<-started
+	return "http://" + ln.Addr().String(), result
+} //                                                             startTestServer
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Server)
+
+// go test --run Test_srvr_Server_Serve_
+func Test_srvr_Server_Serve_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Server) Serve(ln net.Listener) error
+	//
+	// capture the signal channel so the test can send SIGTERM
+	sigCh := make(chan chan<- os.Signal, 1)
+	notifySignals = func(c chan<- os.Signal, sig ...os.Signal) {
+		sigCh <- c
+	}
+	defer func() { notifySignals = signal.Notify }()
+	//
+	var sessions Sessions
+	var shutdownCalled bool
+	server := &Server{Sessions: &sessions}
+	server.Handler = http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			ctx := server.NewContext(w, req)
+			ctx.Reply([]byte(ctx.Session.ID()), "txt")
+		},
+	)
+	server.OnShutdown(func(ctx context.Context) { shutdownCalled = true })
+	url, result := startTestServer(t, server)
+	//
+	resp, err := http.Get(url)
+	zr.TEqual(t, err, nil)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	zr.TEqual(t, len(body), 32)
+	//
+	// starting twice fails
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	zr.TTrue(t, server.Serve(ln) != nil)
+	ln.Close()
+	//
+	(<-sigCh) <- os.Interrupt
+	zr.TEqual(t, <-result, nil)
+	zr.TTrue(t, shutdownCalled)
+} //                                                     Test_srvr_Server_Serve_
+
+// go test --run Test_srvr_Server_Shutdown_
+func Test_srvr_Server_Shutdown_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Server) Shutdown(ctx context.Context) error
+	//
+	zr.TTrue(t, (&Server{}).Shutdown(context.Background()) != nil)
+	//
+	// requests in progress finish before the hooks are called
+	var (
+		inHandler = make(chan struct{})
+		release   = make(chan struct{})
+		events    = make(chan string, 3)
+	)
+	server := &Server{
+		Handler: http.HandlerFunc(
+			func(w http.ResponseWriter, req *http.Request) {
+				close(inHandler)
+				<-release
+				w.Write([]byte("done"))
+				events <- "request"
+			},
+		),
+	}
+	server.OnShutdown(func(ctx context.Context) { events <- "hook" })
+	url, result := startTestServer(t, server)
+	//
+	replied := make(chan string)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			replied <- err.Error()
+			return
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		replied <- string(body)
+	}()
+	<-inHandler
+	shutdownErr := make(chan error)
+	go func() { shutdownErr <- server.Shutdown(context.Background()) }()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	zr.TEqual(t, <-replied, "done")
+	zr.TEqual(t, <-shutdownErr, nil)
+	zr.TEqual(t, <-result, nil)
+	zr.TEqual(t, <-events, "request")
+	zr.TEqual(t, <-events, "hook")
+	//
+	// calling again returns immediately
+	zr.TEqual(t, server.Shutdown(context.Background()), nil)
+	//
+	// hooks get a new context when requests outlast ShutdownTimeout
+	inHandler, release = make(chan struct{}), make(chan struct{})
+	defer close(release)
+	hookErr := make(chan error, 1)
+	server = &Server{
+		Handler: http.HandlerFunc(
+			func(w http.ResponseWriter, req *http.Request) {
+				close(inHandler)
+				<-release
+			},
+		),
+		ShutdownTimeout: 50 * time.Millisecond,
+	}
+	server.OnShutdown(func(ctx context.Context) { hookErr <- ctx.Err() })
+	url, _ = startTestServer(t, server)
+	go http.Get(url)
+	<-inHandler
+	zr.TEqual(t, server.Shutdown(context.Background()),
+		context.DeadlineExceeded)
+	zr.TEqual(t, <-hookErr, nil)
+} //                                                  Test_srvr_Server_Shutdown_
+
+// end
@@ -13,13 +13,18 @@ import (
 	"github.com/balacode/zr"
 )
 
+// sessionCookieName is the name of the cookie that holds the session ID
+const sessionCookieName = "app_session_id"
+
 // Sessions _ _
 type Sessions struct {
 	m     map[string]*Session
 	mutex sync.Mutex
 } //                                                                    Sessions
 
-// GetByCookie _ _
+// GetByCookie returns the session whose ID is in the request's session
+// cookie. If there is no cookie, or its ID is not a stored session,
+// creates a session with a new ID and sends the ID in the cookie.
 func (ob *Sessions) GetByCookie(
 	w http.ResponseWriter,
 	req *http.Request,
@@ -27,25 +32,19 @@ func (ob *Sessions) GetByCookie(
 	ob.mutex.Lock()
 	defer ob.mutex.Unlock()
 	//
-	const CookieName = "app_session_id"
-	// if session cookie already exists, use its session ID..
-	var id string
-	cookie, err := req.Cookie(CookieName)
+	// if session cookie holds the ID of a stored session, return it..
+	cookie, err := req.Cookie(sessionCookieName)
 	if err == nil {
-		id = cookie.Value
-	} else {
-		// ..if not, create new session ID and save it in a cookie
-		id = strings.ReplaceAll(zr.UUID(), "-", "")
-		http.SetCookie(w, &http.Cookie{Name: CookieName, Value: id})
-	}
-	// if session is already stored, return pointer to stored session
-	ptr, exists := ob.m[id]
-	if exists {
-		return ptr
+		if ptr, exists := ob.m[cookie.Value]; exists {
+			return ptr
+		}
 	}
-	// if not, add a new Session to the map
-	ses := Session{id: id, m: map[string]string{}}
-	ptr = &ses
+	// ..if not, create new session ID and save it in a cookie. IDs
+	// the server didn't issue are not stored, so a client can't
+	// choose its own session ID (or get a new one for every request)
+	id := newSessionID()
+	setSessionCookie(w, req, id)
+	ptr := &Session{id: id, m: map[string]string{}}
 	if ob.m == nil {
 		ob.m = make(map[string]*Session, 0)
 	}
@@ -53,4 +52,86 @@ func (ob *Sessions) GetByCookie(
 	return ptr
 } //                                                                 GetByCookie
 
+// Get returns the session with the specified ID,
+// or nil if there is no such session.
+func (ob *Sessions) Get(id string) *Session {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+	return ob.m[id]
+} //                                                                         Get
+
+// New creates and stores a session with a new ID without sending a
+// cookie, e.g. to prepare a session in tests. Send its ID in the
+// 'app_session_id' cookie to continue the session.
+func (ob *Sessions) New() *Session {
+	ses := &Session{id: newSessionID(), m: map[string]string{}}
+	ob.mutex.Lock()
+	if ob.m == nil {
+		ob.m = make(map[string]*Session, 0)
+	}
+	ob.m[ses.id] = ses
+	ob.mutex.Unlock()
+	return ses
+} //                                                                         New
+
+// Len returns the number of active sessions.
+func (ob *Sessions) Len() int {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+	return len(ob.m)
+} //                                                                         Len
+
+// regenerate replaces session 'old' with a new session that has a new ID
+// and sends the new ID in the session cookie. If 'keep' is true, the
+// new session gets a copy of the old session's settings. The old ID
+// stops being valid, so an ID that was known to (or planted by)
+// someone else before login can't be used to take over the session.
+func (ob *Sessions) regenerate(
+	w http.ResponseWriter,
+	req *http.Request,
+	old *Session,
+	keep bool,
+) *Session {
+	ses := &Session{id: newSessionID(), m: map[string]string{}}
+	if old != nil && keep {
+		old.mutex.Lock()
+		for k, v := range old.m {
+			ses.m[k] = v
+		}
+		old.mutex.Unlock()
+	}
+	ob.mutex.Lock()
+	if ob.m == nil {
+		ob.m = make(map[string]*Session, 0)
+	}
+	if old != nil {
+		delete(ob.m, old.ID())
+	}
+	ob.m[ses.id] = ses
+	ob.mutex.Unlock()
+	setSessionCookie(w, req, ses.id)
+	return ses
+} //                                                                  regenerate
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// newSessionID generates a new random session ID.
+func newSessionID() string {
+	return strings.ReplaceAll(zr.UUID(), "-", "")
+} //                                                                newSessionID
+
+// setSessionCookie sends the session cookie holding 'id' to the client.
+// The cookie is marked Secure when the client connected over HTTPS.
+func setSessionCookie(w http.ResponseWriter, req *http.Request, id string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    id,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   requestScheme(req) == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+} //                                                            setSessionCookie
+
 // end
@@ -0,0 +1,234 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                      zr-web/[sse.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	EventStream sends Server-Sent Events (SSE) to a browser's EventSource.
+//	Get one by calling Context.SSE() in a handler, then call Send()
+//	in a loop until it returns an error or Done() is closed:
+//
+//	func statusServe(w http.ResponseWriter, req *http.Request) {
+//		ctx := web.NewContext(w, req, &sessions)
+//		stream, err := ctx.SSE()
+//		if err != nil {
+//			return
+//		}
+//		defer stream.Close()
+//		stream.KeepAlive(15 * time.Second)
+//		for {
+//			select {
+//			case <-stream.Done():
+//				return
+//			case status := <-updates:
+//				stream.Send("status", status.ID, status.Text)
+//			}
+//		}
+//	}
+
+//  EventStream struct
+//
+// # Constructor (ctx *Context)
+//   SSE() (*EventStream, error)
+//
+// # Methods (ob *EventStream)
+//   ) Close()
+//   ) Comment(text string) error
+//   ) Done() <-chan struct{}
+//   ) KeepAlive(interval time.Duration)
+//   ) LastEventID() string
+//   ) Retry(delay time.Duration) error
+//   ) Send(event, id, data string) error
+//
+// # Support (File Scope)
+//   (ob *EventStream) write(s string) error
+//   sseField(name, value string) string
+//   sseLines(s string) []string
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/balacode/zr"
+)
+
+// ErrStreamClosed is returned by EventStream methods
+// after the stream was closed or the client disconnected.
+var ErrStreamClosed = errors.New("event stream closed")
+
+// EventStream writes Server-Sent Events to the
+// client of a request. It is safe for concurrent use.
+type EventStream struct {
+	w           http.ResponseWriter
+	flusher     http.Flusher
+	req         *http.Request
+	lastEventID string
+	closed      bool
+	stop        chan struct{}  // closed by Close() to stop KeepAlive()
+	wg          sync.WaitGroup // tracks the KeepAlive() goroutine
+	mutex       sync.Mutex
+} //                                                                 EventStream
+
+// -----------------------------------------------------------------------------
+// # Constructor (ctx *Context)
+
+// SSE starts a Server-Sent Events reply to the current request and
+// returns an EventStream to send events with. It writes the reply's
+// headers and status immediately, so it must be called before any
+// other reply is made. Returns an error if the response writer
+// doesn't support flushing, which event streams require.
+func (ctx *Context) SSE() (*EventStream, error) {
+	flusher, ok := ctx.w.(http.Flusher)
+	if !ok {
+		return nil, zr.Error("Response writer does not support flushing")
+	}
+	header := ctx.w.Header()
+	header.Set("Content-Type", "text/event-stream; charset=utf-8")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // disables buffering in nginx
+	ctx.w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &EventStream{
+		w:           ctx.w,
+		flusher:     flusher,
+		req:         ctx.req,
+		lastEventID: strings.TrimSpace(ctx.req.Header.Get("Last-Event-ID")),
+		stop:        make(chan struct{}),
+	}, nil
+} //                                                                         SSE
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *EventStream)
+
+// Close stops the keep-alive comments started by KeepAlive()
+// and makes subsequent sends return ErrStreamClosed.
+// It doesn't close the connection: that happens
+// when the handler returns.
+func (ob *EventStream) Close() {
+	ob.mutex.Lock()
+	if !ob.closed {
+		ob.closed = true
+		close(ob.stop)
+	}
+	ob.mutex.Unlock()
+	ob.wg.Wait()
+} //                                                                       Close
+
+// Comment sends a comment line, which the browser ignores.
+// Comments are useful to keep idle connections open.
+func (ob *EventStream) Comment(text string) error {
+	var sb strings.Builder
+	for _, line := range sseLines(text) {
+		sb.WriteString(": " + line + "\n")
+	}
+	sb.WriteString("\n")
+	return ob.write(sb.String())
+} //                                                                     Comment
+
+// Done returns a channel that is closed when the client disconnects.
+func (ob *EventStream) Done() <-chan struct{} {
+	return ob.req.Context().Done()
+} //                                                                        Done
+
+// KeepAlive starts sending a comment every 'interval' until the
+// stream is closed or the client disconnects. Proxies and browsers
+// tend to drop connections that are idle for too long.
+func (ob *EventStream) KeepAlive(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ob.wg.Add(1)
+	go func() {
+		defer ob.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ob.stop:
+				return
+			case <-ob.Done():
+				return
+			case <-ticker.C:
+				if ob.Comment("keep-alive") != nil {
+					return
+				}
+			}
+		}
+	}()
+} //                                                                   KeepAlive
+
+// LastEventID returns the ID of the last event received by the client,
+// as sent by the browser in the 'Last-Event-ID' header when it
+// reconnects. Use it to resume sending from the next event.
+// Returns a blank string on the first connection.
+func (ob *EventStream) LastEventID() string {
+	return ob.lastEventID
+} //                                                                 LastEventID
+
+// Retry tells the browser how long to wait
+// before reconnecting if the connection is lost.
+func (ob *EventStream) Retry(delay time.Duration) error {
+	return ob.write(fmt.Sprintf("retry: %d\n\n", delay.Milliseconds()))
+} //                                                                       Retry
+
+// Send sends an event to the client.
+// 'event' is the event type, which the browser dispatches to listeners
+// added with addEventListener(). If blank, the browser fires 'message'.
+// 'id' is the event ID sent back in 'Last-Event-ID' on reconnection.
+// It is omitted if blank. 'data' is the event's payload,
+// which may span multiple lines.
+func (ob *EventStream) Send(event, id, data string) error {
+	var sb strings.Builder
+	if event != "" {
+		sb.WriteString(sseField("event", event))
+	}
+	if id != "" {
+		sb.WriteString(sseField("id", id))
+	}
+	for _, line := range sseLines(data) {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+	return ob.write(sb.String())
+} //                                                                        Send
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// write writes 's' to the client and flushes it immediately.
+func (ob *EventStream) write(s string) error {
+	ob.mutex.Lock()
+	defer ob.mutex.Unlock()
+	if ob.closed || ob.req.Context().Err() != nil {
+		return ErrStreamClosed
+	}
+	_, err := ob.w.Write([]byte(s))
+	if err != nil {
+		return err
+	}
+	ob.flusher.Flush()
+	return nil
+} //                                                                       write
+
+// sseField formats a single-line field. Line breaks are
+// removed from 'value', since they would end the field.
+func sseField(name, value string) string {
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	return name + ": " + value + "\n"
+} //                                                                    sseField
+
+// sseLines splits 's' into lines. Like the browser, it treats CR LF,
+// a lone CR and a lone LF as line breaks.
+func sseLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.Split(s, "\n")
+} //                                                                    sseLines
+
+// end
@@ -0,0 +1,169 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                 zr-web/[sse_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Constructor
+//   Test_sse_Context_SSE_
+//
+// # Methods
+//   Test_sse_EventStream_Close_
+//   Test_sse_EventStream_Comment_
+//   Test_sse_EventStream_KeepAlive_
+//   Test_sse_EventStream_LastEventID_
+//   Test_sse_EventStream_Retry_
+//   Test_sse_EventStream_Send_
+
+//  to test all items in sse.go use:
+//      go test --run Test_sse_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balacode/zr"
+)
+
+// newTestEventStream creates an EventStream
+// that writes to a response recorder.
+func newTestEventStream(t *testing.T, req *http.Request,
+) (*EventStream, *httptest.ResponseRecorder) {
+	if req == nil {
+		req = httptest.NewRequest("GET", "/events", nil)
+	}
+	rec := httptest.NewRecorder()
+	ctx := NewContext(rec, req, nil)
+	stream, err := ctx.SSE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stream, rec
+} //                                                          newTestEventStream
+
+// -----------------------------------------------------------------------------
+// # Constructor
+
+// go test --run Test_sse_Context_SSE_
+func Test_sse_Context_SSE_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) SSE() (*EventStream, error)
+	//
+	stream, rec := newTestEventStream(t, nil)
+	defer stream.Close()
+	zr.TEqual(t, rec.Code, http.StatusOK)
+	zr.TEqual(t, rec.Header().Get("Content-Type"),
+		"text/event-stream; charset=utf-8")
+	zr.TEqual(t, rec.Header().Get("Cache-Control"), "no-cache")
+	zr.TTrue(t, rec.Flushed)
+} //                                                       Test_sse_Context_SSE_
+
+// -----------------------------------------------------------------------------
+// # Methods
+
+// go test --run Test_sse_EventStream_Close_
+func Test_sse_EventStream_Close_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *EventStream) Close()
+	//
+	{
+		stream, _ := newTestEventStream(t, nil)
+		stream.Close()
+		stream.Close() // closing twice must not panic
+		zr.TEqual(t, stream.Send("", "", "x"), ErrStreamClosed)
+	}
+	// client disconnection
+	{
+		reqCtx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest("GET", "/events", nil)
+		stream, rec := newTestEventStream(t, req.WithContext(reqCtx))
+		zr.TEqual(t, stream.Send("", "", "a"), nil)
+		cancel()
+		<-stream.Done()
+		zr.TEqual(t, stream.Send("", "", "b"), ErrStreamClosed)
+		zr.TEqual(t, rec.Body.String(), "data: a\n\n")
+	}
+} //                                                 Test_sse_EventStream_Close_
+
+// go test --run Test_sse_EventStream_Comment_
+func Test_sse_EventStream_Comment_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *EventStream) Comment(text string) error
+	//
+	test := func(text, expect string) {
+		stream, rec := newTestEventStream(t, nil)
+		zr.TEqual(t, stream.Comment(text), nil)
+		zr.TEqual(t, rec.Body.String(), expect)
+	}
+	test("ping", ": ping\n\n")
+	test("a\nb\r\nc", ": a\n: b\n: c\n\n")
+	test("a\rdata: x", ": a\n: data: x\n\n")
+} //                                               Test_sse_EventStream_Comment_
+
+// go test --run Test_sse_EventStream_KeepAlive_
+func Test_sse_EventStream_KeepAlive_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *EventStream) KeepAlive(interval time.Duration)
+	//
+	stream, rec := newTestEventStream(t, nil)
+	stream.KeepAlive(time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	stream.Close()
+	zr.TTrue(t, strings.HasPrefix(rec.Body.String(), ": keep-alive\n\n"))
+} //                                             Test_sse_EventStream_KeepAlive_
+
+// go test --run Test_sse_EventStream_LastEventID_
+func Test_sse_EventStream_LastEventID_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *EventStream) LastEventID() string
+	//
+	{
+		stream, _ := newTestEventStream(t, nil)
+		zr.TEqual(t, stream.LastEventID(), "")
+	}
+	{
+		req := httptest.NewRequest("GET", "/events", nil)
+		req.Header.Set("Last-Event-ID", "42")
+		stream, _ := newTestEventStream(t, req)
+		zr.TEqual(t, stream.LastEventID(), "42")
+	}
+} //                                           Test_sse_EventStream_LastEventID_
+
+// go test --run Test_sse_EventStream_Retry_
+func Test_sse_EventStream_Retry_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *EventStream) Retry(delay time.Duration) error
+	//
+	stream, rec := newTestEventStream(t, nil)
+	zr.TEqual(t, stream.Retry(3*time.Second), nil)
+	zr.TEqual(t, rec.Body.String(), "retry: 3000\n\n")
+} //                                                 Test_sse_EventStream_Retry_
+
+// go test --run Test_sse_EventStream_Send_
+func Test_sse_EventStream_Send_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *EventStream) Send(event, id, data string) error
+	//
+	test := func(event, id, data, expect string) {
+		stream, rec := newTestEventStream(t, nil)
+		zr.TEqual(t, stream.Send(event, id, data), nil)
+		zr.TEqual(t, rec.Body.String(), expect)
+	}
+	test("", "", "hello", "data: hello\n\n")
+	test("status", "7", "ok", "event: status\nid: 7\ndata: ok\n\n")
+	test("", "", "a\nb\r\nc", "data: a\ndata: b\ndata: c\n\n")
+	test("", "", "a\rid: x", "data: a\ndata: id: x\n\n")
+	test("", "", "a\r\rb\n", "data: a\ndata: \ndata: b\ndata: \n\n")
+	test("x\ny", "1\n2", "", "event: xy\nid: 12\ndata: \n\n")
+} //                                                  Test_sse_EventStream_Send_
+
+// end
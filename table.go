@@ -0,0 +1,374 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                    zr-web/[table.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	DataTable lists rows of data, e.g. records read from a database,
+//	in columns. Rows can be structs, pointers to structs or maps, and
+//	columns read a field or map key, or call a function:
+//
+//	table := web.DataTable{
+//		Columns: []web.TableColumn{
+//			{Header: "Name", Field: "Name", Sortable: true},
+//			{Header: "Joined", Field: "Joined", Sortable: true,
+//				Format: func(v interface{}) interface{} {
+//					return v.(time.Time).Format("2006-01-02")
+//				}},
+//			{Header: "Balance", Field: "Balance", Align: "right"},
+//			{Header: "", Value: func(row interface{}) interface{} {
+//				return web.ANode("/user/"+row.(User).ID, "Edit")
+//			}},
+//		},
+//		Rows:  users,
+//		Query: req.URL.Query(),
+//	}
+//	ctx.Reply(web.Div(table.Render()), "html")
+//
+//	The headers of sortable columns link to the same page with the
+//	'sort' query parameter set to the column's key, or to the key
+//	after '-' to sort in descending order, e.g. '?sort=-Joined'. The
+//	other query parameters are kept. The rows are sorted by the 'sort'
+//	parameter in Query.
+//
+//	If UseNthChild() is true, DataTable writes the same <div> and <p>
+//	layout as COLUMNS(), with the header in a <div class="head">,
+//	which is styled by CSS with 'nth-child' selectors. Otherwise
+//	it writes a <table>. Column classes are set in both layouts,
+//	and the alignment adds an 'align-left', 'align-center' or
+//	'align-right' class.
+
+//  DataTable struct
+//  TableColumn struct
+//
+// # Methods (ob *DataTable)
+//   ) Node() *Node
+//   ) Render() *Buffer
+//
+// # Support (File Scope)
+//   (ob *DataTable) headerNode(col *TableColumn, sortKey string,
+//       desc bool) *Node
+//   (ob *DataTable) rows() []interface{}
+//   (ob *DataTable) sortRows(rows []interface{}) (key string, desc bool)
+//   (ob *TableColumn) cellNode(row interface{}) *Node
+//   (ob *TableColumn) class() string
+//   (ob *TableColumn) key() string
+//   (ob *TableColumn) value(row interface{}) interface{}
+//   tableCellContent(value interface{}) interface{}
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/balacode/zr"
+)
+
+// DataTable describes a table of data. See the top of table.go.
+type DataTable struct {
+	// Columns lists the table's columns, in order.
+	Columns []TableColumn
+
+	// Rows is a slice of structs, pointers to structs or maps.
+	Rows interface{}
+
+	// Class is added to the class of the table, which is 'data-table'.
+	Class string
+
+	// Query holds the query parameters of the page, e.g. from
+	// req.URL.Query(). It is used to sort the rows and to make the
+	// links of sortable columns.
+	Query url.Values
+
+	// SortParam is the name of the query parameter that holds the
+	// sort order. It is "sort" if blank.
+	SortParam string
+
+	// EmptyText is shown in place of the rows if there are none.
+	EmptyText string
+} //                                                                   DataTable
+
+// TableColumn describes a column of a DataTable.
+type TableColumn struct {
+	// Header is the text in the column's header.
+	Header string
+
+	// Field is the name of the struct field or map key whose value
+	// is shown in the column, if Value is nil.
+	Field string
+
+	// Value returns the value shown in the column for 'row'. If it
+	// is nil, the column shows the value of Field.
+	Value func(row interface{}) interface{}
+
+	// Format returns the content of the column's cells for each
+	// value. It can return text, which is escaped, or markup in a
+	// *Node or *Buffer. If it is nil, values are shown as text.
+	Format func(value interface{}) interface{}
+
+	// Class is the class of the column's cells and header.
+	Class string
+
+	// Align is "left", "center" or "right", or blank.
+	Align string
+
+	// Sortable makes the column's header a link that sorts the rows.
+	Sortable bool
+
+	// Key names the column in the sort query parameter. It is Field
+	// if blank. Columns without a key or field can't be sorted.
+	Key string
+} //                                                                 TableColumn
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *DataTable)
+
+// Node returns the table as a node, sorting the rows first.
+// The table's Rows are not changed.
+func (ob *DataTable) Node() *Node {
+	rows := ob.rows()
+	sortKey, desc := ob.sortRows(rows)
+	class := Class(strings.TrimSpace("data-table " + ob.Class))
+	ret := DivNode(class)
+	head := DivNode(Class("head"))
+	if !UseNthChild() {
+		ret = ContainerNode("table", class)
+		head = TrNode()
+		ret.AppendChild(TheadNode(head))
+	} else {
+		ret.AppendChild(head)
+	}
+	for i := range ob.Columns {
+		head.AppendChild(ob.headerNode(&ob.Columns[i], sortKey, desc))
+	}
+	body := ret
+	if !UseNthChild() {
+		body = TbodyNode()
+		ret.AppendChild(body)
+	}
+	for _, row := range rows {
+		tr := DivNode()
+		if !UseNthChild() {
+			tr = TrNode()
+		}
+		for i := range ob.Columns {
+			tr.AppendChild(ob.Columns[i].cellNode(row))
+		}
+		body.AppendChild(tr)
+	}
+	if len(rows) == 0 && ob.EmptyText != "" {
+		if UseNthChild() {
+			body.AppendChild(PNode(Class("empty"), TextNode(ob.EmptyText)))
+		} else {
+			body.AppendChild(TrNode(TdNode(Class("empty"),
+				Attr("colspan", fmt.Sprint(len(ob.Columns))),
+				TextNode(ob.EmptyText))))
+		}
+	}
+	return ret
+} //                                                                        Node
+
+// Render composes the table. See Node().
+func (ob *DataTable) Render() *Buffer {
+	return ob.Node().Render()
+} //                                                                      Render
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// headerNode returns the header cell of column 'col'. 'sortKey'
+// and 'desc' give the column the rows are sorted by, if any.
+func (ob *DataTable) headerNode(col *TableColumn, sortKey string,
+	desc bool) *Node {
+	ret := PNode(Class(col.class()))
+	if !UseNthChild() {
+		ret = ThNode(Class(col.class()), Attr("scope", "col"))
+	}
+	key := col.key()
+	if !col.Sortable || key == "" {
+		return ret.AppendChild(TextNode(col.Header))
+	}
+	next := key
+	if key == sortKey {
+		class, order := "sort-asc", "ascending"
+		if desc {
+			class, order = "sort-desc", "descending"
+		} else {
+			next = "-" + key // clicking again reverses the order
+		}
+		ret.AddClass("sorted", class)
+		if !UseNthChild() {
+			ret.SetAttr("aria-sort", order)
+		}
+	}
+	query := url.Values{}
+	for name, values := range ob.Query {
+		query[name] = values
+	}
+	param := ob.SortParam
+	if param == "" {
+		param = "sort"
+	}
+	query.Set(param, next)
+	href := "?" + query.Encode()
+	return ret.AppendChild(ANode(href, TextNode(col.Header)))
+} //                                                                  headerNode
+
+// rows returns the rows of the table in a new slice.
+func (ob *DataTable) rows() []interface{} {
+	val := reflect.ValueOf(ob.Rows)
+	for val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Slice, reflect.Array:
+	default:
+		zr.Error("DataTable.Rows must be a slice, got", val.Type())
+		return nil
+	}
+	ret := make([]interface{}, val.Len())
+	for i := range ret {
+		ret[i] = val.Index(i).Interface()
+	}
+	return ret
+} //                                                                        rows
+
+// sortRows sorts 'rows' by the sort query parameter, if it names a
+// sortable column. Returns the column's key and if the order is
+// descending, or a blank key if the rows are not sorted.
+func (ob *DataTable) sortRows(rows []interface{}) (key string, desc bool) {
+	param := ob.SortParam
+	if param == "" {
+		param = "sort"
+	}
+	key = ob.Query.Get(param)
+	if strings.HasPrefix(key, "-") {
+		key, desc = key[1:], true
+	}
+	var col *TableColumn
+	for i := range ob.Columns {
+		if ob.Columns[i].Sortable && key != "" && ob.Columns[i].key() == key {
+			col = &ob.Columns[i]
+			break
+		}
+	}
+	if col == nil {
+		return "", false
+	}
+	values := make([]reflect.Value, len(rows))
+	for i, row := range rows {
+		values[i] = reflect.ValueOf(col.value(row))
+	}
+	less := func(a, b reflect.Value) bool {
+		if !a.IsValid() || !b.IsValid() {
+			return !a.IsValid() && b.IsValid() // nil values first
+		}
+		if cmp, ok := compareValues(a, b); ok {
+			return cmp < 0
+		}
+		return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+	}
+	index := make([]int, len(rows))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		a, b := values[index[i]], values[index[j]]
+		if desc {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+	sorted := make([]interface{}, len(rows))
+	for i, at := range index {
+		sorted[i] = rows[at]
+	}
+	copy(rows, sorted)
+	return key, desc
+} //                                                                    sortRows
+
+// cellNode returns the cell of the column for 'row'.
+func (ob *TableColumn) cellNode(row interface{}) *Node {
+	value := ob.value(row)
+	var content interface{}
+	if ob.Format != nil {
+		content = tableCellContent(ob.Format(value))
+	} else {
+		content = tableCellContent(value)
+	}
+	if UseNthChild() {
+		return PNode(Class(ob.class()), content)
+	}
+	return TdNode(Class(ob.class()), content)
+} //                                                                    cellNode
+
+// class returns the class of the column's cells.
+func (ob *TableColumn) class() string {
+	if ob.Align == "" {
+		return ob.Class
+	}
+	return SetClass(true, ob.Class, "align-"+ob.Align)
+} //                                                                       class
+
+// key returns the name of the column in the sort query parameter.
+func (ob *TableColumn) key() string {
+	if ob.Key != "" {
+		return ob.Key
+	}
+	return ob.Field
+} //                                                                         key
+
+// value returns the column's value for 'row', or nil
+// if 'row' has no such field or key.
+func (ob *TableColumn) value(row interface{}) interface{} {
+	if ob.Value != nil {
+		return ob.Value(row)
+	}
+	val := reflect.ValueOf(row)
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Struct:
+		val = val.FieldByName(ob.Field)
+		if val.IsValid() && !val.CanInterface() {
+			return nil // unexported field
+		}
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		val = val.MapIndex(reflect.ValueOf(ob.Field).Convert(
+			val.Type().Key()))
+	default:
+		return nil
+	}
+	if !val.IsValid() {
+		return nil
+	}
+	return val.Interface()
+} //                                                                       value
+
+// tableCellContent returns the content of a cell showing 'value':
+// text is escaped, while nodes and buffers are markup.
+func tableCellContent(value interface{}) interface{} {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case *Node, *Buffer, Buffer:
+		return value
+	case string:
+		return TextNode(value)
+	}
+	return TextNode(fmt.Sprint(value))
+} //                                                            tableCellContent
+
+// end
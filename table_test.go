@@ -0,0 +1,221 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                               zr-web/[table_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Methods (ob *DataTable)
+//   Test_tabl_DataTable_Render_
+//   Test_tabl_DataTable_Render_empty_
+//   Test_tabl_DataTable_Render_maps_
+//   Test_tabl_DataTable_Render_table_
+
+//  to test all items in table.go use:
+//      go test --run Test_tabl_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// tablTestUser is a row of the tables in the tests
+type tablTestUser struct {
+	Name    string
+	Age     int
+	Balance float64
+} //                                                                tablTestUser
+
+// tablTestUsers returns the rows of the tables in the tests
+func tablTestUsers() []tablTestUser {
+	return []tablTestUser{
+		{Name: "Carol", Age: 41, Balance: 5},
+		{Name: "Alice", Age: 29, Balance: 120.5},
+		{Name: "<Bob>", Age: 35, Balance: 0},
+	}
+} //                                                               tablTestUsers
+
+// tablTestColumns returns the columns of the tables in the tests
+func tablTestColumns() []TableColumn {
+	return []TableColumn{
+		{Header: "Name", Field: "Name", Sortable: true},
+		{Header: "Age", Field: "Age", Sortable: true, Class: "age"},
+		{Header: "Balance", Field: "Balance", Align: "right",
+			Format: func(v interface{}) interface{} {
+				return StrongNode(TextNode(fmt.Sprintf("%.2f", v)))
+			}},
+	}
+} //                                                             tablTestColumns
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *DataTable)
+
+// go test --run Test_tabl_DataTable_Render_
+func Test_tabl_DataTable_Render_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *DataTable) Render() *Buffer
+	//
+	old := UseNthChild()
+	defer SetUseNthChild(old)
+	SetUseNthChild(true)
+	//
+	table := DataTable{
+		Columns: tablTestColumns(),
+		Rows:    tablTestUsers(),
+		Class:   "users",
+		Query:   url.Values{"sort": {"Age"}, "page": {"2"}},
+	}
+	zr.TEqual(t, table.Render().String(),
+		"<div class=\"data-table users\">\r\n"+
+			"<div class=\"head\">\r\n"+
+			"<p><a href=\"?page=2&amp;sort=Name\">Name</a></p>\r\n"+
+			"<p class=\"age sorted sort-asc\">"+
+			"<a href=\"?page=2&amp;sort=-Age\">Age</a></p>\r\n"+
+			"<p class=\"align-right\">Balance</p>\r\n"+
+			"</div>\r\n"+
+			"<div>\r\n"+
+			"<p>Alice</p>\r\n"+
+			"<p class=\"age\">29</p>\r\n"+
+			"<p class=\"align-right\"><strong>120.50</strong>\r\n</p>\r\n"+
+			"</div>\r\n"+
+			"<div>\r\n"+
+			"<p>&lt;Bob&gt;</p>\r\n"+
+			"<p class=\"age\">35</p>\r\n"+
+			"<p class=\"align-right\"><strong>0.00</strong>\r\n</p>\r\n"+
+			"</div>\r\n"+
+			"<div>\r\n"+
+			"<p>Carol</p>\r\n"+
+			"<p class=\"age\">41</p>\r\n"+
+			"<p class=\"align-right\"><strong>5.00</strong>\r\n</p>\r\n"+
+			"</div>\r\n"+
+			"</div>\r\n")
+	//
+	// the rows given to the table are not sorted
+	zr.TEqual(t, table.Rows.([]tablTestUser)[0].Name, "Carol")
+	//
+	// a column that is not sortable is ignored
+	table.Query = url.Values{"sort": {"Balance"}}
+	zr.TEqual(t, table.Node().Children()[1].String(),
+		"<div>\r\n"+
+			"<p>Carol</p>\r\n"+
+			"<p class=\"age\">41</p>\r\n"+
+			"<p class=\"align-right\"><strong>5.00</strong>\r\n</p>\r\n"+
+			"</div>\r\n")
+	//
+	// a custom sort parameter
+	table.SortParam = "order"
+	table.Query = url.Values{"order": {"-Age"}}
+	zr.TEqual(t, table.Node().Children()[0].String(),
+		"<div class=\"head\">\r\n"+
+			"<p><a href=\"?order=Name\">Name</a></p>\r\n"+
+			"<p class=\"age sorted sort-desc\">"+
+			"<a href=\"?order=Age\">Age</a></p>\r\n"+
+			"<p class=\"align-right\">Balance</p>\r\n"+
+			"</div>\r\n")
+} //                                                 Test_tabl_DataTable_Render_
+
+// go test --run Test_tabl_DataTable_Render_empty_
+func Test_tabl_DataTable_Render_empty_(t *testing.T) {
+	zr.TBegin(t)
+	old := UseNthChild()
+	defer SetUseNthChild(old)
+	//
+	table := DataTable{
+		Columns:   []TableColumn{{Header: "A"}, {Header: "B"}},
+		EmptyText: "No rows",
+	}
+	SetUseNthChild(true)
+	zr.TEqual(t, table.Render().String(),
+		"<div class=\"data-table\">\r\n"+
+			"<div class=\"head\">\r\n<p>A</p>\r\n<p>B</p>\r\n</div>\r\n"+
+			"<p class=\"empty\">No rows</p>\r\n"+
+			"</div>\r\n")
+	SetUseNthChild(false)
+	zr.TEqual(t, table.Node().Children()[1].String(),
+		"<tbody><tr><td class=\"empty\" colspan=\"2\">No rows</td>\r\n"+
+			"</tr>\r\n</tbody>\r\n")
+	//
+	// rows that are not a slice are reported
+	errors := zr.GetErrorCount()
+	zr.DisableErrors()
+	table.Rows = 123
+	table.Render()
+	zr.EnableErrors()
+	zr.TEqual(t, zr.GetErrorCount(), errors+1)
+} //                                           Test_tabl_DataTable_Render_empty_
+
+// go test --run Test_tabl_DataTable_Render_maps_
+func Test_tabl_DataTable_Render_maps_(t *testing.T) {
+	zr.TBegin(t)
+	old := UseNthChild()
+	defer SetUseNthChild(old)
+	SetUseNthChild(false)
+	//
+	table := DataTable{
+		Columns: []TableColumn{
+			{Header: "Code", Field: "code", Sortable: true},
+			{Header: "Total", Sortable: true, Key: "total",
+				Value: func(row interface{}) interface{} {
+					m := row.(map[string]int)
+					return m["a"] + m["b"]
+				}},
+		},
+		Rows: []map[string]int{
+			{"code": 3, "a": 1, "b": 1},
+			{"code": 1, "a": 5, "b": 0},
+			{"a": 2, "b": 2},
+		},
+		Query: url.Values{"sort": {"-total"}},
+	}
+	zr.TEqual(t, table.Node().Children()[1].String(),
+		"<tbody><tr><td>1</td>\r\n<td>5</td>\r\n</tr>\r\n"+
+			"<tr><td></td>\r\n<td>4</td>\r\n</tr>\r\n"+
+			"<tr><td>3</td>\r\n<td>2</td>\r\n</tr>\r\n"+
+			"</tbody>\r\n")
+} //                                            Test_tabl_DataTable_Render_maps_
+
+// go test --run Test_tabl_DataTable_Render_table_
+func Test_tabl_DataTable_Render_table_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *DataTable) Render() *Buffer
+	//
+	old := UseNthChild()
+	defer SetUseNthChild(old)
+	SetUseNthChild(false)
+	//
+	table := DataTable{
+		Columns: tablTestColumns(),
+		Rows:    tablTestUsers(),
+		Query:   url.Values{"sort": {"-Name"}},
+	}
+	zr.TEqual(t, table.Render().String(),
+		"<table class=\"data-table\"><thead><tr>"+
+			"<th class=\"sorted sort-desc\" scope=\"col\""+
+			" aria-sort=\"descending\"><a href=\"?sort=Name\">Name</a></th>\r\n"+
+			"<th class=\"age\" scope=\"col\">"+
+			"<a href=\"?sort=Age\">Age</a></th>\r\n"+
+			"<th class=\"align-right\" scope=\"col\">Balance</th>\r\n"+
+			"</tr>\r\n</thead>\r\n"+
+			"<tbody><tr><td>Carol</td>\r\n"+
+			"<td class=\"age\">41</td>\r\n"+
+			"<td class=\"align-right\"><strong>5.00</strong>\r\n</td>\r\n"+
+			"</tr>\r\n"+
+			"<tr><td>Alice</td>\r\n"+
+			"<td class=\"age\">29</td>\r\n"+
+			"<td class=\"align-right\"><strong>120.50</strong>\r\n</td>\r\n"+
+			"</tr>\r\n"+
+			"<tr><td>&lt;Bob&gt;</td>\r\n"+
+			"<td class=\"age\">35</td>\r\n"+
+			"<td class=\"align-right\"><strong>0.00</strong>\r\n</td>\r\n"+
+			"</tr>\r\n"+
+			"</tbody>\r\n</table>\r\n")
+} //                                           Test_tabl_DataTable_Render_table_
+
+// end
@@ -0,0 +1,765 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                 zr-web/[validate.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	Validators check request data, e.g. a struct read from JSON or
+//	a form. They are registered by name and listed in 'validate' tags,
+//	separated by semicolons. A parameter follows the name after '=':
+//
+//	type Account struct {
+//		Name     string `json:"name" validate:"required; length=2:50"`
+//		Email    string `json:"email" validate:"required; email"`
+//		Age      int    `json:"age" validate:"range=18:130"`
+//		Site     string `json:"site" validate:"url"`
+//		Plan     string `json:"plan" validate:"oneof=free|pro"`
+//		Password string `json:"password" validate:"length=8:"`
+//		Confirm  string `json:"confirm" validate:"eqfield=Password"`
+//	}
+//
+//	func accountServe(w http.ResponseWriter, req *http.Request) {
+//		ctx := web.NewContext(w, req, &sessions)
+//		var account Account
+//		if result := ctx.BindJSON(&account); !result.OK() {
+//			ctx.ReplyErrors(result) // 422 with the errors in JSON
+//			return
+//		}
+//		...
+//	}
+//
+//	The built-in validators are:
+//	  required         the value is not blank, zero, false or empty.
+//	                   Pointers pass if they are not nil
+//	  length=min:max   the number of characters, or of items in a
+//	                   slice or map. Either limit can be left out,
+//	                   e.g. 'length=8:', and 'length=n' is exact
+//	  range=min:max    the lowest and highest number
+//	  regex=expr       text matches the regular expression fully
+//	  email            an email address, e.g. 'ann@example.com'
+//	  url              an absolute http or https URL
+//	  oneof=a|b|c      one of the listed values
+//	  eqfield=Field    equal to another field of the struct, e.g. to
+//	  nefield=Field    confirm a password, or not equal to it
+//	  gtfield=Field    greater or less than another field, e.g. an end
+//	  ltfield=Field    date after a start date. Numbers, text and
+//	                   time.Time values can be compared
+//
+//	Except for 'required', validators skip blank text and nil pointers,
+//	so optional fields only need to be valid when they are given.
+//	A semicolon that is part of a rule, e.g. in a regular expression,
+//	is written after a backslash, which is doubled in struct tags:
+//	`validate:"regex=^[^\\;]*$"`.
+//
+//	Messages are templates looked up by locale and by the key that a
+//	validator returns, e.g. "length.min". In templates, {field} is the
+//	field's label and {0}, {1}, ... are the validator's arguments:
+//
+//	web.RegisterValidationMessages("de", map[string]string{
+//		"required": "{field} ist erforderlich",
+//		...
+//	})
+
+//  ValidationError struct
+//  ValidationResult struct
+//  Validator func type
+//
+// # Global Settings
+//   DefaultValidationLocale string
+//
+// # Registration
+//   RegisterValidationMessages(locale string, messages map[string]string)
+//   RegisterValidator(name string, fn Validator)
+//
+// # Functions
+//   ValidateStruct(model interface{}, locale string) *ValidationResult
+//   ValidateValue(field string, value interface{}, rules, locale string,
+//       ) *ValidationResult
+//   ValidationMessage(locale, key, field string, args ...string) string
+//
+// # Methods (ob *ValidationResult)
+//   ) Add(field, rule, message string)
+//   ) FieldError(field string) string
+//   ) Map() map[string]string
+//   ) Node() *Node
+//   ) OK() bool
+//
+// # Methods (ctx *Context)
+//   BindJSON(model interface{}) *ValidationResult
+//   ReplyErrors(result *ValidationResult)
+//   ValidationLocale() string
+//
+// # Built-in Validators (File Scope)
+//   validateCompareField(op string) Validator
+//   validateEmail(value reflect.Value, param string, parent reflect.Value,
+//       ) []string
+//   validateLength(value reflect.Value, param string, parent reflect.Value,
+//       ) []string
+//   validateOneOf(value reflect.Value, param string, parent reflect.Value,
+//       ) []string
+//   validateRange(value reflect.Value, param string, parent reflect.Value,
+//       ) []string
+//   validateRegex(value reflect.Value, param string, parent reflect.Value,
+//       ) []string
+//   validateRequired(value reflect.Value, param string, parent reflect.Value,
+//       ) []string
+//   validateURL(value reflect.Value, param string, parent reflect.Value,
+//       ) []string
+//
+// # Support (File Scope)
+//   compareValues(a, b reflect.Value) (int, bool)
+//   isBlankValue(value reflect.Value) bool
+//   numberValue(value reflect.Value) (float64, bool)
+//   parseLimits(param string) (min, max string)
+//   splitTagOptions(tag string) []string
+//   validateRules(result *ValidationResult, field, label string,
+//       value, parent reflect.Value, rules, locale string)
+//   validationLabel(sf reflect.StructField) string
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/balacode/zr"
+)
+
+// DefaultValidationLocale is the locale of messages when a locale
+// is not given, or has no message for an error. Its messages should
+// be complete: the built-in English messages are registered as "en".
+var DefaultValidationLocale = "en"
+
+// ValidationError describes a value that is not valid.
+type ValidationError struct {
+	// Field is the name of the field, e.g. its JSON name. It is
+	// blank for errors that are not about a field.
+	Field string `json:"field"`
+
+	// Rule is the message key given by the validator, e.g. "length.min"
+	Rule string `json:"rule"`
+
+	// Message describes the error in the requested locale
+	Message string `json:"message"`
+} //                                                             ValidationError
+
+// ValidationResult holds the errors found by validation, in the
+// order of the fields. It can be shown in a page with Node() or
+// FieldError(), or sent to the client with Context.ReplyErrors().
+type ValidationResult struct {
+	Errors []ValidationError `json:"errors"`
+} //                                                            ValidationResult
+
+// Validator checks 'value', using the rule's parameter 'param', which
+// is blank if the rule has none. 'parent' is the struct holding the
+// value, used by cross-field rules. It is not valid (see IsValid)
+// when checking a single value. Returns nil if the value is valid,
+// or the key of the message to show, followed by its arguments,
+// e.g. []string{"length.max", "50"}.
+type Validator func(value reflect.Value, param string,
+	parent reflect.Value) []string
+
+// validationMutex guards validators and validationMessages
+var validationMutex sync.RWMutex
+
+// validators holds the registered validators, by name
+var validators = map[string]Validator{
+	"email":    validateEmail,
+	"eqfield":  validateCompareField("eq"),
+	"gtfield":  validateCompareField("gt"),
+	"length":   validateLength,
+	"ltfield":  validateCompareField("lt"),
+	"nefield":  validateCompareField("ne"),
+	"oneof":    validateOneOf,
+	"range":    validateRange,
+	"regex":    validateRegex,
+	"required": validateRequired,
+	"url":      validateURL,
+}
+
+// validationMessages holds the message templates by locale and key
+var validationMessages = map[string]map[string]string{
+	"en": {
+		"email":        "{field} must be a valid email address",
+		"eqfield":      "{field} must be the same as {0}",
+		"form":         "The form data is not valid",
+		"gtfield":      "{field} must be greater than {0}",
+		"json":         "The data is not valid JSON",
+		"length":       "{field} must have {0} to {1} characters",
+		"length.exact": "{field} must have {0} characters",
+		"length.max":   "{field} must have at most {0} characters",
+		"length.min":   "{field} must have at least {0} characters",
+		"ltfield":      "{field} must be less than {0}",
+		"nefield":      "{field} must be different from {0}",
+		"number":       "{field} must be a number",
+		"oneof":        "{field} must be one of the options",
+		"range":        "{field} must be from {0} to {1}",
+		"range.max":    "{field} must be at most {0}",
+		"range.min":    "{field} must be at least {0}",
+		"regex":        "{field} is not in the right format",
+		"required":     "{field} is required",
+		"url":          "{field} must be a valid URL",
+	},
+}
+
+// validationRegexps caches compiled 'regex' rules, by expression
+var validationRegexps sync.Map
+
+// -----------------------------------------------------------------------------
+// # Registration
+
+// RegisterValidationMessages adds the message templates of 'locale',
+// e.g. "de" or "pt-BR", by key. Existing templates are replaced.
+// Locales with a region fall back to the language, e.g. "pt-BR" to
+// "pt", then to DefaultValidationLocale.
+func RegisterValidationMessages(locale string, messages map[string]string) {
+	locale = strings.ToLower(locale)
+	validationMutex.Lock()
+	defer validationMutex.Unlock()
+	if validationMessages[locale] == nil {
+		validationMessages[locale] = map[string]string{}
+	}
+	for key, msg := range messages {
+		validationMessages[locale][key] = msg
+	}
+} //                                                  RegisterValidationMessages
+
+// RegisterValidator registers validator 'fn' under 'name', to be used
+// in 'validate' tags. It replaces a validator with the same name, so
+// the built-in validators can be changed too. Register its messages
+// with RegisterValidationMessages().
+func RegisterValidator(name string, fn Validator) {
+	if name == "" || fn == nil {
+		zr.Error(zr.EInvalidArg, "^name", ":^", name)
+		return
+	}
+	validationMutex.Lock()
+	validators[name] = fn
+	validationMutex.Unlock()
+} //                                                           RegisterValidator
+
+// -----------------------------------------------------------------------------
+// # Functions
+
+// ValidateStruct checks the fields of the struct that 'model' is, or
+// points to, by the rules in their 'validate' tags. Messages are in
+// 'locale'. A field is named in the result by its JSON name, if it
+// has one, and in messages by its 'web' tag label, if it has one.
+// Nested structs are not checked.
+func ValidateStruct(model interface{}, locale string) *ValidationResult {
+	ret := &ValidationResult{}
+	val := reflect.ValueOf(model)
+	for val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		zr.Error("ValidateStruct() needs a struct, got",
+			reflect.TypeOf(model))
+		return ret
+	}
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		rules := sf.Tag.Get("validate")
+		if rules == "" || sf.PkgPath != "" {
+			continue
+		}
+		field := sf.Name
+		if name := strings.Split(sf.Tag.Get("json"), ",")[0]; name != "" &&
+			name != "-" {
+			field = name
+		}
+		validateRules(ret, field, validationLabel(sf), val.Field(i), val,
+			rules, locale)
+	}
+	return ret
+} //                                                              ValidateStruct
+
+// ValidateValue checks a single 'value', e.g. a query parameter, by
+// 'rules', which are written as in 'validate' tags. 'field' names the
+// value in the result and in messages. Cross-field rules can't be used.
+func ValidateValue(field string, value interface{}, rules, locale string,
+) *ValidationResult {
+	ret := &ValidationResult{}
+	validateRules(ret, field, field, reflect.ValueOf(value),
+		reflect.Value{}, rules, locale)
+	return ret
+} //                                                               ValidateValue
+
+// ValidationMessage returns the message with 'key' in 'locale', e.g.
+// "required", with {field} replaced by 'field' and {0}, {1}, ... by
+// 'args'. Returns the key if there is no such message.
+func ValidationMessage(locale, key, field string, args ...string) string {
+	locale = strings.ToLower(locale)
+	validationMutex.RLock()
+	msg, ok := validationMessages[locale][key]
+	if i := strings.IndexByte(locale, '-'); !ok && i != -1 {
+		msg, ok = validationMessages[locale[:i]][key]
+	}
+	if !ok {
+		msg, ok = validationMessages[strings.ToLower(
+			DefaultValidationLocale)][key]
+	}
+	validationMutex.RUnlock()
+	if !ok {
+		return key
+	}
+	pairs := []string{"{field}", field}
+	for i, arg := range args {
+		pairs = append(pairs, "{"+strconv.Itoa(i)+"}", arg)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+} //                                                           ValidationMessage
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *ValidationResult)
+
+// Add adds an error of 'field', e.g. from a check that needs a
+// database. 'rule' is a key for clients, e.g. "unique".
+func (ob *ValidationResult) Add(field, rule, message string) {
+	ob.Errors = append(ob.Errors, ValidationError{
+		Field:   field,
+		Rule:    rule,
+		Message: message,
+	})
+} //                                                                         Add
+
+// FieldError returns the message of the first error of
+// 'field', or a blank string if it has no errors.
+func (ob *ValidationResult) FieldError(field string) string {
+	for _, it := range ob.Errors {
+		if it.Field == field {
+			return it.Message
+		}
+	}
+	return ""
+} //                                                                  FieldError
+
+// Map returns the message of the first error of each field, by field.
+func (ob *ValidationResult) Map() map[string]string {
+	ret := map[string]string{}
+	for _, it := range ob.Errors {
+		if _, ok := ret[it.Field]; !ok {
+			ret[it.Field] = it.Message
+		}
+	}
+	return ret
+} //                                                                         Map
+
+// Node returns the messages in a <ul class="errors"> list, to
+// show them at the top of a page, or an empty fragment if there
+// are no errors.
+func (ob *ValidationResult) Node() *Node {
+	if ob.OK() {
+		return FragmentNode()
+	}
+	ret := UlNode(Class("errors"), Role("alert"))
+	for _, it := range ob.Errors {
+		ret.AppendChild(LiNode(TextNode(it.Message)))
+	}
+	return ret
+} //                                                                        Node
+
+// OK returns true if there are no errors.
+func (ob *ValidationResult) OK() bool {
+	return ob == nil || len(ob.Errors) == 0
+} //                                                                          OK
+
+// -----------------------------------------------------------------------------
+// # Methods (ctx *Context)
+
+// BindJSON reads the JSON in the body of the request into 'model', which
+// must be a pointer, then checks it with ValidateStruct(), giving the
+// messages in the client's language (see ValidationLocale).
+func (ctx *Context) BindJSON(model interface{}) *ValidationResult {
+	locale := ctx.ValidationLocale()
+	err := json.Unmarshal(ctx.PostData(), model)
+	if err != nil {
+		ret := &ValidationResult{}
+		ret.Add("", "json", ValidationMessage(locale, "json", ""))
+		return ret
+	}
+	return ValidateStruct(model, locale)
+} //                                                                    BindJSON
+
+// ReplyErrors replies with HTTP status 422 (unprocessable entity)
+// and the errors in 'result' as JSON, e.g.
+// {"errors":[{"field":"email","rule":"email","message":"..."}]}
+func (ctx *Context) ReplyErrors(result *ValidationResult) {
+	if result == nil || result.Errors == nil {
+		result = &ValidationResult{Errors: []ValidationError{}}
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		zr.Error("Failed encoding errors:", err)
+		return
+	}
+	// headers can't be set after the status
+	ctx.w.Header().Set("Content-Type", MediaType("json"))
+	ctx.w.WriteHeader(http.StatusUnprocessableEntity)
+	ctx.Reply(data, "json")
+} //                                                                 ReplyErrors
+
+// ValidationLocale returns the language from the request's
+// 'Accept-Language' header that has the highest preference
+// and registered messages, or DefaultValidationLocale.
+func (ctx *Context) ValidationLocale() string {
+	ranges := parseAccept(ctx.req.Header.Get("Accept-Language"))
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	validationMutex.RLock()
+	defer validationMutex.RUnlock()
+	for _, rng := range ranges {
+		locale := rng.mainType // e.g. "en-us"
+		if rng.q == 0 {
+			continue
+		}
+		if validationMessages[locale] != nil {
+			return locale
+		}
+		if i := strings.IndexByte(locale, '-'); i != -1 &&
+			validationMessages[locale[:i]] != nil {
+			return locale[:i]
+		}
+	}
+	return DefaultValidationLocale
+} //                                                            ValidationLocale
+
+// -----------------------------------------------------------------------------
+// # Built-in Validators (File Scope)
+
+// validateCompareField returns a cross-field validator that compares
+// a value with the field named by its parameter, using operator 'op':
+// "eq", "ne", "gt" or "lt".
+func validateCompareField(op string) Validator {
+	return func(value reflect.Value, param string, parent reflect.Value,
+	) []string {
+		var other reflect.Value
+		var sf reflect.StructField
+		if parent.IsValid() {
+			sf, _ = parent.Type().FieldByName(param)
+			other = parent.FieldByName(param)
+		}
+		if !other.IsValid() {
+			zr.Error("Field", param, "not found for rule", op+"field")
+			return nil
+		}
+		for other.Kind() == reflect.Ptr && !other.IsNil() {
+			other = other.Elem()
+		}
+		label := validationLabel(sf)
+		if op == "eq" || op == "ne" {
+			equal := other.Kind() != reflect.Ptr &&
+				value.Type() == other.Type() &&
+				reflect.DeepEqual(value.Interface(), other.Interface())
+			if equal != (op == "eq") {
+				return []string{op + "field", label}
+			}
+			return nil
+		}
+		cmp, ok := compareValues(value, other)
+		if ok && (op == "gt" && cmp <= 0 || op == "lt" && cmp >= 0) {
+			return []string{op + "field", label}
+		}
+		return nil
+	}
+} //                                                        validateCompareField
+
+// validateEmail checks that text is an email address, without a name.
+func validateEmail(value reflect.Value, param string, parent reflect.Value,
+) []string {
+	s := value.String()
+	addr, err := mail.ParseAddress(s)
+	if value.Kind() != reflect.String || err != nil || addr.Address != s ||
+		!strings.Contains(s[strings.LastIndexByte(s, '@'):], ".") {
+		return []string{"email"}
+	}
+	return nil
+} //                                                               validateEmail
+
+// validateLength checks the number of characters in text,
+// or of items in a slice, array or map.
+func validateLength(value reflect.Value, param string, parent reflect.Value,
+) []string {
+	var n int
+	switch value.Kind() {
+	case reflect.String:
+		n = utf8.RuneCountInString(value.String())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n = value.Len()
+	default:
+		zr.Error("Rule 'length' can't check values of type", value.Type())
+		return nil
+	}
+	min, max := parseLimits(param)
+	minN, errMin := strconv.Atoi(min)
+	maxN, errMax := strconv.Atoi(max)
+	switch {
+	case !strings.Contains(param, ":"):
+		if errMin == nil && n != minN {
+			return []string{"length.exact", min}
+		}
+	case errMin == nil && errMax == nil:
+		if n < minN || n > maxN {
+			return []string{"length", min, max}
+		}
+	case errMin == nil && n < minN:
+		return []string{"length.min", min}
+	case errMax == nil && n > maxN:
+		return []string{"length.max", max}
+	}
+	return nil
+} //                                                              validateLength
+
+// validateOneOf checks that a value is one of
+// the values in its parameter, e.g. "a|b|c".
+func validateOneOf(value reflect.Value, param string, parent reflect.Value,
+) []string {
+	var s string
+	if n, ok := numberValue(value); ok && value.Kind() != reflect.String {
+		s = strconv.FormatFloat(n, 'f', -1, 64)
+	} else {
+		s = fmt.Sprint(value.Interface())
+	}
+	for _, it := range strings.Split(param, "|") {
+		if it == s {
+			return nil
+		}
+	}
+	return []string{"oneof", strings.Replace(param, "|", ", ", -1)}
+} //                                                               validateOneOf
+
+// validateRange checks that a number is in the range given by its
+// parameter, e.g. "1:10". Text must be a number in the range.
+func validateRange(value reflect.Value, param string, parent reflect.Value,
+) []string {
+	n, ok := numberValue(value)
+	if !ok {
+		return []string{"number"}
+	}
+	min, max := parseLimits(param)
+	minN, errMin := strconv.ParseFloat(min, 64)
+	maxN, errMax := strconv.ParseFloat(max, 64)
+	switch {
+	case errMin == nil && errMax == nil:
+		if n < minN || n > maxN {
+			return []string{"range", min, max}
+		}
+	case errMin == nil && n < minN:
+		return []string{"range.min", min}
+	case errMax == nil && n > maxN:
+		return []string{"range.max", max}
+	}
+	return nil
+} //                                                               validateRange
+
+// validateRegex checks that text matches the
+// regular expression in its parameter fully.
+func validateRegex(value reflect.Value, param string, parent reflect.Value,
+) []string {
+	var rx *regexp.Regexp
+	if it, ok := validationRegexps.Load(param); ok {
+		rx = it.(*regexp.Regexp)
+	} else {
+		var err error
+		rx, err = regexp.Compile("^(?:" + param + ")$")
+		if err != nil {
+			zr.Error("Invalid rule 'regex':", err)
+			return nil
+		}
+		validationRegexps.Store(param, rx)
+	}
+	if !rx.MatchString(fmt.Sprint(value.Interface())) {
+		return []string{"regex"}
+	}
+	return nil
+} //                                                               validateRegex
+
+// validateRequired checks that a value is not blank, zero, false or empty.
+func validateRequired(value reflect.Value, param string, parent reflect.Value,
+) []string {
+	if isBlankValue(value) {
+		return []string{"required"}
+	}
+	return nil
+} //                                                            validateRequired
+
+// validateURL checks that text is an absolute http or https URL.
+func validateURL(value reflect.Value, param string, parent reflect.Value,
+) []string {
+	u, err := url.Parse(value.String())
+	if value.Kind() != reflect.String || err != nil || u.Host == "" ||
+		u.Scheme != "http" && u.Scheme != "https" {
+		return []string{"url"}
+	}
+	return nil
+} //                                                                 validateURL
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// compareValues compares numbers, text or time.Time values 'a' and 'b',
+// returning -1, 0 or 1. Returns false if they can't be compared.
+func compareValues(a, b reflect.Value) (int, bool) {
+	if ta, ok := a.Interface().(time.Time); ok {
+		tb, ok := b.Interface().(time.Time)
+		switch {
+		case !ok:
+			return 0, false
+		case ta.Before(tb):
+			return -1, true
+		case ta.After(tb):
+			return 1, true
+		}
+		return 0, true
+	}
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), true
+	}
+	na, okA := numberValue(a)
+	nb, okB := numberValue(b)
+	if !okA || !okB || a.Kind() == reflect.String ||
+		b.Kind() == reflect.String {
+		return 0, false
+	}
+	switch {
+	case na < nb:
+		return -1, true
+	case na > nb:
+		return 1, true
+	}
+	return 0, true
+} //                                                               compareValues
+
+// isBlankValue returns true if 'value' is blank text, a zero
+// number, false, an empty slice or map, or a nil pointer.
+func isBlankValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	}
+	return value.IsZero()
+} //                                                                isBlankValue
+
+// numberValue returns a number or text holding a number as
+// a float64. Returns false if 'value' is not a number.
+func numberValue(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	case reflect.String:
+		n, err := strconv.ParseFloat(strings.TrimSpace(value.String()), 64)
+		return n, err == nil
+	}
+	return 0, false
+} //                                                                 numberValue
+
+// parseLimits splits a parameter like "1:10", "1:" or ":10"
+// into its lower and upper limits.
+func parseLimits(param string) (min, max string) {
+	i := strings.IndexByte(param, ':')
+	if i == -1 {
+		return strings.TrimSpace(param), ""
+	}
+	return strings.TrimSpace(param[:i]), strings.TrimSpace(param[i+1:])
+} //                                                                 parseLimits
+
+// splitTagOptions splits the rules of a 'validate' tag, or the options
+// of a 'web' tag, at semicolons. A semicolon after a backslash is part
+// of the option, e.g. `validate:"regex=^[^\\;]+$"`, and the backslash
+// is removed. (Backslashes are doubled in struct tags.)
+func splitTagOptions(tag string) []string {
+	var ret []string
+	var sb strings.Builder
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ';':
+			sb.WriteByte(';')
+			i++
+		case tag[i] == ';':
+			ret = append(ret, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(tag[i])
+		}
+	}
+	return append(ret, sb.String())
+} //                                                             splitTagOptions
+
+// validateRules checks 'value' by 'rules' and adds an error to 'result'
+// for the first rule it fails. Rules other than 'required' are skipped
+// for blank text and nil pointers. Unknown rules are reported.
+func validateRules(result *ValidationResult, field, label string,
+	value, parent reflect.Value, rules, locale string) {
+	given := value // 'required' passes for pointers to zero values
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	skip := value.Kind() == reflect.Invalid ||
+		value.Kind() == reflect.Ptr ||
+		value.Kind() == reflect.String &&
+			strings.TrimSpace(value.String()) == ""
+	for _, rule := range splitTagOptions(rules) {
+		name, param := strings.TrimSpace(rule), ""
+		if i := strings.IndexByte(name, '='); i != -1 {
+			name, param = strings.TrimSpace(name[:i]), name[i+1:]
+		}
+		if name == "" || skip && name != "required" {
+			continue
+		}
+		validationMutex.RLock()
+		fn := validators[name]
+		validationMutex.RUnlock()
+		if fn == nil {
+			zr.Error("Unknown validator", name, "of", field)
+			continue
+		}
+		arg := value
+		if name == "required" {
+			arg = given
+		}
+		fail := fn(arg, param, parent)
+		if len(fail) > 0 {
+			result.Add(field, fail[0],
+				ValidationMessage(locale, fail[0], label, fail[1:]...))
+			return
+		}
+	}
+} //                                                               validateRules
+
+// validationLabel returns the name of struct field 'sf' used in
+// messages: its label from its 'web' tag, or its name.
+func validationLabel(sf reflect.StructField) string {
+	for _, opt := range splitTagOptions(sf.Tag.Get("web")) {
+		opt = strings.TrimSpace(opt)
+		if strings.HasPrefix(opt, "label=") {
+			return strings.TrimSpace(opt[len("label="):])
+		}
+	}
+	return sf.Name
+} //                                                             validationLabel
+
+// end
@@ -0,0 +1,387 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                            zr-web/[validate_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Registration
+//   Test_vald_RegisterValidationMessages_
+//   Test_vald_RegisterValidator_
+//
+// # Functions
+//   Test_vald_ValidateStruct_
+//   Test_vald_ValidateStruct_crossField_
+//   Test_vald_ValidateValue_
+//
+// # Methods (ob *ValidationResult)
+//   Test_vald_ValidationResult_
+//
+// # Methods (ctx *Context)
+//   Test_vald_Context_BindJSON_
+//   Test_vald_Context_ReplyErrors_
+//   Test_vald_Context_ValidationLocale_
+
+//  to test all items in validate.go use:
+//      go test --run Test_vald_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balacode/zr"
+)
+
+// validateTestAccount is the struct checked by the tests
+type validateTestAccount struct {
+	Name     string `json:"name" validate:"required; length=2:10"`
+	Email    string `json:"email" web:"label=E-mail" validate:"email"`
+	Age      int    `json:"age" validate:"range=18:"`
+	Password string `json:"-" validate:"length=8:"`
+	Confirm  string `json:"confirm" validate:"eqfield=Password"`
+	Plain    string
+} //                                                         validateTestAccount
+
+// -----------------------------------------------------------------------------
+// # Registration
+
+// go test --run Test_vald_RegisterValidationMessages_
+func Test_vald_RegisterValidationMessages_(t *testing.T) {
+	zr.TBegin(t)
+	// RegisterValidationMessages(locale string, messages map[string]string)
+	//
+	RegisterValidationMessages("xx", map[string]string{
+		"required":   "{field} fehlt",
+		"length.min": "{field}: mindestens {0} Zeichen",
+	})
+	test := func(locale, key, expect string, args ...string) {
+		zr.TEqual(t, ValidationMessage(locale, key, "Name", args...), expect)
+	}
+	test("xx", "required", "Name fehlt")
+	test("XX-YY", "length.min", "Name: mindestens 3 Zeichen", "3")
+	//
+	// missing messages and locales fall back to the default locale
+	test("xx", "email", "Name must be a valid email address")
+	test("", "range", "Name must be from 1 to 5", "1", "5")
+	test("zz", "required", "Name is required")
+	test("en", "no-such-key", "no-such-key")
+	//
+	// the default locale can be changed
+	DefaultValidationLocale = "xx"
+	test("zz", "required", "Name fehlt")
+	DefaultValidationLocale = "en"
+} //                                       Test_vald_RegisterValidationMessages_
+
+// go test --run Test_vald_RegisterValidator_
+func Test_vald_RegisterValidator_(t *testing.T) {
+	zr.TBegin(t)
+	// RegisterValidator(name string, fn Validator)
+	//
+	RegisterValidator("even", func(value reflect.Value, param string,
+		parent reflect.Value) []string {
+		if value.Int()%2 != 0 {
+			return []string{"even"}
+		}
+		return nil
+	})
+	RegisterValidationMessages("en", map[string]string{
+		"even": "{field} must be even",
+	})
+	zr.TTrue(t, ValidateValue("n", 4, "even", "").OK())
+	zr.TEqual(t, ValidateValue("n", 3, "even", "").FieldError("n"),
+		"n must be even")
+	//
+	zr.DisableErrors()
+	defer zr.EnableErrors()
+	count := zr.GetErrorCount()
+	RegisterValidator("", nil)
+	zr.TTrue(t, ValidateValue("n", 3, "no-such-rule", "").OK())
+	zr.TEqual(t, zr.GetErrorCount()-count, 2)
+} //                                                Test_vald_RegisterValidator_
+
+// -----------------------------------------------------------------------------
+// # Functions
+
+// go test --run Test_vald_ValidateStruct_
+func Test_vald_ValidateStruct_(t *testing.T) {
+	zr.TBegin(t)
+	// ValidateStruct(model interface{}, locale string) *ValidationResult
+	//
+	account := validateTestAccount{
+		Name: "Ann", Email: "ann@example.com", Age: 30,
+		Password: "secret-1", Confirm: "secret-1",
+	}
+	zr.TTrue(t, ValidateStruct(account, "").OK())
+	zr.TTrue(t, ValidateStruct(&account, "en").OK())
+	//
+	// errors are in the order of the fields, one for each field
+	account = validateTestAccount{
+		Email: "ann@", Age: 17, Password: "short", Confirm: "other",
+	}
+	zr.TEqual(t, ValidateStruct(&account, "").Errors, []ValidationError{
+		{"name", "required", "Name is required"},
+		{"email", "email", "E-mail must be a valid email address"},
+		{"age", "range.min", "Age must be at least 18"},
+		{"Password", "length.min", "Password must have at least 8 characters"},
+		{"confirm", "eqfield", "Confirm must be the same as Password"},
+	})
+	//
+	zr.DisableErrors()
+	defer zr.EnableErrors()
+	zr.TTrue(t, ValidateStruct(nil, "").OK())
+	zr.TTrue(t, ValidateStruct("x", "").OK())
+	//
+	// a semicolon after a backslash is part of a rule
+	var pair struct {
+		Pair string `web:"label=A\\;B" validate:"regex=\\w+\\;\\w+; length=:5"`
+	}
+	pair.Pair = "ab;cd"
+	zr.TTrue(t, ValidateStruct(&pair, "").OK())
+	pair.Pair = "ab;c;d"
+	zr.TEqual(t, ValidateStruct(&pair, "").Errors, []ValidationError{
+		{"Pair", "regex", "A;B is not in the right format"},
+	})
+} //                                                   Test_vald_ValidateStruct_
+
+// go test --run Test_vald_ValidateStruct_crossField_
+func Test_vald_ValidateStruct_crossField_(t *testing.T) {
+	zr.TBegin(t)
+	// ValidateStruct(model interface{}, locale string) *ValidationResult
+	//
+	type period struct {
+		Start time.Time `web:"label=Start date"`
+		End   time.Time `validate:"gtfield=Start"`
+		Min   int
+		Max   int     `validate:"gtfield=Min"`
+		Low   float64 `validate:"ltfield=Max"`
+		Old   string
+		New   string `validate:"nefield=Old"`
+	}
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	model := period{
+		Start: day, End: day.Add(time.Hour), Min: 1, Max: 2, Low: 1.5,
+		Old: "a", New: "b",
+	}
+	zr.TTrue(t, ValidateStruct(model, "").OK())
+	model = period{Start: day, End: day, Min: 2, Max: 2, Low: 2,
+		Old: "a", New: "a"}
+	zr.TEqual(t, ValidateStruct(model, "").Map(), map[string]string{
+		"End": "End must be greater than Start date",
+		"Max": "Max must be greater than Min",
+		"Low": "Low must be less than Max",
+		"New": "New must be different from Old",
+	})
+	//
+	// cross-field rules need a struct with the named field
+	zr.DisableErrors()
+	defer zr.EnableErrors()
+	count := zr.GetErrorCount()
+	zr.TTrue(t, ValidateValue("v", 1, "eqfield=Other", "").OK())
+	zr.TTrue(t, ValidateStruct(struct {
+		A int `validate:"eqfield=B"`
+	}{1}, "").OK())
+	zr.TEqual(t, zr.GetErrorCount()-count, 2)
+} //                                        Test_vald_ValidateStruct_crossField_
+
+// go test --run Test_vald_ValidateValue_
+func Test_vald_ValidateValue_(t *testing.T) {
+	zr.TBegin(t)
+	// ValidateValue(field string, value interface{}, rules, locale string,
+	//     ) *ValidationResult
+	//
+	test := func(value interface{}, rules, expect string) {
+		result := ValidateValue("v", value, rules, "")
+		rule := ""
+		if !result.OK() {
+			rule = result.Errors[0].Rule
+		}
+		zr.TEqual(t, rule, expect)
+	}
+	// required
+	for _, it := range []interface{}{"", " ", 0, 0.0, false, []int{},
+		map[string]int{}, (*int)(nil), nil, time.Time{}} {
+		test(it, "required", "required")
+	}
+	for _, it := range []interface{}{"a", 1, -0.5, true, []int{0},
+		new(int), time.Now()} {
+		test(it, "required", "")
+	}
+	// other rules skip blank text and nil pointers
+	test("", "email; length=3; url", "")
+	test((*string)(nil), "email", "")
+	//
+	// length
+	test("abc", "length=3", "")
+	test("ab", "length=3", "length.exact")
+	test("añb", "length=1:3", "")
+	test("abcd", "length=1:3", "length")
+	test("a", "length=2:", "length.min")
+	test("abc", "length=:2", "length.max")
+	test([]int{1, 2}, "length=:1", "length.max")
+	//
+	// range
+	test(5, "range=1:10", "")
+	test(0, "range=1:10", "range")
+	test(uint8(200), "range=:100", "range.max")
+	test(-1.5, "range=-1:", "range.min")
+	test("7", "range=1:10", "")
+	test("x", "range=1:10", "number")
+	//
+	// regex, email, url and oneof
+	test("ab12", "regex=[a-z]+[0-9]*", "")
+	test("ab12x", "regex=[a-z]+[0-9]*", "regex")
+	test("x;y", "regex=[a-z]", "regex")
+	test("x;y", `regex=[a-z]\;[a-z]`, "")
+	test("x;y", `regex=[a-z]\;[a-z]; length=:2`, "length.max")
+	test("ann@example.com", "email", "")
+	for _, it := range []string{"ann", "ann@", "@example.com",
+		"Ann <ann@example.com>", "ann@localhost", "a b@example.com"} {
+		test(it, "email", "email")
+	}
+	test("https://example.com/a?b=1", "url", "")
+	for _, it := range []string{"example.com", "/path", "http://",
+		"javascript:alert(1)", "ftp://example.com"} {
+		test(it, "url", "url")
+	}
+	test("b", "oneof=a|b|c", "")
+	test("d", "oneof=a|b|c", "oneof")
+	test(2, "oneof=1|2", "")
+	test(2.5, "oneof=1|2", "oneof")
+	//
+	// the first failing rule gives the error
+	test("a", "required; length=2:; email", "length.min")
+	test("ab", "required; length=2:; email", "email")
+	//
+	// messages use the value's name and the rule's arguments
+	zr.TEqual(t, ValidateValue("Code", "abcd", "length=1:3", "").Errors,
+		[]ValidationError{
+			{"Code", "length", "Code must have 1 to 3 characters"},
+		})
+} //                                                    Test_vald_ValidateValue_
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *ValidationResult)
+
+// go test --run Test_vald_ValidationResult_
+func Test_vald_ValidationResult_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *ValidationResult) Add(field, rule, message string)
+	// (ob *ValidationResult) FieldError(field string) string
+	// (ob *ValidationResult) Map() map[string]string
+	// (ob *ValidationResult) Node() *Node
+	// (ob *ValidationResult) OK() bool
+	//
+	var result ValidationResult
+	zr.TTrue(t, result.OK())
+	zr.TTrue(t, (*ValidationResult)(nil).OK())
+	zr.TEqual(t, result.Node().String(), "")
+	//
+	result.Add("email", "unique", "E-mail is <taken>")
+	result.Add("email", "email", "E-mail is not valid")
+	result.Add("name", "required", "Name is required")
+	zr.TTrue(t, !result.OK())
+	zr.TEqual(t, result.FieldError("email"), "E-mail is <taken>")
+	zr.TEqual(t, result.FieldError("age"), "")
+	zr.TEqual(t, result.Map(), map[string]string{
+		"email": "E-mail is <taken>",
+		"name":  "Name is required",
+	})
+	zr.TEqual(t, result.Node().String(),
+		`<ul class="errors" role="alert">`+
+			"\r\n<li>E-mail is &lt;taken&gt;</li>"+
+			"\r\n<li>E-mail is not valid</li>\r\n"+
+			"<li>Name is required</li>\r\n</ul>\r\n")
+} //                                                 Test_vald_ValidationResult_
+
+// -----------------------------------------------------------------------------
+// # Methods (ctx *Context)
+
+// go test --run Test_vald_Context_BindJSON_
+func Test_vald_Context_BindJSON_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) BindJSON(model interface{}) *ValidationResult
+	//
+	test := func(body, language string) (
+		validateTestAccount, *ValidationResult) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Accept-Language", language)
+		ctx := NewContext(httptest.NewRecorder(), req, nil)
+		var account validateTestAccount
+		result := ctx.BindJSON(&account)
+		return account, result
+	}
+	account, result := test(`{"name":"Ann","age":20}`, "")
+	zr.TTrue(t, result.OK())
+	zr.TEqual(t, account.Name, "Ann")
+	zr.TEqual(t, account.Age, 20)
+	//
+	_, result = test(`{"name":"A","email":"x"}`, "")
+	zr.TEqual(t, result.Map(), map[string]string{
+		"name":  "Name must have 2 to 10 characters",
+		"email": "E-mail must be a valid email address",
+		"age":   "Age must be at least 18",
+	})
+	_, result = test(`{"name":`, "")
+	zr.TEqual(t, result.Errors, []ValidationError{
+		{"", "json", "The data is not valid JSON"},
+	})
+	//
+	// messages are in the client's language
+	RegisterValidationMessages("xx", map[string]string{
+		"required": "{field} fehlt",
+	})
+	_, result = test(`{}`, "xx-YY, en;q=0.5")
+	zr.TEqual(t, result.FieldError("name"), "Name fehlt")
+} //                                                 Test_vald_Context_BindJSON_
+
+// go test --run Test_vald_Context_ReplyErrors_
+func Test_vald_Context_ReplyErrors_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) ReplyErrors(result *ValidationResult)
+	//
+	test := func(result *ValidationResult, expect string) {
+		rec := httptest.NewRecorder()
+		ctx := NewContext(rec, httptest.NewRequest("POST", "/", nil), nil)
+		ctx.ReplyErrors(result)
+		zr.TEqual(t, rec.Code, 422)
+		zr.TEqual(t, rec.Header().Get("Content-Type"), "application/json")
+		zr.TEqual(t, rec.Body.String(), expect)
+	}
+	test(ValidateValue("email", "x", "email", ""),
+		`{"errors":[{"field":"email","rule":"email",`+
+			`"message":"email must be a valid email address"}]}`)
+	test(nil, `{"errors":[]}`)
+	test(&ValidationResult{}, `{"errors":[]}`)
+} //                                              Test_vald_Context_ReplyErrors_
+
+// go test --run Test_vald_Context_ValidationLocale_
+func Test_vald_Context_ValidationLocale_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) ValidationLocale() string
+	//
+	RegisterValidationMessages("xx", map[string]string{})
+	RegisterValidationMessages("yy-zz", map[string]string{})
+	test := func(language, expect string) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Language", language)
+		ctx := NewContext(httptest.NewRecorder(), req, nil)
+		zr.TEqual(t, ctx.ValidationLocale(), expect)
+	}
+	test("", "en")
+	test("xx", "xx")
+	test("XX-AA", "xx")
+	test("yy-ZZ", "yy-zz")
+	test("yy", "en")
+	test("fr, xx;q=0.8, en;q=0.9", "en")
+	test("en;q=0.5, xx", "xx")
+	test("xx;q=0, fr", "en")
+} //                                         Test_vald_Context_ValidationLocale_
+
+// end
@@ -0,0 +1,553 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                                zr-web/[websocket.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+//	WebSocket implements the server side of the WebSocket protocol
+//	(RFC 6455) without external dependencies. Get one by calling
+//	Context.Upgrade() in a handler, then read and write messages:
+//
+//	func chatServe(w http.ResponseWriter, req *http.Request) {
+//		ctx := web.NewContext(w, req, &sessions)
+//		ws, err := ctx.Upgrade()
+//		if err != nil {
+//			return // Upgrade() already replied with an error status
+//		}
+//		defer ws.Close(web.CloseNormal, "")
+//		for {
+//			msgType, data, err := ws.ReadMessage()
+//			if err != nil {
+//				return
+//			}
+//			ws.WriteMessage(msgType, data) // echo
+//		}
+//	}
+
+//  CloseError struct
+//  WebSocket struct
+//
+// # Global Settings
+//   WebSocketOrigins() []string
+//   SetWebSocketOrigins(origins ...string)
+//
+// # Constructor (ctx *Context)
+//   Upgrade() (*WebSocket, error)
+//
+// # Methods (ob *WebSocket)
+//   ) Close(code int, reason string) error
+//   ) Ping(data []byte) error
+//   ) ReadMessage() (messageType int, data []byte, err error)
+//   ) SetReadLimit(limit int64)
+//   ) WriteMessage(messageType int, data []byte) error
+//
+// # Support (File Scope)
+//   newWebSocket(conn net.Conn, rd *bufio.Reader, isServer bool) *WebSocket
+//   (ob *WebSocket) fail(code int, reason string) error
+//   (ob *WebSocket) readFrame() (fin bool, opcode int, data []byte, err error)
+//   (ob *WebSocket) writeFrame(opcode int, data []byte) error
+//   headerHasToken(header http.Header, name, token string) bool
+//   isAllowedOrigin(origin, host string) bool
+//   isValidCloseCode(code int) bool
+//   webSocketAccept(key string) string
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/balacode/zr"
+)
+
+// WebSocket message types (frame opcodes) defined in RFC 6455.
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// WebSocket close codes defined in RFC 6455.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseAbnormal        = 1006
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+)
+
+// DefaultWebSocketReadLimit is the default maximum size
+// of a message that WebSocket.ReadMessage() accepts.
+const DefaultWebSocketReadLimit = 1 << 20 // 1 MiB
+
+// webSocketGUID is appended to the client's key to compute the
+// 'Sec-WebSocket-Accept' value of the handshake (RFC 6455 4.2.2)
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrWebSocketClosed is returned when writing to a closed WebSocket.
+var ErrWebSocketClosed = errors.New("websocket closed")
+
+// webSocketOrigins holds the origins, other than the origin of the
+// requested host, from which Upgrade() accepts WebSocket handshakes.
+var webSocketOrigins []string
+
+// webSocketOriginsMutex guards webSocketOrigins
+var webSocketOriginsMutex sync.RWMutex
+
+// CloseError is returned by ReadMessage() when
+// the peer closes the connection, or on a protocol error.
+type CloseError struct {
+	Code   int
+	Reason string
+} //                                                                  CloseError
+
+// Error returns the error message and implements the error interface.
+func (ob *CloseError) Error() string {
+	if ob.Reason == "" {
+		return fmt.Sprintf("websocket closed: %d", ob.Code)
+	}
+	return fmt.Sprintf("websocket closed: %d %s", ob.Code, ob.Reason)
+} //                                                                       Error
+
+// WebSocket is a WebSocket connection. One goroutine may read from it
+// while others write to it. Ping frames received while reading are
+// answered automatically.
+type WebSocket struct {
+	conn       net.Conn
+	rd         *bufio.Reader
+	isServer   bool  // server side reads masked, writes unmasked frames
+	readLimit  int64 // maximum size of a received message
+	closeSent  bool
+	writeMutex sync.Mutex
+} //                                                                   WebSocket
+
+// -----------------------------------------------------------------------------
+// # Global Settings
+
+// WebSocketOrigins returns the list of allowed
+// origins set by SetWebSocketOrigins().
+func WebSocketOrigins() []string {
+	webSocketOriginsMutex.RLock()
+	defer webSocketOriginsMutex.RUnlock()
+	return append([]string{}, webSocketOrigins...)
+} //                                                            WebSocketOrigins
+
+// SetWebSocketOrigins specifies other origins, e.g. "https://example.org",
+// from which pages may open WebSockets. By default, Upgrade() only
+// accepts handshakes from pages served by the same host, which
+// prevents other sites from using a visitor's session cookie.
+func SetWebSocketOrigins(origins ...string) {
+	list := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimRight(origin, "/ "))
+		if origin != "" {
+			list = append(list, origin)
+		}
+	}
+	webSocketOriginsMutex.Lock()
+	webSocketOrigins = list
+	webSocketOriginsMutex.Unlock()
+} //                                                         SetWebSocketOrigins
+
+// -----------------------------------------------------------------------------
+// # Constructor (ctx *Context)
+
+// Upgrade performs the WebSocket opening handshake and
+// takes over the request's connection.
+//
+// The 'Origin' header must belong to the host that served the session's
+// pages, or to one of the origins allowed with SetWebSocketOrigins().
+//
+// If the request is not a valid handshake, replies with
+// an HTTP error status and returns an error.
+func (ctx *Context) Upgrade() (*WebSocket, error) {
+	var (
+		req    = ctx.req
+		header = req.Header
+		fail   = func(status int, msg string) (*WebSocket, error) {
+			http.Error(ctx.w, http.StatusText(status), status)
+			return nil, zr.Error("WebSocket handshake:", msg)
+		}
+	)
+	if req.Method != "GET" {
+		return fail(http.StatusMethodNotAllowed, "method is not GET")
+	}
+	if !headerHasToken(header, "Connection", "upgrade") ||
+		!headerHasToken(header, "Upgrade", "websocket") {
+		return fail(http.StatusBadRequest, "missing upgrade headers")
+	}
+	if header.Get("Sec-WebSocket-Version") != "13" {
+		ctx.w.Header().Set("Sec-WebSocket-Version", "13")
+		return fail(http.StatusUpgradeRequired, "unsupported version")
+	}
+	key := strings.TrimSpace(header.Get("Sec-WebSocket-Key"))
+	if b, err := base64.StdEncoding.DecodeString(key); err != nil ||
+		len(b) != 16 {
+		return fail(http.StatusBadRequest, "invalid Sec-WebSocket-Key")
+	}
+	if !isAllowedOrigin(header.Get("Origin"), ctx.Host()) {
+		return fail(http.StatusForbidden, "origin not allowed")
+	}
+	hijacker, ok := ctx.w.(http.Hijacker)
+	if !ok {
+		return fail(http.StatusInternalServerError, "can't hijack")
+	}
+	conn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, zr.Error("WebSocket handshake:", err)
+	}
+	brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + webSocketAccept(key) + "\r\n\r\n")
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, zr.Error("WebSocket handshake:", err)
+	}
+	return newWebSocket(conn, brw.Reader, true), nil
+} //                                                                     Upgrade
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *WebSocket)
+
+// Close sends a close frame with the given close code and reason
+// (unless one was already sent) and closes the connection. The
+// reason is cut to fit in the frame, at a character boundary.
+// Codes 1005 and 1006 must not be sent, so they send a close
+// frame without a code.
+func (ob *WebSocket) Close(code int, reason string) error {
+	ob.writeMutex.Lock()
+	sent := ob.closeSent
+	ob.writeMutex.Unlock()
+	var err error
+	if !sent {
+		var data []byte
+		if code != CloseNoStatus && code != CloseAbnormal {
+			// a control frame holds 125 bytes: the code and 123 more
+			if n := 123; len(reason) > n {
+				for n > 0 && !utf8.RuneStart(reason[n]) {
+					n--
+				}
+				reason = reason[:n]
+			}
+			data = make([]byte, 2, 2+len(reason))
+			binary.BigEndian.PutUint16(data, uint16(code))
+			data = append(data, reason...)
+		}
+		err = ob.writeFrame(CloseMessage, data)
+	}
+	if err2 := ob.conn.Close(); err == nil {
+		err = err2
+	}
+	return err
+} //                                                                       Close
+
+// Ping sends a ping frame. The peer should reply
+// with a pong, which ReadMessage() discards.
+func (ob *WebSocket) Ping(data []byte) error {
+	if len(data) > 125 {
+		return zr.Error(zr.EInvalidArg, "^data", "longer than 125 bytes")
+	}
+	return ob.writeFrame(PingMessage, data)
+} //                                                                        Ping
+
+// ReadMessage reads the next text or binary message, joining fragmented
+// messages. It answers pings and discards pongs while reading.
+// When the peer sends a close frame, it is echoed back and
+// a *CloseError holding the peer's close code is returned.
+// Protocol violations, invalid UTF-8 text and messages longer
+// than the read limit close the connection with the
+// corresponding close code and return a *CloseError.
+func (ob *WebSocket) ReadMessage() (messageType int, data []byte, err error) {
+	for {
+		fin, opcode, payload, rerr := ob.readFrame()
+		if rerr != nil {
+			return 0, nil, rerr
+		}
+		switch opcode {
+		case PingMessage:
+			if werr := ob.writeFrame(PongMessage, payload); werr != nil {
+				return 0, nil, werr
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			code, reason := CloseNoStatus, ""
+			if len(payload) == 1 {
+				return 0, nil, ob.fail(CloseProtocolError, "bad close frame")
+			}
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+				reason = string(payload[2:])
+				if !isValidCloseCode(code) {
+					return 0, nil, ob.fail(CloseProtocolError, "bad close code")
+				}
+				if !utf8.ValidString(reason) {
+					return 0, nil, ob.fail(CloseInvalidPayload, "bad reason")
+				}
+			}
+			ob.writeMutex.Lock()
+			sent := ob.closeSent
+			ob.writeMutex.Unlock()
+			if !sent {
+				echo := payload
+				if len(echo) > 2 {
+					echo = echo[:2]
+				}
+				ob.writeFrame(CloseMessage, echo) // peer may be gone already
+			}
+			return 0, nil, &CloseError{Code: code, Reason: reason}
+		case 0:
+			if messageType == 0 {
+				return 0, nil, ob.fail(CloseProtocolError,
+					"unexpected continuation frame")
+			}
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, ob.fail(CloseProtocolError,
+					"expected continuation frame")
+			}
+			messageType = opcode
+		default:
+			return 0, nil, ob.fail(CloseProtocolError, "unknown opcode")
+		}
+		if int64(len(data))+int64(len(payload)) > ob.readLimit {
+			return 0, nil, ob.fail(CloseMessageTooBig, "message too big")
+		}
+		data = append(data, payload...)
+		if !fin {
+			continue
+		}
+		if messageType == TextMessage && !utf8.Valid(data) {
+			return 0, nil, ob.fail(CloseInvalidPayload, "invalid UTF-8")
+		}
+		return messageType, data, nil
+	}
+} //                                                                 ReadMessage
+
+// SetReadLimit sets the maximum size of a message in bytes.
+// If the peer sends a longer message, ReadMessage() closes the
+// connection with code 1009 (message too big).
+func (ob *WebSocket) SetReadLimit(limit int64) {
+	ob.readLimit = limit
+} //                                                                SetReadLimit
+
+// WriteMessage sends a text or binary message in a single frame.
+func (ob *WebSocket) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return zr.Error(zr.EInvalidArg, "^messageType", ":^", messageType)
+	}
+	return ob.writeFrame(messageType, data)
+} //                                                                WriteMessage
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// newWebSocket creates a WebSocket over an established connection.
+// 'rd' reads from 'conn' and may hold data already buffered.
+// 'isServer' must be true on the server side and false on the
+// client side, which masks the frames it sends.
+func newWebSocket(conn net.Conn, rd *bufio.Reader, isServer bool) *WebSocket {
+	if rd == nil {
+		rd = bufio.NewReader(conn)
+	}
+	return &WebSocket{
+		conn:      conn,
+		rd:        rd,
+		isServer:  isServer,
+		readLimit: DefaultWebSocketReadLimit,
+	}
+} //                                                                newWebSocket
+
+// fail sends a close frame with the given code, closes
+// the connection and returns a matching *CloseError.
+func (ob *WebSocket) fail(code int, reason string) error {
+	ob.Close(code, reason)
+	return &CloseError{Code: code, Reason: reason}
+} //                                                                        fail
+
+// readFrame reads a single frame and unmasks its payload.
+func (ob *WebSocket) readFrame() (fin bool, opcode int, data []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(ob.rd, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = head[0]&0x80 != 0
+	opcode = int(head[0] & 0x0F)
+	var (
+		masked = head[1]&0x80 != 0
+		size   = int64(head[1] & 0x7F)
+	)
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, ob.fail(CloseProtocolError, "reserved bits set")
+	}
+	if masked != ob.isServer {
+		return false, 0, nil, ob.fail(CloseProtocolError, "bad masking")
+	}
+	isControl := opcode >= CloseMessage
+	if isControl && (!fin || size > 125) {
+		return false, 0, nil, ob.fail(CloseProtocolError, "bad control frame")
+	}
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(ob.rd, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		size = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(ob.rd, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		size = int64(binary.BigEndian.Uint64(ext[:]))
+		if size < 0 {
+			return false, 0, nil, ob.fail(CloseProtocolError, "bad length")
+		}
+	}
+	if !isControl && size > ob.readLimit {
+		return false, 0, nil, ob.fail(CloseMessageTooBig, "message too big")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(ob.rd, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	data = make([]byte, size)
+	if _, err = io.ReadFull(ob.rd, data); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range data {
+			data[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, data, nil
+} //                                                                   readFrame
+
+// writeFrame writes a single, final frame.
+// Frames written after a close frame are rejected.
+func (ob *WebSocket) writeFrame(opcode int, data []byte) error {
+	ob.writeMutex.Lock()
+	defer ob.writeMutex.Unlock()
+	if ob.closeSent {
+		return ErrWebSocketClosed
+	}
+	if opcode == CloseMessage {
+		ob.closeSent = true
+	}
+	frame := make([]byte, 0, 14+len(data))
+	frame = append(frame, 0x80|byte(opcode))
+	var maskBit byte
+	if !ob.isServer {
+		maskBit = 0x80
+	}
+	switch n := len(data); {
+	case n <= 125:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, maskBit|126, byte(n>>8), byte(n))
+	default:
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(n))
+		frame = append(frame, maskBit|127)
+		frame = append(frame, ext[:]...)
+	}
+	if ob.isServer {
+		frame = append(frame, data...)
+	} else {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		for i, b := range data {
+			frame = append(frame, b^mask[i%4])
+		}
+	}
+	_, err := ob.conn.Write(frame)
+	return err
+} //                                                                  writeFrame
+
+// headerHasToken returns true if the comma-separated list
+// in header 'name' contains 'token' (case-insensitive).
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, s := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(s), token) {
+				return true
+			}
+		}
+	}
+	return false
+} //                                                              headerHasToken
+
+// isAllowedOrigin returns true if 'origin' (the value of the 'Origin'
+// header) is blank, belongs to 'host', or is one of the allowed origins.
+// Browsers always send 'Origin' with WebSocket handshakes, so a blank
+// value means the client isn't a browser and can't carry a visitor's
+// cookies across sites.
+func isAllowedOrigin(origin, host string) bool {
+	origin = strings.ToLower(strings.TrimSpace(origin))
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, host) {
+		return true
+	}
+	webSocketOriginsMutex.RLock()
+	defer webSocketOriginsMutex.RUnlock()
+	for _, allowed := range webSocketOrigins {
+		if origin == allowed {
+			return true
+		}
+	}
+	return false
+} //                                                             isAllowedOrigin
+
+// isValidCloseCode returns true if a peer may send close 'code'
+// (RFC 6455, section 7.4). Codes 1005, 1006 and 1015 are only for
+// reporting, and codes below 3000 that are not registered are
+// reserved. Codes 3000 to 4999 are for libraries and applications.
+func isValidCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1014:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+} //                                                            isValidCloseCode
+
+// webSocketAccept computes the 'Sec-WebSocket-Accept'
+// handshake header value for the client's key.
+func webSocketAccept(key string) string {
+	hash := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+} //                                                             webSocketAccept
+
+// end
@@ -0,0 +1,396 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                           zr-web/[websocket_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package web
+
+// # Constructor
+//   Test_wsck_Context_Upgrade_
+//
+// # Methods
+//   Test_wsck_WebSocket_Close_
+//   Test_wsck_WebSocket_Ping_
+//   Test_wsck_WebSocket_ReadMessage_
+//   Test_wsck_WebSocket_SetReadLimit_
+//   Test_wsck_WebSocket_WriteMessage_
+
+//  to test all items in websocket.go use:
+//      go test --run Test_wsck_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/balacode/zr"
+)
+
+// hijackRecorder is a response recorder that can be
+// hijacked, handing over one end of an in-process pipe.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+} //                                                              hijackRecorder
+
+// Hijack implements the http.Hijacker interface.
+func (ob *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	rw := bufio.NewReadWriter(
+		bufio.NewReader(ob.conn),
+		bufio.NewWriter(ob.conn),
+	)
+	return ob.conn, rw, nil
+} //                                                                      Hijack
+
+// wsTestFrame holds a frame received by the client side of a test.
+type wsTestFrame struct {
+	opcode int
+	data   []byte
+} //                                                                 wsTestFrame
+
+// newTestHandshake creates a valid WebSocket handshake request.
+func newTestHandshake() *http.Request {
+	req := httptest.NewRequest("GET", "http://example.com/ws", nil)
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Origin", "http://example.com")
+	return req
+} //                                                            newTestHandshake
+
+// newTestWebSocketPair upgrades a handshake over a net.Pipe and returns
+// the server side, and the client side with a channel that receives
+// all frames the server sends to the client.
+func newTestWebSocketPair(t *testing.T,
+) (server, client *WebSocket, received chan wsTestFrame) {
+	srvConn, cliConn := net.Pipe()
+	req := newTestHandshake()
+	rec := &hijackRecorder{httptest.NewRecorder(), srvConn}
+	ctx := NewContext(rec, req, nil)
+	done := make(chan error)
+	go func() {
+		var err error
+		server, err = ctx.Upgrade()
+		done <- err
+	}()
+	rd := bufio.NewReader(cliConn)
+	resp, err := http.ReadResponse(rd, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	zr.TEqual(t, resp.StatusCode, http.StatusSwitchingProtocols)
+	zr.TEqual(t, resp.Header.Get("Sec-WebSocket-Accept"),
+		"s3pPLMBiTxaQ9kYGzzhZRbK+xOo=") // example from RFC 6455
+	client = newWebSocket(cliConn, rd, false)
+	received = make(chan wsTestFrame, 16)
+	go func() {
+		for {
+			_, opcode, data, err := client.readFrame()
+			if err != nil {
+				close(received)
+				return
+			}
+			received <- wsTestFrame{opcode, data}
+		}
+	}()
+	return server, client, received
+} //                                                        newTestWebSocketPair
+
+// rawClientFrame composes a masked frame as sent by a client.
+func rawClientFrame(fin bool, opcode int, data []byte) []byte {
+	var head byte = byte(opcode)
+	if fin {
+		head |= 0x80
+	}
+	mask := []byte{1, 2, 3, 4}
+	ret := []byte{head}
+	switch n := len(data); {
+	case n <= 125:
+		ret = append(ret, 0x80|byte(n))
+	default:
+		ret = append(ret, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(ret[2:], uint16(n))
+	}
+	ret = append(ret, mask...)
+	for i, b := range data {
+		ret = append(ret, b^mask[i%4])
+	}
+	return ret
+} //                                                              rawClientFrame
+
+// -----------------------------------------------------------------------------
+// # Constructor
+
+// go test --run Test_wsck_Context_Upgrade_
+func Test_wsck_Context_Upgrade_(t *testing.T) {
+	zr.TBegin(t)
+	// (ctx *Context) Upgrade() (*WebSocket, error)
+	//
+	// valid handshake
+	{
+		server, client, _ := newTestWebSocketPair(t)
+		server.conn.Close()
+		client.conn.Close()
+	}
+	// invalid handshakes
+	test := func(modify func(req *http.Request), expectStatus int) {
+		req := newTestHandshake()
+		modify(req)
+		rec := httptest.NewRecorder()
+		ctx := NewContext(rec, req, nil)
+		ws, err := ctx.Upgrade()
+		zr.TTrue(t, ws == nil)
+		zr.TTrue(t, err != nil)
+		zr.TEqual(t, rec.Code, expectStatus)
+	}
+	test(func(req *http.Request) {
+		req.Method = "POST"
+	}, http.StatusMethodNotAllowed)
+	test(func(req *http.Request) {
+		req.Header.Del("Upgrade")
+	}, http.StatusBadRequest)
+	test(func(req *http.Request) {
+		req.Header.Set("Sec-WebSocket-Version", "8")
+	}, http.StatusUpgradeRequired)
+	test(func(req *http.Request) {
+		req.Header.Set("Sec-WebSocket-Key", "short")
+	}, http.StatusBadRequest)
+	test(func(req *http.Request) {
+		req.Header.Set("Origin", "http://evil.com")
+	}, http.StatusForbidden)
+	//
+	// origins allowed with SetWebSocketOrigins()
+	defer SetWebSocketOrigins()
+	SetWebSocketOrigins("https://app.example.org/")
+	zr.TEqual(t, WebSocketOrigins(), []string{"https://app.example.org"})
+	zr.TTrue(t, isAllowedOrigin("https://app.example.org", "example.com"))
+	zr.TFalse(t, isAllowedOrigin("http://app.example.org", "example.com"))
+	zr.TTrue(t, isAllowedOrigin("", "example.com"))
+} //                                                  Test_wsck_Context_Upgrade_
+
+// -----------------------------------------------------------------------------
+// # Methods
+
+// go test --run Test_wsck_WebSocket_Close_
+func Test_wsck_WebSocket_Close_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *WebSocket) Close(code int, reason string) error
+	//
+	// client closes: server gets the code and reason
+	{
+		server, client, _ := newTestWebSocketPair(t)
+		go client.Close(CloseNormal, "bye")
+		_, _, err := server.ReadMessage()
+		zr.TEqual(t, err, &CloseError{Code: CloseNormal, Reason: "bye"})
+		zr.TEqual(t, server.WriteMessage(TextMessage, []byte("x")),
+			ErrWebSocketClosed)
+		server.Close(CloseNormal, "")
+	}
+	// server closes: client receives the close frame
+	{
+		server, client, received := newTestWebSocketPair(t)
+		go server.Close(CloseGoingAway, "restart")
+		frame := <-received
+		zr.TEqual(t, frame.opcode, CloseMessage)
+		zr.TEqual(t, int(binary.BigEndian.Uint16(frame.data)), CloseGoingAway)
+		zr.TEqual(t, string(frame.data[2:]), "restart")
+		client.conn.Close()
+	}
+	// long reasons are cut at a character boundary
+	{
+		server, client, received := newTestWebSocketPair(t)
+		go server.Close(CloseNormal, strings.Repeat("é", 100))
+		frame := <-received
+		zr.TEqual(t, len(frame.data), 124)
+		zr.TTrue(t, utf8.Valid(frame.data[2:]))
+		zr.TEqual(t, string(frame.data[2:]), strings.Repeat("é", 61))
+		client.conn.Close()
+	}
+	// codes that must not be sent give a close frame without a code
+	{
+		server, client, received := newTestWebSocketPair(t)
+		go server.Close(CloseNoStatus, "x")
+		frame := <-received
+		zr.TEqual(t, frame.opcode, CloseMessage)
+		zr.TEqual(t, len(frame.data), 0)
+		client.conn.Close()
+	}
+	// valid close codes from the peer are accepted
+	for _, code := range []int{1000, 1003, 1007, 1014, 3000, 4999} {
+		server, client, _ := newTestWebSocketPair(t)
+		go client.Close(code, "")
+		_, _, err := server.ReadMessage()
+		zr.TEqual(t, err, &CloseError{Code: code})
+		server.Close(CloseNormal, "")
+	}
+} //                                                  Test_wsck_WebSocket_Close_
+
+// go test --run Test_wsck_WebSocket_Ping_
+func Test_wsck_WebSocket_Ping_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *WebSocket) Ping(data []byte) error
+	//
+	server, client, received := newTestWebSocketPair(t)
+	defer client.conn.Close()
+	defer server.conn.Close()
+	//
+	zr.TTrue(t, server.Ping(make([]byte, 126)) != nil)
+	go server.Ping([]byte("p1"))
+	frame := <-received
+	zr.TEqual(t, frame.opcode, PingMessage)
+	zr.TEqual(t, string(frame.data), "p1")
+	//
+	// pings from the client are answered with pongs
+	go client.conn.Write(append(
+		rawClientFrame(true, PingMessage, []byte("p2")),
+		rawClientFrame(true, TextMessage, []byte("t"))...,
+	))
+	_, data, err := server.ReadMessage()
+	zr.TEqual(t, err, nil)
+	zr.TEqual(t, string(data), "t")
+	frame = <-received
+	zr.TEqual(t, frame.opcode, PongMessage)
+	zr.TEqual(t, string(frame.data), "p2")
+} //                                                   Test_wsck_WebSocket_Ping_
+
+// go test --run Test_wsck_WebSocket_ReadMessage_
+func Test_wsck_WebSocket_ReadMessage_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *WebSocket) ReadMessage() (messageType int, data []byte, err error)
+	//
+	// text and binary messages from a masking client
+	{
+		server, client, _ := newTestWebSocketPair(t)
+		go client.WriteMessage(TextMessage, []byte("hello"))
+		msgType, data, err := server.ReadMessage()
+		zr.TEqual(t, err, nil)
+		zr.TEqual(t, msgType, TextMessage)
+		zr.TEqual(t, string(data), "hello")
+		//
+		big := bytes.Repeat([]byte{0xFF}, 70000)
+		go client.WriteMessage(BinaryMessage, big)
+		msgType, data, err = server.ReadMessage()
+		zr.TEqual(t, err, nil)
+		zr.TEqual(t, msgType, BinaryMessage)
+		zr.TTrue(t, bytes.Equal(data, big))
+		server.conn.Close()
+		client.conn.Close()
+	}
+	// fragmented message with a control frame in between
+	{
+		server, client, received := newTestWebSocketPair(t)
+		go func() {
+			var raw []byte
+			raw = append(raw, rawClientFrame(false, TextMessage, []byte("a"))...)
+			raw = append(raw, rawClientFrame(true, PongMessage, nil)...)
+			raw = append(raw, rawClientFrame(false, 0, []byte("b"))...)
+			raw = append(raw, rawClientFrame(true, 0, []byte("c"))...)
+			client.conn.Write(raw)
+		}()
+		msgType, data, err := server.ReadMessage()
+		zr.TEqual(t, err, nil)
+		zr.TEqual(t, msgType, TextMessage)
+		zr.TEqual(t, string(data), "abc")
+		server.conn.Close()
+		client.conn.Close()
+		for range received {
+		}
+	}
+	// protocol errors
+	test := func(raw []byte, expectCode int) {
+		server, client, received := newTestWebSocketPair(t)
+		go client.conn.Write(raw)
+		_, _, err := server.ReadMessage()
+		closeErr, ok := err.(*CloseError)
+		zr.TTrue(t, ok)
+		if ok {
+			zr.TEqual(t, closeErr.Code, expectCode)
+		}
+		frame := <-received
+		zr.TEqual(t, frame.opcode, CloseMessage)
+		zr.TEqual(t, int(binary.BigEndian.Uint16(frame.data)), expectCode)
+		client.conn.Close()
+	}
+	test(rawClientFrame(true, 0, []byte("x")), CloseProtocolError)
+	test(rawClientFrame(true, 3, []byte("x")), CloseProtocolError)
+	test(rawClientFrame(false, PingMessage, nil), CloseProtocolError)
+	test(rawClientFrame(true, TextMessage, []byte{0xC0, 0xAF}),
+		CloseInvalidPayload)
+	test(append(rawClientFrame(false, TextMessage, []byte("a")),
+		rawClientFrame(true, TextMessage, []byte("b"))...),
+		CloseProtocolError)
+	test([]byte{0x81, 0x01, 'x'}, CloseProtocolError) // unmasked
+	test([]byte{0xC1, 0x80, 0, 0, 0, 0}, CloseProtocolError)
+	//
+	// close codes the peer must not send
+	closeFrame := func(code int) []byte {
+		data := make([]byte, 2)
+		binary.BigEndian.PutUint16(data, uint16(code))
+		return rawClientFrame(true, CloseMessage, data)
+	}
+	for _, code := range []int{0, 999, 1004, 1005, 1006, 1015, 1016, 2999,
+		5000, 65535} {
+		test(closeFrame(code), CloseProtocolError)
+	}
+	test(rawClientFrame(true, CloseMessage, []byte{3}), CloseProtocolError)
+} //                                            Test_wsck_WebSocket_ReadMessage_
+
+// go test --run Test_wsck_WebSocket_SetReadLimit_
+func Test_wsck_WebSocket_SetReadLimit_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *WebSocket) SetReadLimit(limit int64)
+	//
+	test := func(raw []byte) {
+		server, client, received := newTestWebSocketPair(t)
+		server.SetReadLimit(4)
+		go client.conn.Write(raw)
+		_, _, err := server.ReadMessage()
+		zr.TEqual(t, err,
+			&CloseError{Code: CloseMessageTooBig, Reason: "message too big"})
+		frame := <-received
+		zr.TEqual(t, int(binary.BigEndian.Uint16(frame.data)),
+			CloseMessageTooBig)
+		client.conn.Close()
+	}
+	// single frame
+	test(rawClientFrame(true, BinaryMessage, []byte("12345")))
+	//
+	// fragments adding up to more than the limit
+	test(append(rawClientFrame(false, BinaryMessage, []byte("123")),
+		rawClientFrame(true, 0, []byte("45"))...))
+} //                                           Test_wsck_WebSocket_SetReadLimit_
+
+// go test --run Test_wsck_WebSocket_WriteMessage_
+func Test_wsck_WebSocket_WriteMessage_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *WebSocket) WriteMessage(messageType int, data []byte) error
+	//
+	server, client, received := newTestWebSocketPair(t)
+	defer client.conn.Close()
+	defer server.conn.Close()
+	//
+	zr.TTrue(t, server.WriteMessage(PingMessage, nil) != nil)
+	for _, size := range []int{0, 125, 126, 65535, 65536} {
+		data := bytes.Repeat([]byte{'z'}, size)
+		go server.WriteMessage(BinaryMessage, data)
+		frame := <-received
+		zr.TEqual(t, frame.opcode, BinaryMessage)
+		zr.TTrue(t, bytes.Equal(frame.data, data))
+	}
+} //                                           Test_wsck_WebSocket_WriteMessage_
+
+// end
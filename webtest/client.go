@@ -0,0 +1,254 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                           zr-web/webtest/[client.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package webtest
+
+//	Client sends requests straight to a handler, without a network,
+//	and keeps the cookies the handler sets, so a series of requests
+//	continues the same session:
+//
+//	var sessions web.Sessions
+//
+//	func Test_Login(t *testing.T) {
+//		client := webtest.NewClient(t, http.HandlerFunc(mainServe),
+//			&sessions)
+//		client.Get("/login").
+//			AssertStatus(200).
+//			AssertMediaType("html").
+//			AssertExists("form#login input[name=user]")
+//		client.PostForm("/login", url.Values{"user": {"bob"}}).
+//			AssertStatus(303).
+//			AssertHeader("Location", "/home")
+//		if client.Session().GetSetting("user") != "bob" {
+//			t.Error("not logged in")
+//		}
+//	}
+//
+//	SeedSession() prepares a session before the first request,
+//	e.g. to test pages that need a logged-in user.
+
+//  Client struct
+//
+// # Constructor
+//   NewClient(t testing.TB, handler http.Handler, sessions *web.Sessions,
+//       ) *Client
+//
+// # Methods (ob *Client)
+//   ) Cookie(name string) string
+//   ) Do(req *http.Request) *Response
+//   ) Get(path string) *Response
+//   ) PostForm(path string, values url.Values) *Response
+//   ) PostJSON(path string, value interface{}) *Response
+//   ) SeedSession(settings map[string]string) *web.Session
+//   ) Session() *web.Session
+//   ) SetCookie(name, value string)
+//
+// # Support (File Scope)
+//   (ob *Client) baseURL() string
+//   (ob *Client) initJar()
+//   (ob *Client) newRequest(method, path string, body io.Reader,
+//       ) *http.Request
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	web "github.com/balacode/zr-web"
+)
+
+// DefaultBaseURL is the scheme and host of requests
+// whose path doesn't specify them.
+const DefaultBaseURL = "http://example.com"
+
+// sessionCookieName is the name of the cookie
+// that holds the session ID (see web.Sessions)
+const sessionCookieName = "app_session_id"
+
+// Client sends requests to a handler and keeps cookies between them.
+type Client struct {
+	// T reports failed assertions.
+	T testing.TB
+
+	// Handler serves the client's requests.
+	Handler http.Handler
+
+	// Sessions, if set, are the sessions used by Handler.
+	// They are needed by Session() and SeedSession().
+	Sessions *web.Sessions
+
+	// BaseURL is the scheme and host of requests
+	// made with a path. Defaults to DefaultBaseURL.
+	BaseURL string
+
+	// Header holds headers sent with every request.
+	Header http.Header
+
+	jar *cookiejar.Jar
+} //                                                                      Client
+
+// -----------------------------------------------------------------------------
+// # Constructor
+
+// NewClient creates a Client that sends requests to 'handler',
+// which uses 'sessions'. 'sessions' can be nil.
+func NewClient(t testing.TB, handler http.Handler, sessions *web.Sessions,
+) *Client {
+	return &Client{
+		T:        t,
+		Handler:  handler,
+		Sessions: sessions,
+		Header:   http.Header{},
+	}
+} //                                                                   NewClient
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Client)
+
+// Cookie returns the value of the named cookie that the client
+// holds for BaseURL, or a blank string if there is no such cookie.
+func (ob *Client) Cookie(name string) string {
+	if ob.jar == nil {
+		return ""
+	}
+	u, _ := url.Parse(ob.baseURL())
+	for _, cookie := range ob.jar.Cookies(u) {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+	return ""
+} //                                                                      Cookie
+
+// Do sends 'req' to the handler, with the client's headers and
+// cookies, saves the cookies the handler sets and returns the reply.
+func (ob *Client) Do(req *http.Request) *Response {
+	ob.T.Helper()
+	for name, values := range ob.Header {
+		if _, exists := req.Header[name]; !exists {
+			req.Header[name] = values
+		}
+	}
+	if ob.jar != nil {
+		for _, cookie := range ob.jar.Cookies(req.URL) {
+			req.AddCookie(cookie)
+		}
+	}
+	rec := httptest.NewRecorder()
+	ob.Handler.ServeHTTP(rec, req)
+	result := rec.Result()
+	if cookies := result.Cookies(); len(cookies) > 0 {
+		ob.initJar()
+		ob.jar.SetCookies(req.URL, cookies)
+	}
+	return &Response{
+		T:      ob.T,
+		Code:   rec.Code,
+		Header: rec.Header(),
+		Body:   rec.Body.String(),
+	}
+} //                                                                          Do
+
+// Get sends a GET request for 'path'.
+func (ob *Client) Get(path string) *Response {
+	ob.T.Helper()
+	return ob.Do(ob.newRequest("GET", path, nil))
+} //                                                                         Get
+
+// PostForm sends 'values' in a POST request to 'path',
+// encoded like an HTML form.
+func (ob *Client) PostForm(path string, values url.Values) *Response {
+	ob.T.Helper()
+	req := ob.newRequest("POST", path, strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return ob.Do(req)
+} //                                                                    PostForm
+
+// PostJSON sends 'value' encoded as JSON in a POST request to 'path'.
+func (ob *Client) PostJSON(path string, value interface{}) *Response {
+	ob.T.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		ob.T.Fatalf("PostJSON %s: %v", path, err)
+	}
+	req := ob.newRequest("POST", path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	return ob.Do(req)
+} //                                                                    PostJSON
+
+// SeedSession prepares the client's session before a request:
+// it stores 'settings' in the client's current session, or in
+// a new session whose ID it saves in the session cookie.
+func (ob *Client) SeedSession(settings map[string]string) *web.Session {
+	if ob.Sessions == nil {
+		ob.T.Fatal("SeedSession: Client.Sessions is nil")
+	}
+	ses := ob.Session()
+	if ses == nil {
+		ses = ob.Sessions.New()
+		ob.SetCookie(sessionCookieName, ses.ID())
+	}
+	for name, value := range settings {
+		ses.SetSetting(name, value)
+	}
+	return ses
+} //                                                                 SeedSession
+
+// Session returns the session identified by the client's session
+// cookie, or nil if there is no such session yet.
+func (ob *Client) Session() *web.Session {
+	if ob.Sessions == nil {
+		return nil
+	}
+	id := ob.Cookie(sessionCookieName)
+	if id == "" {
+		return nil
+	}
+	return ob.Sessions.Get(id)
+} //                                                                     Session
+
+// SetCookie sets a cookie that is sent with requests to BaseURL.
+func (ob *Client) SetCookie(name, value string) {
+	ob.initJar()
+	u, _ := url.Parse(ob.baseURL())
+	ob.jar.SetCookies(u, []*http.Cookie{{Name: name, Value: value, Path: "/"}})
+} //                                                                   SetCookie
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// baseURL returns BaseURL, or DefaultBaseURL if it is blank.
+func (ob *Client) baseURL() string {
+	if ob.BaseURL == "" {
+		return DefaultBaseURL
+	}
+	return strings.TrimSuffix(ob.BaseURL, "/")
+} //                                                                     baseURL
+
+// initJar creates the client's cookie jar if it doesn't exist yet.
+func (ob *Client) initJar() {
+	if ob.jar == nil {
+		ob.jar, _ = cookiejar.New(nil)
+	}
+} //                                                                     initJar
+
+// newRequest creates a request for 'path', which is
+// either a full URL or a path relative to BaseURL.
+func (ob *Client) newRequest(method, path string, body io.Reader,
+) *http.Request {
+	target := path
+	if !strings.Contains(path, "://") {
+		target = ob.baseURL() + "/" + strings.TrimPrefix(path, "/")
+	}
+	return httptest.NewRequest(method, target, body)
+} //                                                                  newRequest
+
+// end
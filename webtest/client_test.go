@@ -0,0 +1,134 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                      zr-web/webtest/[client_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package webtest
+
+// # Methods (ob *Client)
+//   Test_clnt_Client_Get_
+//   Test_clnt_Client_PostForm_
+//   Test_clnt_Client_PostJSON_
+//   Test_clnt_Client_SeedSession_
+
+//  to test all items in client.go use:
+//      go test --run Test_clnt_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/balacode/zr"
+	web "github.com/balacode/zr-web"
+)
+
+// newTestHandler returns a handler that counts visits in the
+// session, and echoes posted forms and JSON.
+func newTestHandler(sessions *web.Sessions) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := web.NewContext(w, req, sessions)
+		switch req.URL.Path {
+		case "/visit":
+			visits := zr.Int(ctx.Session.GetSetting("visits")) + 1
+			ctx.Session.SetSetting("visits", visits)
+			ctx.Reply([]byte("<p id='visits'>"+zr.String(visits)+"</p>"),
+				"html")
+		case "/form":
+			req.ParseForm()
+			ctx.Reply([]byte("<p id='name'>"+req.PostForm.Get("name")+
+				"</p>"), "html")
+		case "/json":
+			var data map[string]interface{}
+			json.Unmarshal(ctx.PostData(), &data)
+			data["user"] = ctx.UserID()
+			reply, _ := json.Marshal(data)
+			ctx.Reply(reply, "json")
+		default:
+			http.NotFound(w, req)
+		}
+	}
+} //                                                              newTestHandler
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Client)
+
+// go test --run Test_clnt_Client_Get_
+func Test_clnt_Client_Get_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Client) Get(path string) *Response
+	//
+	var sessions web.Sessions
+	client := NewClient(t, newTestHandler(&sessions), &sessions)
+	zr.TTrue(t, client.Session() == nil)
+	//
+	// the session cookie carries the session across requests
+	client.Get("/visit").AssertStatus(200).AssertText("#visits", "1")
+	client.Get("visit").AssertText("#visits", "2")
+	zr.TEqual(t, client.Session().GetSetting("visits"), "2")
+	zr.TEqual(t, len(client.Cookie("app_session_id")), 32)
+	//
+	// another client has its own session
+	other := NewClient(t, newTestHandler(&sessions), &sessions)
+	other.Get("/visit").AssertText("#visits", "1")
+	zr.TEqual(t, sessions.Len(), 2)
+	//
+	client.Get("/missing").AssertStatus(404)
+} //                                                       Test_clnt_Client_Get_
+
+// go test --run Test_clnt_Client_PostForm_
+func Test_clnt_Client_PostForm_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Client) PostForm(path string, values url.Values) *Response
+	//
+	var sessions web.Sessions
+	client := NewClient(t, newTestHandler(&sessions), &sessions)
+	client.PostForm("/form", url.Values{"name": {"Tom & Jerry"}}).
+		AssertStatus(200).
+		AssertMediaType("html").
+		AssertText("p#name", "Tom & Jerry")
+} //                                                  Test_clnt_Client_PostForm_
+
+// go test --run Test_clnt_Client_PostJSON_
+func Test_clnt_Client_PostJSON_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Client) PostJSON(path string, value interface{}) *Response
+	//
+	var sessions web.Sessions
+	client := NewClient(t, newTestHandler(&sessions), &sessions)
+	client.SeedSession(map[string]string{"user": "bob"})
+	res := client.PostJSON("/json", map[string]int{"count": 3})
+	res.AssertStatus(200).AssertMediaType("application/json")
+	var reply struct {
+		Count int    `json:"count"`
+		User  string `json:"user"`
+	}
+	res.JSON(&reply)
+	zr.TEqual(t, reply.Count, 3)
+	zr.TEqual(t, reply.User, "bob")
+} //                                                  Test_clnt_Client_PostJSON_
+
+// go test --run Test_clnt_Client_SeedSession_
+func Test_clnt_Client_SeedSession_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Client) SeedSession(settings map[string]string) *web.Session
+	//
+	var sessions web.Sessions
+	client := NewClient(t, newTestHandler(&sessions), &sessions)
+	ses := client.SeedSession(map[string]string{"visits": "41"})
+	zr.TEqual(t, client.Cookie("app_session_id"), ses.ID())
+	client.Get("/visit").AssertText("#visits", "42")
+	//
+	// seeding again updates the same session
+	again := client.SeedSession(map[string]string{"visits": "9"})
+	zr.TTrue(t, again == ses)
+	client.Get("/visit").AssertText("#visits", "10")
+	zr.TEqual(t, sessions.Len(), 1)
+} //                                               Test_clnt_Client_SeedSession_
+
+// end
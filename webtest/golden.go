@@ -0,0 +1,342 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                           zr-web/webtest/[golden.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package webtest
+
+//	AssertGolden compares generated HTML with a golden file, which
+//	holds the expected output, in 'testdata/<name>.golden':
+//
+//	func Test_LoginPage(t *testing.T) {
+//		page := web.HTML(web.Head(web.Title("Login")), loginForm())
+//		webtest.AssertGolden(t, "login_page", page)
+//	}
+//
+//	Run 'go test -update' to create or rewrite golden files, then
+//	review the changes with 'git diff' before committing them.
+//	webtest registers the '-update' flag, so test binaries that
+//	import it must not define a flag with that name: the flag
+//	package panics when a name is defined twice. Setting
+//	WEBTEST_UPDATE=1 works too, e.g. for 'go test ./...', where
+//	packages that don't import webtest would reject '-update'.
+//
+//	Both sides are normalized before they are compared: '\r\n' line
+//	endings, indentation and runs of white space in text don't matter.
+//	Golden files hold the normalized HTML, with one element per line
+//	and children indented, so their diffs are easy to read. A failed
+//	comparison shows the lines that differ under the path of the
+//	element that contains them, e.g. 'html > body > div#main'.
+
+// # Functions
+//   AssertGolden(t testing.TB, name string, got interface{})
+//   NormalizeHTML(s string) string
+//
+// # Support (File Scope)
+//   diffLines(want, got, paths []string) string
+//   escapeAttr(s string) string
+//   escapeText(s string) string
+//   goldenString(t testing.TB, got interface{}) string
+//   normalizeLines(s string) (lines, paths []string)
+//   pathName(node *web.Node) string
+//   updateGolden() bool
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	web "github.com/balacode/zr-web"
+)
+
+// GoldenDir is the directory that holds golden files.
+var GoldenDir = "testdata"
+
+// GoldenUpdateEnv is the environment variable that makes
+// AssertGolden() rewrite golden files when it is set to "1".
+const GoldenUpdateEnv = "WEBTEST_UPDATE"
+
+// goldenUpdate is the '-update' flag, which makes
+// AssertGolden() rewrite golden files
+var goldenUpdate = flag.Bool("update", false,
+	"rewrite the golden files of webtest.AssertGolden()")
+
+// goldenRawTextElements are elements whose content is text, not
+// markup. Their lines are kept, rather than joined into one.
+var goldenRawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// -----------------------------------------------------------------------------
+// # Functions
+
+// AssertGolden checks that HTML 'got' matches golden file 'name'. 'got'
+// can be a *web.Buffer, string, []byte (e.g. from web.HTML()) or a
+// fmt.Stringer. When golden files are being updated, it writes 'got'
+// to the file instead: see the '-update' flag and GoldenUpdateEnv.
+func AssertGolden(t testing.TB, name string, got interface{}) {
+	t.Helper()
+	path := filepath.Join(GoldenDir, name+".golden")
+	lines, _ := normalizeLines(goldenString(t, got))
+	if updateGolden() {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			data := []byte(strings.Join(lines, "\n") + "\n")
+			err = ioutil.WriteFile(path, data, 0644)
+		}
+		if err != nil {
+			t.Fatalf("can't update golden file: %v", err)
+		}
+		return
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden file %s does not exist: run 'go test -update'"+
+			" (or set %s=1) to create it", path, GoldenUpdateEnv)
+	}
+	if err != nil {
+		t.Fatalf("can't read golden file: %v", err)
+	}
+	want, paths := normalizeLines(string(data))
+	if strings.Join(want, "\n") != strings.Join(lines, "\n") {
+		t.Errorf("HTML does not match %s (- want, + got):\n%s"+
+			"run 'go test -update' (or set %s=1) to accept the changes",
+			path, diffLines(want, lines, paths), GoldenUpdateEnv)
+	}
+} //                                                                AssertGolden
+
+// NormalizeHTML returns HTML 's' in the format used by golden files:
+// one node per line, children indented with two spaces, runs of white
+// space in text replaced with single spaces, and '\n' line endings.
+// Elements whose only child is text are written on one line.
+func NormalizeHTML(s string) string {
+	lines, _ := normalizeLines(s)
+	return strings.Join(lines, "\n")
+} //                                                               NormalizeHTML
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// diffLines returns the differences between 'want' and 'got' with two
+// lines of context. 'paths' holds the path of the element that contains
+// each line of 'want', which heads each group of differences.
+func diffLines(want, got, paths []string) string {
+	// lcs[i][j] is the length of the longest common
+	// subsequence of want[i:] and got[j:]
+	lcs := make([][]int, len(want)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(got)+1)
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			if want[i] == got[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	type diffLine struct {
+		op   byte // ' ', '-' or '+'
+		text string
+		want int // index in 'want' at or before this line
+	}
+	var ops []diffLine
+	i, j := 0, 0
+	for i < len(want) || j < len(got) {
+		switch {
+		case i < len(want) && j < len(got) && want[i] == got[j]:
+			ops = append(ops, diffLine{' ', want[i], i})
+			i, j = i+1, j+1
+		case i < len(want) && (j == len(got) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffLine{'-', want[i], i})
+			i++
+		default:
+			ops = append(ops, diffLine{'+', got[j], i})
+			j++
+		}
+	}
+	const context = 2
+	var sb strings.Builder
+	last := -1 // index of the last op written
+	for k := 0; k < len(ops); k++ {
+		if ops[k].op == ' ' {
+			continue
+		}
+		start := k - context
+		if start < 0 {
+			start = 0
+		}
+		if last >= 0 && start <= last {
+			start = last + 1
+		} else {
+			path := "(top)"
+			if w := ops[k].want; w < len(paths) {
+				path = paths[w]
+			} else if len(paths) > 0 {
+				path = paths[len(paths)-1]
+			}
+			sb.WriteString("@@ " + path + "\n")
+		}
+		end := k + context
+		for m := k + 1; m < len(ops) && m <= end; m++ {
+			if ops[m].op != ' ' {
+				end = m + context
+			}
+		}
+		if end >= len(ops) {
+			end = len(ops) - 1
+		}
+		for m := start; m <= end; m++ {
+			sb.WriteString(string(ops[m].op) + " " + ops[m].text + "\n")
+		}
+		last = end
+		k = end
+	}
+	return sb.String()
+} //                                                                   diffLines
+
+// escapeAttr escapes 's' for use in a double-quoted attribute value.
+func escapeAttr(s string) string {
+	return strings.NewReplacer(`&`, "&amp;", `"`, "&quot;").Replace(s)
+} //                                                                  escapeAttr
+
+// escapeText escapes 's' for use as text.
+func escapeText(s string) string {
+	return strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;").
+		Replace(s)
+} //                                                                  escapeText
+
+// goldenString converts 'got' to a string, or fails the test
+// if it is not one of the types AssertGolden() accepts.
+func goldenString(t testing.TB, got interface{}) string {
+	t.Helper()
+	switch v := got.(type) {
+	case *web.Buffer:
+		return v.String()
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	}
+	t.Fatalf("AssertGolden: can't compare %T", got)
+	return ""
+} //                                                                goldenString
+
+// normalizeLines parses HTML 's' and returns its normalized lines (see
+// NormalizeHTML) and the path of the element containing each line.
+func normalizeLines(s string) (lines, paths []string) {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	var write func(node *web.Node, depth int, path string)
+	add := func(depth int, path, line string) {
+		lines = append(lines, strings.Repeat("  ", depth)+line)
+		paths = append(paths, path)
+	}
+	collapse := func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	}
+	write = func(node *web.Node, depth int, path string) {
+		for _, child := range node.Children() {
+			switch child.Type() {
+			case web.NodeText:
+				if text := collapse(child.Data()); text != "" {
+					add(depth, path, escapeText(text))
+				}
+				continue
+			case web.NodeComment:
+				add(depth, path, "<!-- "+collapse(child.Data())+" -->")
+				continue
+			case web.NodeRaw: // doctype
+				add(depth, path, collapse(child.Data()))
+				continue
+			}
+			tag := child.Tag()
+			var sb strings.Builder
+			sb.WriteString("<" + tag)
+			for _, attr := range child.Attributes() {
+				sb.WriteString(" " + attr.Name)
+				if value := html.UnescapeString(attr.Value); value != "" {
+					sb.WriteString(`="` + escapeAttr(value) + `"`)
+				}
+			}
+			sb.WriteString(">")
+			open := sb.String()
+			childPath := pathName(child)
+			if path != "" {
+				childPath = path + " > " + childPath
+			}
+			children := child.Children()
+			switch {
+			case web.IsVoidElement(tag):
+				add(depth, path, open)
+			case goldenRawTextElements[tag]:
+				var content []string
+				if len(children) > 0 {
+					for _, line := range strings.Split(
+						children[0].Data(), "\n") {
+						line = strings.TrimSpace(line)
+						if line == "" {
+							continue
+						}
+						if tag != "script" && tag != "style" {
+							line = escapeText(line)
+						}
+						content = append(content, line)
+					}
+				}
+				if len(content) <= 1 {
+					add(depth, childPath,
+						open+strings.Join(content, "")+"</"+tag+">")
+					break
+				}
+				add(depth, childPath, open)
+				for _, line := range content {
+					add(depth+1, childPath, line)
+				}
+				add(depth, childPath, "</"+tag+">")
+			case len(children) == 0:
+				add(depth, path, open+"</"+tag+">")
+			case len(children) == 1 && children[0].Type() == web.NodeText:
+				add(depth, childPath,
+					open+escapeText(collapse(children[0].Data()))+"</"+tag+">")
+			default:
+				add(depth, childPath, open)
+				write(child, depth+1, childPath)
+				add(depth, childPath, "</"+tag+">")
+			}
+		}
+	}
+	write(web.ParseHTML(s), 0, "")
+	return lines, paths
+} //                                                              normalizeLines
+
+// pathName returns the name of 'node' in element paths: its
+// tag followed by its ID, or by its classes if it has no ID.
+func pathName(node *web.Node) string {
+	if id := html.UnescapeString(node.Attr("id")); id != "" {
+		return node.Tag() + "#" + id
+	}
+	ret := node.Tag()
+	for _, class := range strings.Fields(
+		html.UnescapeString(node.Attr("class"))) {
+		ret += "." + class
+	}
+	return ret
+} //                                                                    pathName
+
+// updateGolden returns true if golden files should be rewritten:
+// when the '-update' flag is set, or GoldenUpdateEnv is "1".
+func updateGolden() bool {
+	return *goldenUpdate || os.Getenv(GoldenUpdateEnv) == "1"
+} //                                                                updateGolden
+
+// end
@@ -0,0 +1,182 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                      zr-web/webtest/[golden_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package webtest
+
+// # Functions
+//   Test_gold_AssertGolden_
+//   Test_gold_NormalizeHTML_
+//
+// # Support (File Scope)
+//   Test_gold_diffLines_
+//   Test_gold_updateGolden_
+
+//  to test all items in golden.go use:
+//      go test --run Test_gold_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/balacode/zr"
+	web "github.com/balacode/zr-web"
+)
+
+// goldenTestPage returns a page generated with the
+// web package's helpers, used by the golden file tests
+func goldenTestPage(heading string) []byte {
+	return web.HTML(
+		web.Head(web.Title("Golden & Test")),
+		web.Body(
+			web.Comment("navigation"),
+			web.Nav(web.Ul(
+				web.Li(web.A("/", "Home")),
+				web.Li(web.A("/about", "About")),
+			)),
+			web.Div(web.ID("main"), web.Class("page"),
+				web.H1(heading),
+				web.P("First  line\r\nof text."),
+				web.Br(),
+				web.Input(web.Type("text"), web.Name("q")),
+			),
+		),
+	)
+} //                                                              goldenTestPage
+
+// -----------------------------------------------------------------------------
+// # Functions
+
+// go test --run Test_gold_AssertGolden_
+func Test_gold_AssertGolden_(t *testing.T) {
+	zr.TBegin(t)
+	// AssertGolden(t testing.TB, name string, got interface{})
+	//
+	AssertGolden(t, "golden_page", goldenTestPage("Welcome"))
+	buf := web.Div(web.P("a"))
+	AssertGolden(t, "golden_buffer", buf)
+	//
+	// a mismatch shows the differing lines under the element's path
+	rt := &recordingT{TB: t}
+	update, env := *goldenUpdate, os.Getenv(GoldenUpdateEnv)
+	*goldenUpdate = false
+	os.Unsetenv(GoldenUpdateEnv)
+	defer func() {
+		*goldenUpdate = update
+		os.Setenv(GoldenUpdateEnv, env)
+	}()
+	AssertGolden(rt, "golden_page", goldenTestPage("Goodbye"))
+	zr.TEqual(t, len(rt.errors), 1)
+	if len(rt.errors) == 1 {
+		zr.TEqual(t, rt.errors[0], ""+
+			"HTML does not match testdata/golden_page.golden"+
+			" (- want, + got):\n"+
+			"@@ html > body > div#main > h1\n"+
+			"      </nav>\n"+
+			"      <div id=\"main\" class=\"page\">\n"+
+			"-       <h1>Welcome</h1>\n"+
+			"+       <h1>Goodbye</h1>\n"+
+			"        <p>First line of text.</p>\n"+
+			"        <br>\n"+
+			"run 'go test -update' (or set WEBTEST_UPDATE=1)"+
+			" to accept the changes")
+	}
+	// WEBTEST_UPDATE=1 rewrites golden files
+	dir := t.TempDir()
+	GoldenDir = dir
+	defer func() { GoldenDir = "testdata" }()
+	os.Setenv(GoldenUpdateEnv, "1")
+	AssertGolden(t, "sub/new", "<p>a<b>b</b></p>")
+	data, err := ioutil.ReadFile(filepath.Join(dir, "sub", "new.golden"))
+	zr.TEqual(t, err, nil)
+	zr.TEqual(t, string(data), "<p>\n  a\n  <b>b</b>\n</p>\n")
+} //                                                     Test_gold_AssertGolden_
+
+// go test --run Test_gold_NormalizeHTML_
+func Test_gold_NormalizeHTML_(t *testing.T) {
+	zr.TBegin(t)
+	// NormalizeHTML(s string) string
+	//
+	test := func(input string, expect ...string) {
+		zr.TEqual(t, NormalizeHTML(input), strings.Join(expect, "\n"))
+	}
+	test("")
+	test("<p>\r\n  a   b\r\n</p>", "<p>a b</p>")
+	test("<div><p>a</p>\r\n<p></p></div>",
+		"<div>", "  <p>a</p>", "  <p></p>", "</div>")
+	test(`<a href='/x?a=1&amp;b="2"' hidden>x &lt; y</a>`,
+		`<a href="/x?a=1&amp;b=&quot;2&quot;" hidden>x &lt; y</a>`)
+	test("<!doctype  html><br><img src=x>",
+		"<!doctype html>", "<br>", `<img src="x">`)
+	test("<!--  a\r\nb -->", "<!-- a b -->")
+	test("<ul><li>1<li>2</ul>",
+		"<ul>", "  <li>1</li>", "  <li>2</li>", "</ul>")
+	test("<script>\r\n  var a = 1;\r\n\r\n  if (a<2) {}\r\n</script>",
+		"<script>", "  var a = 1;", "  if (a<2) {}", "</script>")
+	//
+	// normalized HTML normalizes to itself
+	page := NormalizeHTML(string(goldenTestPage("Welcome")))
+	zr.TEqual(t, NormalizeHTML(page), page)
+} //                                                    Test_gold_NormalizeHTML_
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// go test --run Test_gold_diffLines_
+func Test_gold_diffLines_(t *testing.T) {
+	zr.TBegin(t)
+	// diffLines(want, got, paths []string) string
+	//
+	test := func(want, got, expect string) {
+		w, g := strings.Fields(want), strings.Fields(got)
+		paths := make([]string, len(w))
+		for i := range paths {
+			paths[i] = "p" + zr.String(i)
+		}
+		zr.TEqual(t, diffLines(w, g, paths), expect)
+	}
+	test("a b c", "a b c", "")
+	test("a b c", "a x c", "@@ p1\n  a\n- b\n+ x\n  c\n")
+	test("a b c d e f g h i", "a b c d e f g h x",
+		"@@ p8\n  g\n  h\n- i\n+ x\n")
+	test("1 2 3 4 5 6 7 8 9", "x 2 3 4 5 6 7 8 y",
+		"@@ p0\n- 1\n+ x\n  2\n  3\n@@ p8\n  7\n  8\n- 9\n+ y\n")
+	test("a", "a b", "@@ p0\n  a\n+ b\n")
+	test("", "a", "@@ (top)\n+ a\n")
+} //                                                        Test_gold_diffLines_
+
+// go test --run Test_gold_updateGolden_
+func Test_gold_updateGolden_(t *testing.T) {
+	zr.TBegin(t)
+	// updateGolden() bool
+	//
+	update, env := *goldenUpdate, os.Getenv(GoldenUpdateEnv)
+	defer func() {
+		*goldenUpdate = update
+		os.Setenv(GoldenUpdateEnv, env)
+	}()
+	os.Unsetenv(GoldenUpdateEnv)
+	*goldenUpdate = false
+	zr.TEqual(t, updateGolden(), false)
+	//
+	// the '-update' flag
+	*goldenUpdate = true
+	zr.TEqual(t, updateGolden(), true)
+	*goldenUpdate = false
+	//
+	// the environment variable
+	os.Setenv(GoldenUpdateEnv, "1")
+	zr.TEqual(t, updateGolden(), true)
+	os.Setenv(GoldenUpdateEnv, "0")
+	zr.TEqual(t, updateGolden(), false)
+} //                                                     Test_gold_updateGolden_
+
+// end
@@ -0,0 +1,10 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                           zr-web/webtest/[module.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+// Package webtest provides a client and assertions for testing
+// HTTP handlers that use web.Context and web.Sessions.
+package webtest
+
+// end
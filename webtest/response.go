@@ -0,0 +1,257 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                         zr-web/webtest/[response.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package webtest
+
+//  Response struct
+//
+// # Assertions (ob *Response)
+//   ) AssertAttr(selector, name, value string) *Response
+//   ) AssertBodyContains(s string) *Response
+//   ) AssertCount(selector string, count int) *Response
+//   ) AssertExists(selector string) *Response
+//   ) AssertHeader(name, value string) *Response
+//   ) AssertMediaType(mediaType string) *Response
+//   ) AssertNotExists(selector string) *Response
+//   ) AssertStatus(code int) *Response
+//   ) AssertText(selector, text string) *Response
+//
+// # Methods (ob *Response)
+//   ) Attr(selector, name string) string
+//   ) Count(selector string) int
+//   ) JSON(value interface{})
+//   ) MediaType() string
+//   ) Text(selector string) string
+//
+// # Support (File Scope)
+//   (ob *Response) find(selector string) []*web.Node
+//   attrValue(node *web.Node, name string) (string, bool)
+//   nodeText(node *web.Node) string
+
+import (
+	"encoding/json"
+	"html"
+	"mime"
+	"net/http"
+	"strings"
+	"testing"
+
+	web "github.com/balacode/zr-web"
+)
+
+// Response is a reply received by Client. Its assertions report
+// failures to T and return the Response, so they can be chained.
+// Methods that take a selector read the body with web.ParseHTML()
+// and find elements with web.Node.Select(), e.g. "form > input[name=q]".
+type Response struct {
+	T      testing.TB
+	Code   int
+	Header http.Header
+	Body   string
+
+	doc *web.Node // parsed Body, created when first needed
+} //                                                                    Response
+
+// -----------------------------------------------------------------------------
+// # Assertions (ob *Response)
+
+// AssertAttr checks that the first element matching
+// 'selector' has attribute 'name' set to 'value'.
+func (ob *Response) AssertAttr(selector, name, value string) *Response {
+	ob.T.Helper()
+	found := ob.find(selector)
+	if len(found) == 0 {
+		ob.T.Errorf("no element matches %q", selector)
+		return ob
+	}
+	got, exists := attrValue(found[0], name)
+	if !exists {
+		ob.T.Errorf("%q has no %q attribute", selector, name)
+	} else if got != value {
+		ob.T.Errorf("%q attribute %q is %q, want %q",
+			selector, name, got, value)
+	}
+	return ob
+} //                                                                  AssertAttr
+
+// AssertBodyContains checks that the reply's body contains 's'.
+func (ob *Response) AssertBodyContains(s string) *Response {
+	ob.T.Helper()
+	if !strings.Contains(ob.Body, s) {
+		ob.T.Errorf("body does not contain %q:\n%s", s, ob.Body)
+	}
+	return ob
+} //                                                          AssertBodyContains
+
+// AssertCount checks that 'count' elements match 'selector'.
+func (ob *Response) AssertCount(selector string, count int) *Response {
+	ob.T.Helper()
+	if n := len(ob.find(selector)); n != count {
+		ob.T.Errorf("%d elements match %q, want %d", n, selector, count)
+	}
+	return ob
+} //                                                                 AssertCount
+
+// AssertExists checks that at least one element matches 'selector'.
+func (ob *Response) AssertExists(selector string) *Response {
+	ob.T.Helper()
+	if len(ob.find(selector)) == 0 {
+		ob.T.Errorf("no element matches %q", selector)
+	}
+	return ob
+} //                                                                AssertExists
+
+// AssertHeader checks that header 'name' is 'value'.
+func (ob *Response) AssertHeader(name, value string) *Response {
+	ob.T.Helper()
+	got := ob.Header.Get(name)
+	if got != value {
+		ob.T.Errorf("header %s is %q, want %q", name, got, value)
+	}
+	return ob
+} //                                                                AssertHeader
+
+// AssertMediaType checks the media type of the reply, ignoring
+// parameters such as 'charset'. 'mediaType' can be a media type,
+// e.g. "text/html", or a name known by web.MediaType(), e.g. "html".
+func (ob *Response) AssertMediaType(mediaType string) *Response {
+	ob.T.Helper()
+	if !strings.Contains(mediaType, "/") {
+		mediaType = web.MediaType(mediaType)
+	}
+	if got := ob.MediaType(); got != mediaType {
+		ob.T.Errorf("media type is %q, want %q", got, mediaType)
+	}
+	return ob
+} //                                                             AssertMediaType
+
+// AssertNotExists checks that no element matches 'selector'.
+func (ob *Response) AssertNotExists(selector string) *Response {
+	ob.T.Helper()
+	if n := len(ob.find(selector)); n > 0 {
+		ob.T.Errorf("%d elements match %q, want none", n, selector)
+	}
+	return ob
+} //                                                             AssertNotExists
+
+// AssertStatus checks the reply's HTTP status code.
+func (ob *Response) AssertStatus(code int) *Response {
+	ob.T.Helper()
+	if ob.Code != code {
+		ob.T.Errorf("status is %d, want %d", ob.Code, code)
+	}
+	return ob
+} //                                                                AssertStatus
+
+// AssertText checks the text of the first element matching 'selector'.
+// Runs of white space in the text count as single spaces.
+func (ob *Response) AssertText(selector, text string) *Response {
+	ob.T.Helper()
+	found := ob.find(selector)
+	if len(found) == 0 {
+		ob.T.Errorf("no element matches %q", selector)
+		return ob
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if got := nodeText(found[0]); got != text {
+		ob.T.Errorf("%q text is %q, want %q", selector, got, text)
+	}
+	return ob
+} //                                                                  AssertText
+
+// -----------------------------------------------------------------------------
+// # Methods (ob *Response)
+
+// Attr returns the value of attribute 'name' of the first element
+// matching 'selector', or a blank string if there is no such element.
+func (ob *Response) Attr(selector, name string) string {
+	ob.T.Helper()
+	found := ob.find(selector)
+	if len(found) == 0 {
+		return ""
+	}
+	value, _ := attrValue(found[0], name)
+	return value
+} //                                                                        Attr
+
+// Count returns the number of elements matching 'selector'.
+func (ob *Response) Count(selector string) int {
+	ob.T.Helper()
+	return len(ob.find(selector))
+} //                                                                       Count
+
+// JSON decodes the reply's body into 'value',
+// and fails the test if the body is not valid JSON.
+func (ob *Response) JSON(value interface{}) {
+	ob.T.Helper()
+	err := json.Unmarshal([]byte(ob.Body), value)
+	if err != nil {
+		ob.T.Fatalf("invalid JSON reply: %v\n%s", err, ob.Body)
+	}
+} //                                                                        JSON
+
+// MediaType returns the media type of the reply, without parameters.
+func (ob *Response) MediaType() string {
+	contentType := ob.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.TrimSpace(contentType)
+	}
+	return mediaType
+} //                                                                   MediaType
+
+// Text returns the text of the first element matching 'selector',
+// with runs of white space replaced with single spaces.
+func (ob *Response) Text(selector string) string {
+	ob.T.Helper()
+	found := ob.find(selector)
+	if len(found) == 0 {
+		return ""
+	}
+	return nodeText(found[0])
+} //                                                                        Text
+
+// -----------------------------------------------------------------------------
+// # Support (File Scope)
+
+// find returns the elements of the reply's body matching 'selector'
+// (see web.Node.Select). It fails the test if the selector is not valid.
+func (ob *Response) find(selector string) []*web.Node {
+	ob.T.Helper()
+	if ob.doc == nil {
+		ob.doc = web.ParseHTML(ob.Body)
+	}
+	found, err := ob.doc.Select(selector)
+	if err != nil {
+		ob.T.Fatalf("invalid selector %q: %v", selector, err)
+	}
+	return found
+} //                                                                        find
+
+// attrValue returns the value of attribute 'name' of 'node',
+// with entities decoded, and whether the node has the attribute.
+func attrValue(node *web.Node, name string) (string, bool) {
+	name = strings.ToLower(name)
+	if !node.HasAttr(name) {
+		return "", false
+	}
+	return html.UnescapeString(node.Attr(name)), true
+} //                                                                   attrValue
+
+// nodeText returns the text within 'node', with runs
+// of white space replaced with single spaces.
+func nodeText(node *web.Node) string {
+	var sb strings.Builder
+	node.Walk(func(it *web.Node) bool {
+		if it.Type() == web.NodeText {
+			sb.WriteString(it.Data())
+			sb.WriteString(" ")
+		}
+		return true
+	})
+	return strings.Join(strings.Fields(sb.String()), " ")
+} //                                                                    nodeText
+
+// end
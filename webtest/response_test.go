@@ -0,0 +1,105 @@
+// -----------------------------------------------------------------------------
+// ZR Library - Web Package                    zr-web/webtest/[response_test.go]
+// (c) balarabe@protonmail.com                                      License: MIT
+// -----------------------------------------------------------------------------
+
+package webtest
+
+// # Assertions (ob *Response)
+//   Test_resp_Response_Assert_
+
+//  to test all items in response.go use:
+//      go test --run Test_resp_
+//
+//  to generate a test coverage report for the whole module use:
+//      go test -coverprofile cover.out
+//      go tool cover -html=cover.out
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/balacode/zr"
+)
+
+// recordingT records the errors reported by assertions
+type recordingT struct {
+	testing.TB
+	errors []string
+} //                                                                  recordingT
+
+// Errorf records an error.
+func (ob *recordingT) Errorf(format string, args ...interface{}) {
+	ob.errors = append(ob.errors, fmt.Sprintf(format, args...))
+} //                                                                      Errorf
+
+// Helper does nothing.
+func (ob *recordingT) Helper() {
+} //                                                                      Helper
+
+// -----------------------------------------------------------------------------
+// # Assertions (ob *Response)
+
+// go test --run Test_resp_Response_Assert_
+func Test_resp_Response_Assert_(t *testing.T) {
+	zr.TBegin(t)
+	// (ob *Response) AssertStatus(code int) *Response
+	//
+	rt := &recordingT{TB: t}
+	res := &Response{
+		T:    rt,
+		Code: 200,
+		Header: http.Header{
+			"Content-Type": {"text/html; charset=utf-8"},
+			"X-Total":      {"3"},
+		},
+		Body: `<ul class="list"><li>One<li class="x">Two</ul>` +
+			`<a href="/next" rel=next>Next</a>`,
+	}
+	// passing assertions
+	res.AssertStatus(200).
+		AssertHeader("x-total", "3").
+		AssertMediaType("html").
+		AssertMediaType("text/html").
+		AssertBodyContains("Two").
+		AssertCount("ul.list > li", 2).
+		AssertExists("li.x").
+		AssertNotExists("ol").
+		AssertText("li.x", "Two").
+		AssertAttr("a[rel=next]", "href", "/next")
+	zr.TEqual(t, len(rt.errors), 0)
+	zr.TEqual(t, res.Text("ul"), "One Two")
+	zr.TEqual(t, res.Attr("a", "rel"), "next")
+	zr.TEqual(t, res.Count("li"), 2)
+	//
+	// failing assertions
+	test := func(assert func(), expect string) {
+		rt.errors = nil
+		assert()
+		zr.TEqual(t, len(rt.errors), 1)
+		if len(rt.errors) == 1 {
+			zr.TEqual(t, rt.errors[0], expect)
+		}
+	}
+	test(func() { res.AssertStatus(404) }, "status is 200, want 404")
+	test(func() { res.AssertHeader("X-Total", "4") },
+		`header X-Total is "3", want "4"`)
+	test(func() { res.AssertMediaType("json") },
+		`media type is "text/html", want "application/json"`)
+	test(func() { res.AssertBodyContains("Three") },
+		"body does not contain \"Three\":\n"+res.Body)
+	test(func() { res.AssertCount("li", 3) },
+		`2 elements match "li", want 3`)
+	test(func() { res.AssertExists("ol") }, `no element matches "ol"`)
+	test(func() { res.AssertNotExists("li") },
+		`2 elements match "li", want none`)
+	test(func() { res.AssertText("li", "Two") },
+		`"li" text is "One", want "Two"`)
+	test(func() { res.AssertAttr("a", "href", "/") },
+		`"a" attribute "href" is "/next", want "/"`)
+	test(func() { res.AssertAttr("a", "title", "") },
+		`"a" has no "title" attribute`)
+} //                                                  Test_resp_Response_Assert_
+
+// end